@@ -1,9 +1,14 @@
 package app
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
 	"github.com/mceck/clickup-tui/internal/ui/views"
 )
 
@@ -15,11 +20,26 @@ const (
 	TimesheetView
 )
 
+const (
+	// headerHeight is the number of lines reserved at the top of the screen for the timer.
+	headerHeight = 1
+	// statusBarHeight is the number of lines reserved at the bottom of the screen for notifications.
+	statusBarHeight = 1
+)
+
 type AppModel struct {
-	currentPage Page
-	routes      map[Page]tea.Model
-	width       int
-	height      int
+	currentPage   Page
+	client        clients.ClickupAPI
+	routes        map[Page]tea.Model
+	notifications *components.Notifications
+	timer         *components.Timer
+	writes        *components.WriteQueue
+	refresh       *components.AutoRefresh
+	profiles      *components.ProfileSwitcher
+	profile       string
+	loadErr       error // the local state of the profile could not be loaded
+	width         int
+	height        int
 }
 
 func (m AppModel) getCurrentRoute() tea.Model {
@@ -27,17 +47,47 @@ func (m AppModel) getCurrentRoute() tea.Model {
 	if route == nil {
 		switch m.currentPage {
 		case HomeView:
-			m.routes[m.currentPage] = views.NewHomeModel()
+			m.routes[m.currentPage] = views.NewHomeModel(m.client)
 		case SettingsView:
-			m.routes[m.currentPage] = views.NewSettingsModel()
+			m.routes[m.currentPage] = views.NewSettingsModel(m.client)
 		case TimesheetView:
-			m.routes[m.currentPage] = views.NewTimesheetModel()
+			m.routes[m.currentPage] = views.NewTimesheetModel(m.client)
+		}
+		if m.width > 0 {
+			m.routes[m.currentPage], _ = m.routes[m.currentPage].Update(m.routeSizeMsg())
 		}
 	}
 	return m.routes[m.currentPage]
 }
 
+// routeHeight is the height available to the routes, without the header and the status bar.
+func (m AppModel) routeHeight() int {
+	return max(m.height-headerHeight-statusBarHeight, 1)
+}
+
+func (m AppModel) routeSizeMsg() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{Width: m.width, Height: m.routeHeight()}
+}
+
+// leaveCurrentRoute lets the current view cancel the requests it still has running.
+func (m AppModel) leaveCurrentRoute() {
+	if route := m.routes[m.currentPage]; route != nil {
+		m.routes[m.currentPage], _ = route.Update(views.LeaveMsg{})
+	}
+}
+
+// New creates the application model for the active profile, after loading its local state.
 func New() AppModel {
+	loadErr := clients.LoadLocalState()
+	client := clients.GetConfig().NewClient()
+	client.QueueWrites = true
+	m := NewWithClient(client)
+	m.loadErr = loadErr
+	return m
+}
+
+// NewWithClient creates the application model on top of the given ClickUp API.
+func NewWithClient(client clients.ClickupAPI) AppModel {
 	config := clients.GetConfig()
 	var initialPage Page
 	if config.InitialView == "timesheet" {
@@ -46,31 +96,181 @@ func New() AppModel {
 		initialPage = HomeView
 	}
 	return AppModel{
-		currentPage: initialPage,
-		routes:      map[Page]tea.Model{},
+		currentPage:   initialPage,
+		client:        client,
+		routes:        map[Page]tea.Model{},
+		notifications: &components.Notifications{},
+		timer:         components.NewTimer(client),
+		writes:        components.NewWriteQueue(client),
+		refresh:       components.NewAutoRefresh(config.RefreshInterval),
+		profiles:      &components.ProfileSwitcher{},
+		profile:       config.Profile,
 	}
 }
 
+// switchProfile activates another profile and rebuilds the application for it.
+func (m AppModel) switchProfile(name string) (tea.Model, tea.Cmd) {
+	if err := clients.SwitchProfile(name); err != nil {
+		return m, m.notifications.Push(components.NotifyMsg{Source: "Switching profile", Err: err})
+	}
+	return m.rebuild()
+}
+
+// rebuild recreates the views, the client and the timer from the current config.
+// Notifications are kept so that earlier errors remain in the log.
+func (m AppModel) rebuild() (tea.Model, tea.Cmd) {
+	for page, route := range m.routes {
+		m.routes[page], _ = route.Update(views.LeaveMsg{})
+	}
+	next := New()
+	next.notifications = m.notifications
+	next.width, next.height = m.width, m.height
+	if clients.GetConfig().ClickupToken == "" {
+		next.currentPage = SettingsView
+	}
+	return next, next.Init()
+}
+
 func (m AppModel) Init() tea.Cmd {
 	config := clients.GetConfig()
 	if config.ClickupToken == "" || config.TeamId == "" || config.UserId == "" {
 		m.currentPage = SettingsView
 	}
-	return m.getCurrentRoute().Init()
+	var notify tea.Cmd
+	if m.loadErr != nil {
+		notify = components.Notify("Loading the local data", m.loadErr, nil)
+	}
+	if config.ClickupToken == "" {
+		return tea.Batch(m.getCurrentRoute().Init(), notify)
+	}
+	return tea.Batch(m.getCurrentRoute().Init(), m.timer.Init(), m.refresh.Init(), notify)
+}
+
+// broadcast sends a message to every route that has already been created,
+// so that results of background commands reach their view even after switching page.
+func (m AppModel) broadcast(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	for page, route := range m.routes {
+		var cmd tea.Cmd
+		m.routes[page], cmd = route.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
 }
 
+// Update handles a message, then lets the write queue send the changes made offline
+// when there are some.
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if app, ok := next.(AppModel); ok {
+		return app, tea.Batch(cmd, app.writes.Watch())
+	}
+	return next, cmd
+}
+
+func (m AppModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.timer.Update(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.writes.Update(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.refresh.Update(msg); ok {
+		return m, cmd
+	}
+	if cmd, ok := m.notifications.Update(msg); ok {
+		return m, cmd
+	}
+	switch msg := msg.(type) {
+	case components.RefreshMsg:
+		// Only the view on screen is refreshed.
+		route := m.routes[m.currentPage]
+		if route == nil {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.routes[m.currentPage], cmd = route.Update(msg)
+		return m, cmd
+	case components.NotifyMsg:
+		return m, m.notifications.Push(msg)
+	case components.SwitchProfileMsg:
+		return m.switchProfile(msg.Name)
+	case components.ConfigChangedMsg:
+		return m.rebuild()
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, m.broadcast(m.routeSizeMsg())
+	case tea.KeyMsg:
+		if m.notifications.LogVisible() {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			m.notifications.HandleLogKey(msg)
+			return m, nil
+		}
+		if m.profiles.Visible() {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, m.profiles.HandleKey(msg)
+		}
+		if m.writes.Visible() {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, m.writes.HandleKey(msg)
+		}
+		switch msg.String() {
+		case "ctrl+p":
+			m.profiles.Open()
+			return m, nil
+		case "ctrl+q":
+			m.writes.Open()
+			return m, nil
+		case "ctrl+e":
+			m.notifications.ToggleLog()
+			return m, nil
+		case "ctrl+x":
+			if m.notifications.HasCurrent() {
+				m.notifications.Dismiss()
+				return m, nil
+			}
+		case "ctrl+r":
+			if m.notifications.HasCurrent() {
+				return m, m.notifications.Retry()
+			}
+		}
+	case tea.MouseMsg:
+		msg.Y -= headerHeight
+		if msg.Y < 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.routes[m.currentPage], cmd = m.getCurrentRoute().Update(msg)
+		return m, cmd
+	default:
+		m.getCurrentRoute()
+		return m, m.broadcast(msg)
+	}
+
 	var cmd tea.Cmd
 	m.routes[m.currentPage], cmd = m.getCurrentRoute().Update(msg)
 	if cmd != nil {
 		return m, cmd
 	}
+	if editor, ok := m.routes[m.currentPage].(views.TextEditor); ok && editor.EditingText() {
+		if key, ok := msg.(tea.KeyMsg); ok && key.String() != "ctrl+c" {
+			return m, nil
+		}
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "tab":
+			m.leaveCurrentRoute()
 			if m.currentPage == HomeView {
 				m.currentPage = TimesheetView
 			} else {
@@ -79,14 +279,14 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.routes[m.currentPage] == nil {
 				m.routes[m.currentPage] = m.getCurrentRoute()
 				m.routes[m.currentPage], cmd = m.routes[m.currentPage].Update(views.LoadMsg{})
+			} else {
+				m.routes[m.currentPage], cmd = m.routes[m.currentPage].Update(views.EnterMsg{})
 			}
 		case "?":
+			m.leaveCurrentRoute()
 			m.currentPage = SettingsView
 			m.routes[m.currentPage], cmd = m.getCurrentRoute().Update(nil)
 		}
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
 	}
 	config := clients.GetConfig()
 	if config.ClickupToken == "" {
@@ -97,11 +297,31 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m AppModel) View() string {
+	profile := lipgloss.NewStyle().Foreground(ui.Highlight).Padding(0, 1).Render("◆ " + m.profile)
+	queue := m.writes.HeaderView()
+	header := lipgloss.JoinHorizontal(lipgloss.Top, m.timer.View(m.width-lipgloss.Width(queue)-lipgloss.Width(profile)), queue, profile)
+	statusBar := m.notifications.StatusBar(m.width)
+	if m.notifications.LogVisible() {
+		return lipgloss.JoinVertical(lipgloss.Left, header, m.notifications.LogView(m.width, m.routeHeight()), statusBar)
+	}
+	if m.profiles.Visible() {
+		return lipgloss.JoinVertical(lipgloss.Left, header, m.profiles.View(m.width, m.routeHeight()), statusBar)
+	}
+	if m.writes.Visible() {
+		return lipgloss.JoinVertical(lipgloss.Left, header, m.writes.View(m.width, m.routeHeight()), statusBar)
+	}
 	route := m.routes[m.currentPage]
 	if route == nil {
 		return ""
 	}
-	return route.View()
+	if m.height == 0 {
+		return route.View()
+	}
+	routeView := lipgloss.NewStyle().MaxHeight(m.routeHeight()).Render(route.View())
+	if padding := m.routeHeight() - lipgloss.Height(routeView); padding > 0 {
+		routeView += strings.Repeat("\n", padding)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, routeView, statusBar)
 }
 
 func NewProgram() *tea.Program {
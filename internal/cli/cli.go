@@ -0,0 +1,150 @@
+// Package cli implements the headless subcommands of clickup-tui, meant for scripts,
+// cron jobs and shell aliases. They share the configuration and the ClickUp client of the TUI.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+)
+
+const usage = `Usage: clickup-tui [--profile <name>] [command] [flags]
+
+Without a command the interactive interface is started.
+--profile selects a profile of the config file for this run only.
+
+Commands:
+  tasks [--view <id>]                        List the tasks of a view
+  task <id>                                  Show a task
+  log <task> <duration> [--date YYYY-MM-DD]  Track time on a task (e.g. 2h30m, 45m, 1.5)
+  timesheet [--week YYYY-Www]                Show the tracked hours of a week
+  comment <task> <message>                   Comment on a task ("-" reads the message from stdin)
+  profiles                                   List the profiles
+
+Every command accepts --output table|json|csv (default table).
+`
+
+// errUsage marks errors caused by invalid arguments.
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name string
+	run  func(ctx context.Context, e *env, fs *flag.FlagSet, args []string) (output, error)
+}
+
+var commands = []command{
+	{"tasks", runTasks},
+	{"task", runTask},
+	{"log", runLog},
+	{"timesheet", runTimesheet},
+	{"comment", runComment},
+	{"profiles", runProfiles},
+}
+
+// env holds what commands need to talk to ClickUp.
+type env struct {
+	client clients.ClickupAPI
+	config clients.Config
+	stdin  io.Reader
+	format string
+}
+
+// ApplyGlobalFlags handles the flags placed before the command, such as --profile,
+// and returns the remaining arguments.
+func ApplyGlobalFlags(args []string) ([]string, error) {
+	fs := flag.NewFlagSet("clickup-tui", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	profile := fs.String("profile", "", "profile to use for this run")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *profile != "" {
+		if err := clients.UseProfile(*profile); err != nil {
+			return nil, err
+		}
+	}
+	return fs.Args(), nil
+}
+
+// Run executes a command and returns the process exit code:
+// 0 on success, 1 when the command fails and 2 on invalid usage.
+func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || isHelp(args[0]) {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	config := clients.GetConfig()
+	if config.ClickupToken == "" {
+		fmt.Fprintln(stderr, "clickup-tui is not configured: run it without arguments to set your token")
+		return 1
+	}
+
+	defer clients.CloseStore()
+	if err := clients.LoadLocalState(); err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	e := &env{client: config.NewClient(), config: config, stdin: stdin}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&e.format, "output", formatTable, "output format: table, json or csv")
+	out, err := cmd.run(ctx, e, fs, args[1:])
+	if err == nil {
+		err = out.write(stdout, e.format)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", cmd.name, err)
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// parse parses flags placed anywhere among the positional arguments,
+// so that both "log 123 2h --date 2026-10-12" and "log --date 2026-10-12 123 2h" work.
+func (e *env) parse(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(rest) != positional {
+		return nil, fmt.Errorf("%w: expected %d arguments, got %d", errUsage, positional, len(rest))
+	}
+	if !validFormat(e.format) {
+		return nil, fmt.Errorf("%w: unknown output format %q", errUsage, e.format)
+	}
+	return rest, nil
+}
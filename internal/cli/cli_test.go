@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/fakeclickup"
+)
+
+func newTestServer(t *testing.T, fixtures fakeclickup.Fixtures) *fakeclickup.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	srv := fakeclickup.New(fixtures)
+	t.Cleanup(srv.Close)
+	err := clients.SaveConfig(clients.Config{
+		ClickupToken: fakeclickup.DefaultToken,
+		TeamId:       fakeclickup.DefaultTeam,
+		UserId:       "1",
+		ViewId:       "v1",
+		BaseURL:      srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+func run(t *testing.T, stdin string, args ...string) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(args, strings.NewReader(stdin), &stdout, &stderr)
+	if code != 0 {
+		t.Logf("stderr: %s", stderr.String())
+	}
+	return stdout.String(), code
+}
+
+var cliFixtures = fakeclickup.Fixtures{
+	Tasks: []clients.Task{
+		{Id: "t1", Name: "Write docs", Status: clients.Status{Status: "to do"}},
+		{Id: "t2", Name: "Fix bug", Status: clients.Status{Status: "in progress"}},
+	},
+	Views: map[string][]string{"v1": {"t1", "t2"}},
+}
+
+func TestTasksOutputFormats(t *testing.T) {
+	newTestServer(t, cliFixtures)
+
+	out, code := run(t, "", "tasks", "--output", "json")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	var tasks []clients.Task
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil || len(tasks) != 2 {
+		t.Fatalf("unexpected json %q: %v", out, err)
+	}
+
+	out, code = run(t, "", "tasks", "--view", "v1", "--output=csv")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil || len(records) != 3 || records[2][3] != "Fix bug" {
+		t.Fatalf("unexpected csv %q: %v", out, err)
+	}
+
+	out, code = run(t, "", "tasks")
+	if code != 0 || !strings.Contains(out, "Write docs") {
+		t.Fatalf("unexpected table (%d) %q", code, out)
+	}
+}
+
+func TestLogAppendsEntry(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: cliFixtures.Tasks,
+		TimeEntries: []clients.TimeEntry{{
+			Id:       "e1",
+			Task:     map[string]interface{}{"id": "t1", "name": "Write docs"},
+			Start:    strconv.FormatInt(day.Add(9*time.Hour).UnixMilli(), 10),
+			Duration: strconv.FormatInt(time.Hour.Milliseconds(), 10),
+		}},
+	})
+
+	out, code := run(t, "", "log", "t1", "2h30m", "--date", "2026-10-12", "--output", "json")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	var entries []clients.TimeEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil || len(entries) != 2 {
+		t.Fatalf("unexpected json %q: %v", out, err)
+	}
+	created := srv.TimeEntries()[1]
+	if created.Duration != "9000000" || created.Start != strconv.FormatInt(day.Add(10*time.Hour).UnixMilli(), 10) {
+		t.Errorf("unexpected entry %+v", created)
+	}
+
+	if _, code := run(t, "", "log", "t1", "abc"); code != 2 {
+		t.Errorf("invalid duration exit code = %d, want 2", code)
+	}
+}
+
+func TestTimesheetWeek(t *testing.T) {
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+	entry := func(id string, start time.Time, hours int) clients.TimeEntry {
+		return clients.TimeEntry{
+			Id:       id,
+			Task:     map[string]interface{}{"id": "t1", "name": "Write docs"},
+			Start:    strconv.FormatInt(start.UnixMilli(), 10),
+			Duration: strconv.FormatInt((time.Duration(hours) * time.Hour).Milliseconds(), 10),
+		}
+	}
+	newTestServer(t, fakeclickup.Fixtures{
+		Tasks: cliFixtures.Tasks,
+		TimeEntries: []clients.TimeEntry{
+			entry("e1", monday.Add(9*time.Hour), 2),
+			entry("e2", monday.AddDate(0, 0, 2).Add(9*time.Hour), 3),
+			entry("e3", monday.AddDate(0, 0, 7).Add(9*time.Hour), 4),
+		},
+	})
+
+	out, code := run(t, "", "timesheet", "--week", "2026-W41", "--output", "json")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	var week timesheetWeek
+	if err := json.Unmarshal([]byte(out), &week); err != nil {
+		t.Fatal(err)
+	}
+	if week.From != "2026-10-05" || len(week.Tasks) != 1 || week.Total != 5 || week.Tasks[0].Hours["2026-10-07"] != 3 {
+		t.Errorf("unexpected timesheet %+v", week)
+	}
+}
+
+func TestCommentFromStdin(t *testing.T) {
+	srv := newTestServer(t, cliFixtures)
+
+	if _, code := run(t, "Deployed to staging\n", "comment", "t1", "-"); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	comments := srv.Comments("t1")
+	if len(comments) != 1 || comments[0].CommentText != "Deployed to staging" {
+		t.Errorf("unexpected comments %+v", comments)
+	}
+}
+
+func TestParseISOWeek(t *testing.T) {
+	for week, want := range map[string]string{
+		"2026-W01": "2025-12-29",
+		"2026-W41": "2026-10-05",
+		"2020-W53": "2020-12-28",
+	} {
+		got, err := parseISOWeek(week)
+		if err != nil || got.Format("2006-01-02") != want {
+			t.Errorf("parseISOWeek(%s) = %v, %v, want %s", week, got, err, want)
+		}
+	}
+	if _, err := parseISOWeek("2025-W53"); err == nil {
+		t.Error("2025 has no week 53")
+	}
+}
+
+func TestTimesheetFollowsTheWorkWeek(t *testing.T) {
+	sunday := time.Date(2026, 10, 4, 0, 0, 0, 0, time.Local)
+	entry := func(id string, day int, hours int) clients.TimeEntry {
+		return clients.TimeEntry{
+			Id:       id,
+			Task:     map[string]interface{}{"id": "t1", "name": "Write docs"},
+			Start:    strconv.FormatInt(sunday.AddDate(0, 0, day).Add(9*time.Hour).UnixMilli(), 10),
+			Duration: strconv.FormatInt((time.Duration(hours) * time.Hour).Milliseconds(), 10),
+		}
+	}
+	newTestServer(t, fakeclickup.Fixtures{
+		Tasks: cliFixtures.Tasks,
+		TimeEntries: []clients.TimeEntry{
+			entry("e1", 0, 2), // Sunday
+			entry("e2", 4, 3), // Thursday
+			entry("e3", 5, 4), // Friday, not a work day
+		},
+	})
+	config := clients.GetConfig()
+	config.WorkWeek = clients.WorkWeek{FirstDay: "sunday", Days: []string{"sun", "mon", "tue", "wed", "thu"}}
+	if err := clients.SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := run(t, "", "timesheet", "--week", "2026-W41", "--output", "json")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	var week timesheetWeek
+	if err := json.Unmarshal([]byte(out), &week); err != nil {
+		t.Fatal(err)
+	}
+	days := []string{"2026-10-04", "2026-10-05", "2026-10-06", "2026-10-07", "2026-10-08"}
+	if week.From != "2026-10-04" || week.To != "2026-10-08" || !slices.Equal(week.Days, days) {
+		t.Errorf("unexpected days %+v", week)
+	}
+	if len(week.Tasks) != 1 || week.Total != 5 || week.Tasks[0].Hours["2026-10-04"] != 2 || week.Tasks[0].Hours["2026-10-09"] != 0 {
+		t.Errorf("unexpected timesheet %+v", week)
+	}
+
+	out, code = run(t, "", "timesheet", "--week", "2026-W41")
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	header := strings.Fields(strings.SplitN(out, "\n", 2)[0])
+	if !slices.Equal(header, []string{"TASK", "ID", "TASK", "SUN", "04", "MON", "05", "TUE", "06", "WED", "07", "THU", "08", "TOTAL"}) {
+		t.Errorf("unexpected columns %q", header)
+	}
+}
@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+)
+
+func runTasks(ctx context.Context, e *env, fs *flag.FlagSet, args []string) (output, error) {
+	viewId := fs.String("view", e.config.ViewId, "view ID, defaults to the configured view")
+	if _, err := e.parse(fs, args, 0); err != nil {
+		return output{}, err
+	}
+	if *viewId == "" {
+		return output{}, fmt.Errorf("%w: no view configured, pass --view", errUsage)
+	}
+	tasks, err := e.client.GetViewTasks(ctx, *viewId)
+	if err != nil {
+		return output{}, err
+	}
+	out := output{
+		headers: []string{"ID", "CUSTOM ID", "STATUS", "NAME", "ASSIGNEES"},
+		data:    tasks,
+	}
+	for _, task := range tasks {
+		out.rows = append(out.rows, []string{task.Id, task.CustomId, task.Status.Status, task.Name, assigneeNames(task)})
+	}
+	return out, nil
+}
+
+func runTask(ctx context.Context, e *env, fs *flag.FlagSet, args []string) (output, error) {
+	rest, err := e.parse(fs, args, 1)
+	if err != nil {
+		return output{}, err
+	}
+	task, err := e.client.GetTask(ctx, rest[0])
+	if err != nil {
+		return output{}, err
+	}
+	tags := make([]string, len(task.Tags))
+	for i, tag := range task.Tags {
+		tags[i] = tag.Name
+	}
+	return output{
+		headers: []string{"ID", "CUSTOM ID", "STATUS", "LIST", "NAME", "ASSIGNEES", "TAGS", "URL"},
+		rows:    [][]string{{task.Id, task.CustomId, task.Status.Status, task.List.Name, task.Name, assigneeNames(task), strings.Join(tags, ","), task.Url}},
+		data:    task,
+	}, nil
+}
+
+func runLog(ctx context.Context, e *env, fs *flag.FlagSet, args []string) (output, error) {
+	date := fs.String("date", time.Now().Format("2006-01-02"), "day to track the time on, as YYYY-MM-DD")
+	rest, err := e.parse(fs, args, 2)
+	if err != nil {
+		return output{}, err
+	}
+	taskId := rest[0]
+	hours, err := shared.ParseHoursInput(rest[1])
+	if err != nil || hours <= 0 {
+		return output{}, fmt.Errorf("%w: invalid duration %q", errUsage, rest[1])
+	}
+	day, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		return output{}, fmt.Errorf("%w: invalid date %q", errUsage, *date)
+	}
+	if e.config.UserId == "" {
+		return output{}, errors.New("no user configured: open the settings of the TUI first")
+	}
+
+	entries, err := dayEntries(ctx, e, taskId, day)
+	if err != nil {
+		return output{}, err
+	}
+	// The new entry follows the last one of the day, like the timesheet does.
+	start := time.Date(day.Year(), day.Month(), day.Day(), 6, 0, 0, 0, day.Location())
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		start = time.UnixMilli(int64(shared.ToInt(last.Start) + shared.ToInt(last.Duration)))
+	}
+	duration := int(math.Round(hours * 60 * 60 * 1000))
+	if err := e.client.CreateTimeEntry(ctx, taskId, start, duration, e.config.UserId); err != nil {
+		return output{}, err
+	}
+	clients.ClearTimeentriesCache()
+
+	entries, err = dayEntries(ctx, e, taskId, day)
+	if err != nil {
+		return output{}, err
+	}
+	return timeEntriesOutput(entries), nil
+}
+
+func dayEntries(ctx context.Context, e *env, taskId string, day time.Time) ([]clients.TimeEntry, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	all, err := e.client.GetTimeEntriesBetween(ctx, e.config.UserId, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return clients.FilterTimeEntries(all, taskId, day), nil
+}
+
+func timeEntriesOutput(entries []clients.TimeEntry) output {
+	out := output{
+		headers: []string{"ID", "TASK", "START", "HOURS", "BILLABLE", "DESCRIPTION"},
+		data:    entries,
+	}
+	for _, entry := range entries {
+		out.rows = append(out.rows, []string{
+			entry.Id,
+			entry.TaskName(),
+			time.UnixMilli(int64(shared.ToInt(entry.Start))).Format("2006-01-02 15:04"),
+			formatHours(shared.ToHours(entry.Duration)),
+			strconv.FormatBool(entry.Billable),
+			entry.Description,
+		})
+	}
+	return out
+}
+
+type timesheetRow struct {
+	TaskId   string             `json:"task_id"`
+	TaskName string             `json:"task_name"`
+	Hours    map[string]float64 `json:"hours"`
+	Total    float64            `json:"total"`
+}
+
+type timesheetWeek struct {
+	Week  string         `json:"week"`
+	From  string         `json:"from"`
+	To    string         `json:"to"`
+	Days  []string       `json:"days"`
+	Tasks []timesheetRow `json:"tasks"`
+	Total float64        `json:"total"`
+}
+
+// runTimesheet sums the hours tracked on the days of the work week, as the timesheet view shows them.
+func runTimesheet(ctx context.Context, e *env, fs *flag.FlagSet, args []string) (output, error) {
+	year, week := time.Now().ISOWeek()
+	weekFlag := fs.String("week", fmt.Sprintf("%d-W%02d", year, week), "ISO week, as YYYY-Www")
+	if _, err := e.parse(fs, args, 0); err != nil {
+		return output{}, err
+	}
+	monday, err := parseISOWeek(*weekFlag)
+	if err != nil {
+		return output{}, fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if e.config.UserId == "" {
+		return output{}, errors.New("no user configured: open the settings of the TUI first")
+	}
+	workWeek := e.config.WorkWeek
+	if err := workWeek.Validate(); err != nil {
+		return output{}, fmt.Errorf("invalid work_week: %w", err)
+	}
+	start := workWeek.WeekStart(monday)
+	var days []time.Time
+	for _, day := range workWeek.Weekdays() {
+		days = append(days, start.AddDate(0, 0, (int(day-workWeek.Start())+7)%7))
+	}
+	entries, err := e.client.GetTimeEntriesBetween(ctx, e.config.UserId, start, start.AddDate(0, 0, 7))
+	if err != nil {
+		return output{}, err
+	}
+
+	result := timesheetWeek{
+		Week: *weekFlag,
+		From: days[0].Format("2006-01-02"),
+		To:   days[len(days)-1].Format("2006-01-02"),
+	}
+	for _, day := range days {
+		result.Days = append(result.Days, day.Format("2006-01-02"))
+	}
+	rows := map[string]*timesheetRow{}
+	for _, entry := range entries {
+		date := shared.ToDateString(entry.Start)
+		if !slices.Contains(result.Days, date) {
+			continue
+		}
+		row, ok := rows[entry.TaskId()]
+		if !ok {
+			row = &timesheetRow{TaskId: entry.TaskId(), TaskName: entry.TaskName(), Hours: map[string]float64{}}
+			rows[entry.TaskId()] = row
+		}
+		hours := shared.ToHours(entry.Duration)
+		row.Hours[date] += hours
+		row.Total += hours
+		result.Total += hours
+	}
+	for _, row := range rows {
+		result.Tasks = append(result.Tasks, *row)
+	}
+	sort.Slice(result.Tasks, func(i, j int) bool {
+		return result.Tasks[i].TaskName < result.Tasks[j].TaskName
+	})
+
+	out := output{headers: []string{"TASK ID", "TASK"}, data: result}
+	for _, day := range days {
+		out.headers = append(out.headers, strings.ToUpper(day.Format("Mon 02")))
+	}
+	out.headers = append(out.headers, "TOTAL")
+	totals := make([]float64, len(days))
+	for _, row := range result.Tasks {
+		cells := []string{row.TaskId, row.TaskName}
+		for i, date := range result.Days {
+			hours := row.Hours[date]
+			totals[i] += hours
+			cells = append(cells, formatHours(hours))
+		}
+		out.rows = append(out.rows, append(cells, formatHours(row.Total)))
+	}
+	if e.format == formatTable {
+		cells := []string{"", "Total"}
+		for _, total := range totals {
+			cells = append(cells, formatHours(total))
+		}
+		out.rows = append(out.rows, append(cells, formatHours(result.Total)))
+	}
+	return out, nil
+}
+
+// parseISOWeek returns the Monday of an ISO 8601 week such as 2026-W41.
+func parseISOWeek(s string) (time.Time, error) {
+	var year, week int
+	if _, err := fmt.Sscanf(s, "%d-W%d", &year, &week); err != nil || week < 1 || week > 53 {
+		return time.Time{}, fmt.Errorf("invalid week %q, expected YYYY-Www", s)
+	}
+	// January 4th is always in the first ISO week.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+	monday = monday.AddDate(0, 0, (week-1)*7)
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, fmt.Errorf("year %d has no week %d", year, week)
+	}
+	return monday, nil
+}
+
+type commentResult struct {
+	Id     string `json:"id"`
+	TaskId string `json:"task_id"`
+	Text   string `json:"comment_text"`
+}
+
+func runComment(ctx context.Context, e *env, fs *flag.FlagSet, args []string) (output, error) {
+	rest, err := e.parse(fs, args, 2)
+	if err != nil {
+		return output{}, err
+	}
+	taskId, text := rest[0], rest[1]
+	if text == "-" {
+		data, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return output{}, err
+		}
+		text = string(data)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return output{}, fmt.Errorf("%w: empty comment", errUsage)
+	}
+	id, err := e.client.CreateTaskComment(ctx, taskId, text)
+	if err != nil {
+		return output{}, err
+	}
+	return output{
+		headers: []string{"ID", "TASK ID", "COMMENT"},
+		rows:    [][]string{{id, taskId, text}},
+		data:    commentResult{Id: id, TaskId: taskId, Text: text},
+	}, nil
+}
+
+func assigneeNames(task clients.Task) string {
+	names := make([]string, len(task.Assignees))
+	for i, user := range task.Assignees {
+		names[i] = user.Username
+	}
+	return strings.Join(names, ",")
+}
+
+// formatHours renders hours with two decimals, which both humans and spreadsheets can read.
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', 2, 64)
+}
+
+type profileResult struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+func runProfiles(ctx context.Context, e *env, fs *flag.FlagSet, args []string) (output, error) {
+	if _, err := e.parse(fs, args, 0); err != nil {
+		return output{}, err
+	}
+	out := output{headers: []string{"NAME", "ACTIVE"}}
+	var profiles []profileResult
+	for _, name := range clients.Profiles() {
+		active := name == e.config.Profile
+		profiles = append(profiles, profileResult{Name: name, Active: active})
+		out.rows = append(out.rows, []string{name, strconv.FormatBool(active)})
+	}
+	out.data = profiles
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// output is the result of a command. Tables and CSV are rendered from headers and rows,
+// while JSON encodes data so that scripts get every field returned by ClickUp.
+type output struct {
+	headers []string
+	rows    [][]string
+	data    any
+}
+
+func validFormat(format string) bool {
+	return format == formatTable || format == formatJSON || format == formatCSV
+}
+
+func (o output) write(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(o.data)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(o.headers); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(o.rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(o.headers, "\t"))
+		for _, row := range o.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = strings.ReplaceAll(cell, "\n", " ")
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
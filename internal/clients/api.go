@@ -0,0 +1,56 @@
+package clients
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ClickupAPI is the set of ClickUp operations used by the application.
+// ClickupClient implements it against the real API; tests can point a
+// ClickupClient to a fake server or provide their own implementation.
+type ClickupAPI interface {
+	GetCurrentUser(ctx context.Context, token string) (User, error)
+	GetTeams(ctx context.Context, token string) ([]Team, error)
+	GetSpaces(ctx context.Context, token string, teamId string) ([]Space, error)
+	GetFolders(ctx context.Context, token string, spaceId string) ([]Folder, error)
+	GetLists(ctx context.Context, token string, folderId string) ([]List, error)
+	GetFolderlessLists(ctx context.Context, token string, spaceId string) ([]List, error)
+	GetViews(ctx context.Context, token string, parent ViewParent, parentId string) ([]View, error)
+	GetTask(ctx context.Context, taskId string) (Task, error)
+	RefreshTask(ctx context.Context, taskId string) (Task, error)
+	UpdateTaskStatus(ctx context.Context, taskId string, status string) (Task, error)
+	CreateTask(ctx context.Context, listId string, task NewTask) (Task, error)
+	UpdateTask(ctx context.Context, taskId string, update TaskUpdate) (Task, error)
+	SetCustomField(ctx context.Context, taskId string, field CustomField, value any) (Task, error)
+	ResolveChecklistItem(ctx context.Context, taskId string, checklistId string, itemId string, resolved bool) error
+	UploadAttachment(ctx context.Context, taskId string, name string, content io.Reader) (Attachment, error)
+	DownloadAttachment(ctx context.Context, attachment Attachment, w io.Writer) error
+	GetTaskComments(ctx context.Context, taskId string) ([]Comment, error)
+	CreateTaskComment(ctx context.Context, taskId string, text string) (string, error)
+	GetCommentReplies(ctx context.Context, commentId string) ([]Comment, error)
+	CreateCommentReply(ctx context.Context, commentId string, text string) (string, error)
+	UpdateComment(ctx context.Context, commentId string, update CommentUpdate) error
+	AddCommentReaction(ctx context.Context, commentId string, reaction string) error
+	RemoveCommentReaction(ctx context.Context, commentId string, reaction string) error
+	GetTimesheetTasks(ctx context.Context, filter string) ([]Task, error)
+	GetViewTasks(ctx context.Context, viewId string) ([]Task, error)
+	SyncViewTasks(ctx context.Context, viewId string) ([]Task, error)
+	GetTimesheetsEntries(ctx context.Context, userId string) ([]TimeEntry, error)
+	GetTimeEntriesBetween(ctx context.Context, userId string, start time.Time, end time.Time) ([]TimeEntry, error)
+	GetOlderTimeEntries(ctx context.Context, userId string) ([]TimeEntry, error)
+	DeleteTimeEntry(ctx context.Context, taskId string, entryId string) error
+	CreateTimeEntry(ctx context.Context, taskId string, start time.Time, duration int, userId string) error
+	UpdateTimeEntry(ctx context.Context, entry TimeEntry) error
+	UpdateTracking(ctx context.Context, userId string, taskId string, day time.Time, hours float64) error
+	StartTimer(ctx context.Context, taskId string) (TimeEntry, error)
+	StopTimer(ctx context.Context) (TimeEntry, error)
+	GetRunningTimer(ctx context.Context) (*TimeEntry, error)
+	Offline() bool
+	QueuedWrites() []QueuedWrite
+	ReplayWrites(ctx context.Context) (int, error)
+	RetryWrite(id int, overwrite bool) error
+	DiscardWrite(id int) error
+}
+
+var _ ClickupAPI = (*ClickupClient)(nil)
@@ -1,31 +1,109 @@
 package clients
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
 
-type ClickupCache struct {
-	TimesheetTasks   []Task               `json:"timesheet_tasks"`
-	TimeEntries      []TimeEntry          `json:"time_entries"`
-	ViewTasks        []Task               `json:"view_tasks"`
-	TaskByID         map[string]Task      `json:"task_by_id"`
-	CommentsByTaskID map[string][]Comment `json:"comments_by_task_id"`
-	ExpiredAt        int64                `json:"expired_at"`
+	bolt "go.etcd.io/bbolt"
+)
+
+// cacheTask stores an updated task and replaces it in the cached lists of tasks.
+// The lists keep their expiry.
+func cacheTask(task Task) error {
+	if err := putCached(kindTask, task.Id, task); err != nil {
+		return err
+	}
+	return withStore(true, func(tx *bolt.Tx) error {
+		scope := activeScope()
+		b, err := scope.bucket(tx, kindListTask, false)
+		if b == nil || err != nil || b.Get([]byte(task.Id)) == nil {
+			return err
+		}
+		return scope.put(tx, kindListTask, task.Id, task, 0)
+	})
 }
 
-func (c *ClickupCache) IsExpired() bool {
-	if c.ExpiredAt == 0 {
-		return true
+// cachedTasks reads a list of tasks of the active scope: the ids stored under its key, then
+// their tasks. A list missing one of its tasks is not found, to have it fetched again.
+func cachedTasks(kind storeKind, key string) (tasks []Task, found bool, fresh bool) {
+	err := withStore(false, func(tx *bolt.Tx) error {
+		scope := activeScope()
+		lists, err := scope.bucket(tx, kind, false)
+		if lists == nil || err != nil {
+			return err
+		}
+		value := lists.Get([]byte(key))
+		if value == nil {
+			return nil
+		}
+		var r record
+		var ids []string
+		if err := json.Unmarshal(value, &r); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(r.Data, &ids); err != nil {
+			return err
+		}
+		items, err := scope.bucket(tx, kindListTask, false)
+		if err != nil {
+			return err
+		}
+		tasks = make([]Task, 0, len(ids))
+		for _, id := range ids {
+			var item record
+			var task Task
+			if items == nil || json.Unmarshal(items.Get([]byte(id)), &item) != nil || json.Unmarshal(item.Data, &task) != nil {
+				return fmt.Errorf("task %s of the list is not stored", id)
+			}
+			tasks = append(tasks, task)
+		}
+		found, fresh = true, r.fresh()
+		return nil
+	})
+	if err != nil {
+		return nil, false, false
 	}
-	return c.ExpiredAt < time.Now().Unix()
+	return tasks, found, fresh
 }
 
-func (c *ClickupCache) BumpExpiry() {
-	c.ExpiredAt = time.Now().Add(time.Hour * 1).Unix()
+// putTasks stores a list of tasks of the active scope as the ids of its tasks, with the
+// tasks that changed since it was last stored.
+func putTasks(kind storeKind, key string, tasks []Task, changed []Task) error {
+	expiresAt := time.Now().Add(storeTTL[kind]).UnixMilli()
+	return withStore(true, func(tx *bolt.Tx) error {
+		scope := activeScope()
+		for _, task := range changed {
+			if err := scope.put(tx, kindListTask, task.Id, task, 0); err != nil {
+				return err
+			}
+		}
+		return scope.put(tx, kind, key, taskIds(tasks), expiresAt)
+	})
+}
+
+func taskIds(tasks []Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.Id
+	}
+	return ids
 }
 
-func (c *ClickupCache) Clear() {
-	c.TimesheetTasks = nil
-	c.TimeEntries = nil
-	c.ViewTasks = nil
-	c.TaskByID = make(map[string]Task)
-	c.CommentsByTaskID = make(map[string][]Comment)
+// forgetComment expires the cached comments of the task a comment belongs to.
+func forgetComment(commentId string) error {
+	return updateCached(kindComments, func(_ string, r *record) (bool, error) {
+		var comments []Comment
+		if json.Unmarshal(r.Data, &comments) != nil {
+			return false, nil
+		}
+		if !slices.ContainsFunc(comments, func(c Comment) bool {
+			return c.Id == commentId || slices.ContainsFunc(c.Replies, func(r Comment) bool { return r.Id == commentId })
+		}) {
+			return false, nil
+		}
+		r.ExpiresAt = 0
+		return true, nil
+	})
 }
@@ -2,24 +2,34 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
-	"os"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mceck/clickup-tui/internal/shared"
 )
 
-var cache = ClickupCache{}
+const DefaultBaseURL = "https://api.clickup.com"
 
 type ClickupClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	APIToken   string
 	TeamID     string
+	// QueueWrites keeps the changes to the time entries made while ClickUp cannot be
+	// reached in a queue on disk, to send them later with ReplayWrites.
+	QueueWrites bool
+	offline     atomic.Bool
 }
 
 type TaskResponse struct {
@@ -35,520 +45,851 @@ type TeamsResponse struct {
 	Teams []Team `json:"teams"`
 }
 
-func SaveCache() error {
-	cache.BumpExpiry()
-	file, err := json.MarshalIndent(cache, "", " ")
-	if err != nil {
-		return err
-	}
-	// create directory if it doesn't exist
-	dirPath := os.ExpandEnv("$HOME/.config/clickup-tui")
-	err = os.MkdirAll(dirPath, 0755)
-	if err != nil {
-		return err
-	}
-	err = os.WriteFile(dirPath+"/cache.json", file, 0644)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func NewClickupClient(apiToken string, teamId string) *ClickupClient {
-	// read from file
-	dirPath := os.ExpandEnv("$HOME/.config/clickup-tui")
-	file, err := os.ReadFile(dirPath + "/cache.json")
-	if err == nil {
-		err = json.Unmarshal(file, &cache)
-		if err != nil {
-			fmt.Println("Error reading cache file:", err)
-		}
-	}
+	return NewClickupClientWithBaseURL(DefaultBaseURL, apiToken, teamId)
+}
 
+// NewClickupClientWithBaseURL creates a client for a ClickUp compatible server,
+// such as a local stand-in used for tests.
+func NewClickupClientWithBaseURL(baseURL string, apiToken string, teamId string) *ClickupClient {
 	return &ClickupClient{
-		BaseURL:    "https://api.clickup.com",
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
 		HTTPClient: &http.Client{},
 		APIToken:   apiToken,
 		TeamID:     teamId,
 	}
 }
 
-func (c *ClickupClient) GetCurrentUser(token string) (User, error) {
-	url := fmt.Sprintf("%s/api/v2/user", c.BaseURL)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return User{}, err
-	}
-	req.Header.Set("Authorization", token)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return User{}, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return User{}, fmt.Errorf("failed to get current user: %s", resp.Status)
-	}
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return User{}, err
-	}
+func (c *ClickupClient) GetCurrentUser(ctx context.Context, token string) (User, error) {
 	var user UserResponse
-	err = json.Unmarshal(responseBody, &user)
-	if err != nil {
+	if err := c.doWithToken(ctx, token, http.MethodGet, "/api/v2/user", nil, &user); err != nil {
 		return User{}, err
 	}
 	return user.User, nil
 }
 
-func (c *ClickupClient) GetTeams(token string) ([]Team, error) {
-	url := fmt.Sprintf("%s/api/v2/team", c.BaseURL)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
+func (c *ClickupClient) GetTeams(ctx context.Context, token string) ([]Team, error) {
+	var teams TeamsResponse
+	if err := c.doWithToken(ctx, token, http.MethodGet, "/api/v2/team", nil, &teams); err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", token)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get current user: %s", resp.Status)
+	return teams.Teams, nil
+}
+
+// ViewParent is the level of the hierarchy a view is attached to.
+type ViewParent string
+
+const (
+	ViewParentTeam   ViewParent = "team"
+	ViewParentSpace  ViewParent = "space"
+	ViewParentFolder ViewParent = "folder"
+	ViewParentList   ViewParent = "list"
+)
+
+// GetSpaces fetches the spaces of a team. Like GetTeams, the hierarchy methods take the token
+// explicitly so that they can be used while the user is still configuring the client.
+func (c *ClickupClient) GetSpaces(ctx context.Context, token string, teamId string) ([]Space, error) {
+	var data struct {
+		Spaces []Space `json:"spaces"`
 	}
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
+	path := fmt.Sprintf("/api/v2/team/%s/space?archived=false", teamId)
+	if err := c.doWithToken(ctx, token, http.MethodGet, path, nil, &data); err != nil {
 		return nil, err
 	}
-	var user TeamsResponse
-	err = json.Unmarshal(responseBody, &user)
-	if err != nil {
+	return data.Spaces, nil
+}
+
+func (c *ClickupClient) GetFolders(ctx context.Context, token string, spaceId string) ([]Folder, error) {
+	var data struct {
+		Folders []Folder `json:"folders"`
+	}
+	path := fmt.Sprintf("/api/v2/space/%s/folder?archived=false", spaceId)
+	if err := c.doWithToken(ctx, token, http.MethodGet, path, nil, &data); err != nil {
 		return nil, err
 	}
-	return user.Teams, nil
+	return data.Folders, nil
 }
 
-func (c *ClickupClient) getTasksPage(page int, qs string) (TaskResponse, error) {
-	url := fmt.Sprintf("%s/api/v2/team/%s/task?%s&page=%d", c.BaseURL, c.TeamID, qs, page)
-	req, err := http.NewRequest("GET", url, nil)
-	data := TaskResponse{}
-	if err != nil {
-		return data, err
-	}
-	req.Header.Set("Authorization", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return data, err
+// GetLists fetches the lists of a folder.
+func (c *ClickupClient) GetLists(ctx context.Context, token string, folderId string) ([]List, error) {
+	return c.getLists(ctx, token, fmt.Sprintf("/api/v2/folder/%s/list?archived=false", folderId))
+}
+
+// GetFolderlessLists fetches the lists placed directly in a space.
+func (c *ClickupClient) GetFolderlessLists(ctx context.Context, token string, spaceId string) ([]List, error) {
+	return c.getLists(ctx, token, fmt.Sprintf("/api/v2/space/%s/list?archived=false", spaceId))
+}
+
+func (c *ClickupClient) getLists(ctx context.Context, token string, path string) ([]List, error) {
+	var data struct {
+		Lists []List `json:"lists"`
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return data, fmt.Errorf("failed to get tasks: %s", resp.Status)
+	if err := c.doWithToken(ctx, token, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
 	}
+	return data.Lists, nil
+}
 
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return data, err
+// GetViews fetches the views attached to a team, space, folder or list.
+func (c *ClickupClient) GetViews(ctx context.Context, token string, parent ViewParent, parentId string) ([]View, error) {
+	var data struct {
+		Views []View `json:"views"`
 	}
-	err = json.Unmarshal(responseBody, &data)
-	if err != nil {
-		return data, err
+	path := fmt.Sprintf("/api/v2/%s/%s/view", parent, parentId)
+	if err := c.doWithToken(ctx, token, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
 	}
-	return data, nil
+	return data.Views, nil
 }
 
-func (c *ClickupClient) getViewPage(page int, viewId string) (TaskResponse, error) {
-	url := fmt.Sprintf("%s/api/v2/view/%s/task?page=%d", c.BaseURL, viewId, page)
-	req, err := http.NewRequest("GET", url, nil)
+func (c *ClickupClient) getTasksPage(ctx context.Context, page int, qs string) (TaskResponse, error) {
 	data := TaskResponse{}
-	if err != nil {
-		return data, err
-	}
-	req.Header.Set("Authorization", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return data, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return data, fmt.Errorf("failed to get tasks: %s", resp.Status)
-	}
+	path := fmt.Sprintf("/api/v2/team/%s/task?%s&page=%d", c.TeamID, qs, page)
+	err := c.do(ctx, http.MethodGet, path, nil, &data)
+	return data, err
+}
 
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return data, err
+// getViewPage fetches a page of the tasks of a view. With updatedGt, only the tasks
+// updated after it (unix milliseconds) are returned.
+func (c *ClickupClient) getViewPage(ctx context.Context, page int, viewId string, updatedGt int64) (TaskResponse, error) {
+	data := TaskResponse{}
+	path := fmt.Sprintf("/api/v2/view/%s/task?page=%d", viewId, page)
+	if updatedGt > 0 {
+		path += fmt.Sprintf("&date_updated_gt=%d", updatedGt)
 	}
-	err = json.Unmarshal(responseBody, &data)
-	if err != nil {
-		return data, err
+	err := c.do(ctx, http.MethodGet, path, nil, &data)
+	return data, err
+}
+
+// getAllPages fetches pages in parallel batches until ClickUp reports the last page.
+// The first failing page cancels the other requests of the batch.
+func getAllPages(ctx context.Context, getPage func(ctx context.Context, page int) (TaskResponse, error)) ([]Task, error) {
+	var tasks []Task
+	page := 0
+	const batchSize = 3
+
+	for {
+		batchCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		var errOnce sync.Once
+		var batchErr error
+		results := make([]TaskResponse, batchSize)
+
+		for i := 0; i < batchSize; i++ {
+			wg.Add(1)
+			go func(pIdx int, currentPage int) {
+				defer wg.Done()
+				res, err := getPage(batchCtx, currentPage)
+				if err != nil {
+					errOnce.Do(func() {
+						batchErr = err
+						cancel()
+					})
+					return
+				}
+				results[pIdx] = res
+			}(i, page+i)
+		}
+		wg.Wait()
+		cancel()
+		if batchErr != nil {
+			return nil, batchErr
+		}
+
+		anyLastPageInBatch := false
+		for i := 0; i < batchSize; i++ {
+			tasks = append(tasks, results[i].Tasks...)
+			if results[i].LastPage {
+				anyLastPageInBatch = true
+				break
+			}
+		}
+
+		if anyLastPageInBatch {
+			break
+		}
+		page += batchSize
 	}
-	return data, nil
+	return tasks, nil
 }
 
-func (c *ClickupClient) GetTask(taskId string) (Task, error) {
-	if cache.IsExpired() {
-		cache.Clear()
+func (c *ClickupClient) GetTask(ctx context.Context, taskId string) (Task, error) {
+	var task Task
+	found, fresh := cached(kindTask, taskId, &task)
+	if fresh {
+		return task, nil
 	}
-	if cache.TaskByID != nil {
-		if task, ok := cache.TaskByID[taskId]; ok {
+	fetched, err := c.fetchTask(ctx, taskId)
+	if err != nil {
+		if found && IsOffline(err) {
 			return task, nil
 		}
-	} else {
-		cache.TaskByID = make(map[string]Task)
+		return Task{}, err
 	}
-	url := fmt.Sprintf("%s/api/v2/task/%s?include_markdown_description=true", c.BaseURL, taskId)
-	req, err := http.NewRequest("GET", url, nil)
+	putCached(kindTask, taskId, fetched)
+	return fetched, nil
+}
+
+// RefreshTask fetches a task from ClickUp, bypassing the cache, and caches the new copy.
+func (c *ClickupClient) RefreshTask(ctx context.Context, taskId string) (Task, error) {
+	task, err := c.fetchTask(ctx, taskId)
 	if err != nil {
 		return Task{}, err
 	}
-	req.Header.Set("Authorization", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
+	cacheTask(task)
+	return task, nil
+}
+
+func (c *ClickupClient) fetchTask(ctx context.Context, taskId string) (Task, error) {
+	var task Task
+	path := fmt.Sprintf("/api/v2/task/%s?include_markdown_description=true&include_subtasks=true", taskId)
+	if err := c.do(ctx, http.MethodGet, path, nil, &task); err != nil {
 		return Task{}, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return Task{}, fmt.Errorf("failed to get task: %s", resp.Status)
+	return task, nil
+}
+
+// UpdateTask applies the changes to a task and returns it as saved by ClickUp.
+// The cached copies of the task are replaced with the new one.
+func (c *ClickupClient) UpdateTask(ctx context.Context, taskId string, update TaskUpdate) (Task, error) {
+	if body := update.body(); len(body) > 0 {
+		if err := c.do(ctx, http.MethodPut, "/api/v2/task/"+taskId, body, nil); err != nil {
+			return Task{}, err
+		}
 	}
-	responseBody, err := io.ReadAll(resp.Body)
+	for _, tag := range update.AddTags {
+		path := fmt.Sprintf("/api/v2/task/%s/tag/%s", taskId, url.PathEscape(tag))
+		if err := c.do(ctx, http.MethodPost, path, nil, nil); err != nil {
+			return Task{}, err
+		}
+	}
+	for _, tag := range update.RemoveTags {
+		path := fmt.Sprintf("/api/v2/task/%s/tag/%s", taskId, url.PathEscape(tag))
+		if err := c.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+			return Task{}, err
+		}
+	}
+	task, err := c.fetchTask(ctx, taskId)
 	if err != nil {
 		return Task{}, err
 	}
-	var task Task
-	err = json.Unmarshal(responseBody, &task)
+	cacheTask(task)
+	return task, nil
+}
+
+// SetCustomField sets the value of a custom field of a task, or removes it when the value
+// is nil, and returns the task as saved by ClickUp. The value has the shape ClickUp expects
+// for the type of the field: an option id for a drop-down, option ids for labels,
+// {"add": [...], "rem": [...]} for users and tasks, unix milliseconds for a date.
+func (c *ClickupClient) SetCustomField(ctx context.Context, taskId string, field CustomField, value any) (Task, error) {
+	path := fmt.Sprintf("/api/v2/task/%s/field/%s", taskId, field.Id)
+	if value == nil {
+		if err := c.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+			return Task{}, err
+		}
+	} else {
+		body := map[string]any{"value": value}
+		if field.Type == FieldDate {
+			body["value_options"] = map[string]bool{"time": false}
+		}
+		if err := c.do(ctx, http.MethodPost, path, body, nil); err != nil {
+			return Task{}, err
+		}
+	}
+	task, err := c.fetchTask(ctx, taskId)
 	if err != nil {
 		return Task{}, err
 	}
-	cache.TaskByID[taskId] = task
-	SaveCache()
+	cacheTask(task)
 	return task, nil
 }
 
-// GetTaskComments fetches comments for a given task ID.
-func (c *ClickupClient) GetTaskComments(taskId string) ([]Comment, error) {
-	if cache.IsExpired() {
-		cache.Clear()
+// ResolveChecklistItem ticks or unticks an item of a checklist of a task.
+func (c *ClickupClient) ResolveChecklistItem(ctx context.Context, taskId string, checklistId string, itemId string, resolved bool) error {
+	path := fmt.Sprintf("/api/v2/checklist/%s/checklist_item/%s", checklistId, itemId)
+	if err := c.do(ctx, http.MethodPut, path, map[string]bool{"resolved": resolved}, nil); err != nil {
+		return err
+	}
+	expireCached(kindTask, taskId)
+	return nil
+}
+
+// UploadAttachment attaches a file to a task and returns the new attachment.
+func (c *ClickupClient) UploadAttachment(ctx context.Context, taskId string, name string, content io.Reader) (Attachment, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("attachment", name)
+	if err != nil {
+		return Attachment{}, err
 	}
-	if cache.CommentsByTaskID == nil {
-		cache.CommentsByTaskID = make(map[string][]Comment)
+	if _, err := io.Copy(part, content); err != nil {
+		return Attachment{}, err
 	}
-	if comments, ok := cache.CommentsByTaskID[taskId]; ok {
-		return comments, nil
+	if err := form.Close(); err != nil {
+		return Attachment{}, err
+	}
+	var attachment Attachment
+	path := fmt.Sprintf("/api/v2/task/%s/attachment", taskId)
+	if err := c.request(ctx, c.APIToken, http.MethodPost, path, form.FormDataContentType(), body.Bytes(), &attachment); err != nil {
+		return Attachment{}, err
 	}
-	url := fmt.Sprintf("%s/api/v2/task/%s/comment", c.BaseURL, taskId)
-	req, err := http.NewRequest("GET", url, nil)
+	expireCached(kindTask, taskId)
+	return attachment, nil
+}
+
+// DownloadAttachment writes the content of an attachment to w. The token is only sent
+// when the file is served by the ClickUp API itself, not by the storage it links to.
+func (c *ClickupClient) DownloadAttachment(ctx context.Context, attachment Attachment, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachment.Url, nil)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if strings.HasPrefix(attachment.Url, c.BaseURL+"/") {
+		req.Header.Set("Authorization", c.APIToken)
 	}
-	req.Header.Set("Authorization", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get comments: %s", resp.Status)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(http.MethodGet, req.URL.Path, resp)
 	}
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// UpdateTaskStatus moves a task to the given status and returns the updated task.
+func (c *ClickupClient) UpdateTaskStatus(ctx context.Context, taskId string, status string) (Task, error) {
+	var task Task
+	body := map[string]interface{}{
+		"status": status,
 	}
+	if err := c.do(ctx, http.MethodPut, "/api/v2/task/"+taskId, body, &task); err != nil {
+		return Task{}, err
+	}
+	expireCached(kindTask, taskId)
+	ClearViewTasksCache()
+	return task, nil
+}
+
+// CreateTask creates a task in a list and returns it as saved by ClickUp.
+func (c *ClickupClient) CreateTask(ctx context.Context, listId string, task NewTask) (Task, error) {
+	var created Task
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v2/list/%s/task", listId), task, &created); err != nil {
+		return Task{}, err
+	}
+	ClearViewTasksCache()
+	return created, nil
+}
+
+type createCommentResponse struct {
+	Id json.Number `json:"id"`
+}
+
+// CreateTaskComment posts a plain text comment on a task and returns its ID.
+func (c *ClickupClient) CreateTaskComment(ctx context.Context, taskId string, text string) (string, error) {
+	reqBody := map[string]interface{}{
+		"comment_text": text,
+		"notify_all":   false,
+	}
+	var data createCommentResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v2/task/%s/comment", taskId), reqBody, &data); err != nil {
+		return "", err
+	}
+	expireCached(kindComments, taskId)
+	return data.Id.String(), nil
+}
+
+// GetCommentReplies fetches the replies in the thread of a comment.
+func (c *ClickupClient) GetCommentReplies(ctx context.Context, commentId string) ([]Comment, error) {
 	var data struct {
 		Comments []Comment `json:"comments"`
 	}
-	err = json.Unmarshal(responseBody, &data)
-	if err != nil {
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v2/comment/%s/reply", commentId), nil, &data); err != nil {
 		return nil, err
 	}
-	cache.CommentsByTaskID[taskId] = data.Comments
-	SaveCache()
 	return data.Comments, nil
 }
 
-func (c *ClickupClient) GetTimesheetTasks(filter string) ([]Task, error) {
-	if cache.IsExpired() {
-		cache.Clear()
+// CreateCommentReply replies in the thread of a comment and returns the ID of the reply.
+func (c *ClickupClient) CreateCommentReply(ctx context.Context, commentId string, text string) (string, error) {
+	reqBody := map[string]interface{}{
+		"comment_text": text,
+		"notify_all":   false,
 	}
-	if cache.TimesheetTasks != nil {
-		return cache.TimesheetTasks, nil
+	var data createCommentResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v2/comment/%s/reply", commentId), reqBody, &data); err != nil {
+		return "", err
 	}
-	var tasks []Task
-	page := 0
-	const batchSize = 3
+	forgetComment(commentId)
+	return data.Id.String(), nil
+}
 
-	for {
-		var wg sync.WaitGroup
-		results := make([]TaskResponse, batchSize)
-		errCh := make(chan error, batchSize)
-		anyLastPageInBatch := false
+// UpdateComment changes the text, the assignee or the resolved state of a comment.
+func (c *ClickupClient) UpdateComment(ctx context.Context, commentId string, update CommentUpdate) error {
+	if err := c.do(ctx, http.MethodPut, "/api/v2/comment/"+commentId, update.body(), nil); err != nil {
+		return err
+	}
+	forgetComment(commentId)
+	return nil
+}
 
-		for i := 0; i < batchSize; i++ {
-			wg.Add(1)
-			go func(pIdx int, currentPage int) {
-				defer wg.Done()
-				res, err := c.getTasksPage(currentPage, filter)
-				if err != nil {
-					errCh <- err
-					return
-				}
-				results[pIdx] = res
-			}(i, page+i)
-		}
-		gwErr := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(gwErr)
-		}()
-
-		select {
-		case <-gwErr:
-		case err := <-errCh:
-			return nil, err
-		}
-		close(errCh)
-		for err := range errCh {
-			if err != nil {
-				return nil, err
-			}
-		}
+// AddCommentReaction reacts to a comment with an emoji name such as "thumbsup".
+// Reactions are not part of the documented v2 API, these are the endpoints used by the ClickUp app.
+func (c *ClickupClient) AddCommentReaction(ctx context.Context, commentId string, reaction string) error {
+	body := map[string]string{"reaction": reaction}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v2/comment/%s/reaction", commentId), body, nil); err != nil {
+		return err
+	}
+	forgetComment(commentId)
+	return nil
+}
 
-		for i := 0; i < batchSize; i++ {
-			tasks = append(tasks, results[i].Tasks...)
-			if results[i].LastPage {
-				anyLastPageInBatch = true
-				break
-			}
-		}
+// RemoveCommentReaction removes a reaction of the current user from a comment.
+func (c *ClickupClient) RemoveCommentReaction(ctx context.Context, commentId string, reaction string) error {
+	path := fmt.Sprintf("/api/v2/comment/%s/reaction/%s", commentId, url.PathEscape(reaction))
+	if err := c.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return err
+	}
+	forgetComment(commentId)
+	return nil
+}
 
-		if anyLastPageInBatch {
-			break
+// GetTaskComments fetches comments for a given task ID.
+func (c *ClickupClient) GetTaskComments(ctx context.Context, taskId string) ([]Comment, error) {
+	var comments []Comment
+	found, fresh := cached(kindComments, taskId, &comments)
+	if fresh {
+		return comments, nil
+	}
+	var data struct {
+		Comments []Comment `json:"comments"`
+	}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v2/task/%s/comment", taskId), nil, &data); err != nil {
+		if found && IsOffline(err) {
+			return comments, nil
 		}
-		page += batchSize
+		return nil, err
 	}
+	putCached(kindComments, taskId, data.Comments)
+	return data.Comments, nil
+}
 
-	cache.TimesheetTasks = tasks
-	SaveCache()
-	return tasks, nil
+func (c *ClickupClient) GetTimesheetTasks(ctx context.Context, filter string) ([]Task, error) {
+	return syncTasks(ctx, kindTimesheetTasks, filter, false, func(ctx context.Context, page int, updatedGt int64) (TaskResponse, error) {
+		qs := filter
+		if updatedGt > 0 {
+			qs += fmt.Sprintf("&date_updated_gt=%d", updatedGt)
+		}
+		return c.getTasksPage(ctx, page, qs)
+	})
 }
 
-func (c *ClickupClient) GetViewTasks(viewId string) ([]Task, error) {
-	if cache.IsExpired() {
-		cache.Clear()
-	}
-	if cache.ViewTasks != nil {
-		return cache.ViewTasks, nil
-	}
-	var tasks []Task
-	page := 0
-	const batchSize = 3
+func (c *ClickupClient) GetViewTasks(ctx context.Context, viewId string) ([]Task, error) {
+	return c.viewTasks(ctx, viewId, false)
+}
 
-	for {
-		var wg sync.WaitGroup
-		results := make([]TaskResponse, batchSize)
-		errCh := make(chan error, batchSize)
-		anyLastPageInBatch := false
+// SyncViewTasks returns the tasks of a view after fetching the ones updated since the
+// last sync, even when the cached ones are still fresh.
+func (c *ClickupClient) SyncViewTasks(ctx context.Context, viewId string) ([]Task, error) {
+	return c.viewTasks(ctx, viewId, true)
+}
 
-		for i := 0; i < batchSize; i++ {
-			wg.Add(1)
-			go func(pIdx int, currentPage int) {
-				defer wg.Done()
-				res, err := c.getViewPage(currentPage, viewId)
-				if err != nil {
-					errCh <- err
-					return
-				}
-				results[pIdx] = res
-			}(i, page+i)
-		}
+func (c *ClickupClient) viewTasks(ctx context.Context, viewId string, sync bool) ([]Task, error) {
+	return syncTasks(ctx, kindViewTasks, viewId, sync, func(ctx context.Context, page int, updatedGt int64) (TaskResponse, error) {
+		return c.getViewPage(ctx, page, viewId, updatedGt)
+	})
+}
 
-		gwErr := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(gwErr)
-		}()
+// taskSync is the state of the sync of a list of tasks with ClickUp.
+type taskSync struct {
+	UpdatedAt int64 `json:"updated_at"` // most recent date_updated of the tasks received, unix milliseconds
+	FullAt    int64 `json:"full_at"`    // when every page was last fetched, unix milliseconds
+}
 
-		select {
-		case <-gwErr:
-		case err := <-errCh:
-			return nil, err
-		}
-		close(errCh)
-		for err := range errCh {
-			if err != nil {
-				return nil, err
-			}
+// syncTasks returns the cached tasks of a view or of a filter, once they are expired
+// fetching only the tasks updated since the last sync and merging them in. Deleted
+// tasks, and the ones that left the list, are not part of the changes: the whole list
+// is fetched again when the sync state expires, every fullSyncInterval. With sync, the
+// changes are fetched even when the cached tasks are fresh.
+func syncTasks(ctx context.Context, kind storeKind, key string, sync bool, getPage func(ctx context.Context, page int, updatedGt int64) (TaskResponse, error)) ([]Task, error) {
+	tasks, found, fresh := cachedTasks(kind, key)
+	if fresh && !sync {
+		return tasks, nil
+	}
+	syncKey := string(kind) + "/" + key
+	var state taskSync
+	_, syncFresh := cached(kindSync, syncKey, &state)
+	full := !found || !syncFresh || state.UpdatedAt == 0
+	updatedGt := state.UpdatedAt
+	if full {
+		updatedGt = 0
+	}
+	fetched, err := getAllPages(ctx, func(ctx context.Context, page int) (TaskResponse, error) {
+		return getPage(ctx, page, updatedGt)
+	})
+	if err != nil {
+		if found && IsOffline(err) {
+			return tasks, nil
 		}
-
-		for i := 0; i < batchSize; i++ {
-			tasks = append(tasks, results[i].Tasks...)
-			if results[i].LastPage {
-				anyLastPageInBatch = true
-				break
-			}
+		return nil, err
+	}
+	if full {
+		tasks = fetched
+		state = taskSync{FullAt: time.Now().UnixMilli()}
+	} else {
+		tasks = mergeTasks(tasks, fetched)
+	}
+	for _, task := range fetched {
+		if updated, err := strconv.ParseInt(task.DateUpdated, 10, 64); err == nil {
+			state.UpdatedAt = max(state.UpdatedAt, updated)
 		}
+	}
+	putTasks(kind, key, tasks, fetched)
+	putCachedUntil(kindSync, syncKey, state, state.FullAt+fullSyncInterval.Milliseconds())
+	return tasks, nil
+}
 
-		if anyLastPageInBatch {
-			break
+// mergeTasks replaces the tasks that changed in a list and appends the new ones.
+func mergeTasks(tasks []Task, changed []Task) []Task {
+	tasks = slices.Clone(tasks)
+	for _, task := range changed {
+		if i := slices.IndexFunc(tasks, func(t Task) bool { return t.Id == task.Id }); i >= 0 {
+			tasks[i] = task
+		} else {
+			tasks = append(tasks, task)
 		}
-		page += batchSize
 	}
-
-	cache.ViewTasks = tasks
-	SaveCache()
-	return tasks, nil
+	return tasks
 }
 
 type TsResponse struct {
 	Data []TimeEntry
 }
 
-func (c *ClickupClient) GetTimesheetsEntries(userId string) ([]TimeEntry, error) {
-	if cache.IsExpired() {
-		cache.Clear()
-	}
-	if cache.TimeEntries != nil {
-		return cache.TimeEntries, nil
+// GetTimesheetsEntries returns the recent entries of a user, with the changes still
+// waiting in the write queue applied.
+func (c *ClickupClient) GetTimesheetsEntries(ctx context.Context, userId string) ([]TimeEntry, error) {
+	var entries []TimeEntry
+	found, fresh := cached(kindTimeEntries, userId, &entries)
+	if fresh {
+		return withQueuedWrites(entries), nil
 	}
-	url := fmt.Sprintf("%s/api/v2/team/%s/time_entries?assignee=%s", c.BaseURL, c.TeamID, userId)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
+	data := TsResponse{}
+	path := fmt.Sprintf("/api/v2/team/%s/time_entries?assignee=%s", c.TeamID, userId)
+	if err := c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		if found && IsOffline(err) {
+			return withQueuedWrites(entries), nil
+		}
 		return nil, err
 	}
-	req.Header.Set("Authorization", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
+	putCached(kindTimeEntries, userId, data.Data)
+	return withQueuedWrites(data.Data), nil
+}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
+// trackingEpoch is when the history of the entries starts: ClickUp did not exist before.
+var trackingEpoch = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get timesheets: %s", resp.Status)
-	}
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
+// recentDays is how many days before today GetTimesheetsEntries covers for sure: without a
+// range ClickUp returns the entries of the last 30 days.
+const recentDays = 29
+
+// RecentEntriesStart returns the midnight from which GetTimesheetsEntries has every entry.
+func RecentEntriesStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()-recentDays, 0, 0, 0, 0, now.Location())
+}
+
+// GetOlderTimeEntries returns the entries of a user that started before RecentEntriesStart,
+// the history that GetTimesheetsEntries does not cover. They seldom change, so they are
+// cached for a day and ClearTimeentriesCache keeps them.
+func (c *ClickupClient) GetOlderTimeEntries(ctx context.Context, userId string) ([]TimeEntry, error) {
+	before := RecentEntriesStart(time.Now())
+	key := userId + "/" + before.Format("2006-01-02")
+	var entries []TimeEntry
+	found, fresh := cached(kindTimeHistory, key, &entries)
+	if fresh {
+		return entries, nil
+	}
+	fetched, err := c.GetTimeEntriesBetween(ctx, userId, trackingEpoch, before)
+	if err != nil {
+		if found && IsOffline(err) {
+			return entries, nil
+		}
 		return nil, err
 	}
+	putCached(kindTimeHistory, key, fetched)
+	return fetched, nil
+}
+
+// GetTimeEntriesBetween fetches the entries of a user that started in [start, end).
+// Unlike GetTimesheetsEntries it is not limited to the last 30 days and it is not cached.
+func (c *ClickupClient) GetTimeEntriesBetween(ctx context.Context, userId string, start time.Time, end time.Time) ([]TimeEntry, error) {
 	data := TsResponse{}
-	err = json.Unmarshal(responseBody, &data)
-	if err != nil {
+	path := fmt.Sprintf("/api/v2/team/%s/time_entries?assignee=%s&start_date=%d&end_date=%d", c.TeamID, userId, start.UnixMilli(), end.UnixMilli())
+	if err := c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
 		return nil, err
 	}
-	cache.TimeEntries = data.Data
-	SaveCache()
 	return data.Data, nil
 }
 
-func (c *ClickupClient) DeleteTimeEntry(taskId string, entryId string) error {
-	url := fmt.Sprintf("%s/api/v2/task/%s/time/%s", c.BaseURL, taskId, entryId)
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return err
+// DeleteTimeEntry deletes an entry. While ClickUp cannot be reached, the deletion is queued.
+func (c *ClickupClient) DeleteTimeEntry(ctx context.Context, taskId string, entryId string) error {
+	if strings.HasPrefix(entryId, localEntryPrefix) {
+		return errEntryQueued
 	}
-	req.Header.Set("Authorization", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return err
+	write := QueuedWrite{Kind: WriteDeleteEntry, TaskId: taskId, EntryId: entryId, Base: shownEntry(entryId)}
+	if c.queueing() {
+		return queueWrite(write)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to delete timesheet: %s %s", entryId, resp.Status)
+	err := c.deleteTimeEntry(ctx, taskId, entryId)
+	if c.QueueWrites && IsOffline(err) {
+		return queueWrite(write)
+	}
+	return err
+}
+
+func (c *ClickupClient) deleteTimeEntry(ctx context.Context, taskId string, entryId string) error {
+	path := fmt.Sprintf("/api/v2/task/%s/time/%s", taskId, entryId)
+	if err := c.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete timesheet %s: %w", entryId, err)
 	}
 	return nil
 }
 
-func (c *ClickupClient) CreateTimeEntry(taskId string, start time.Time, duration int, userId string) error {
-	url := fmt.Sprintf("%s/api/v2/task/%s/time", c.BaseURL, taskId)
+// CreateTimeEntry adds an entry to a task. While ClickUp cannot be reached, the entry is
+// queued. A creation whose answer was lost may have reached ClickUp: before it is sent
+// again, the entries of the day are checked for one with the same start and duration.
+func (c *ClickupClient) CreateTimeEntry(ctx context.Context, taskId string, start time.Time, duration int, userId string) error {
+	write := QueuedWrite{Kind: WriteCreateEntry, TaskId: taskId, UserId: userId, Start: start.Unix() * 1000, Duration: duration}
+	if c.queueing() {
+		return queueWrite(write)
+	}
+	err := c.createTimeEntry(ctx, taskId, start, duration)
+	if c.QueueWrites && IsOffline(err) {
+		return queueWrite(write)
+	}
+	return err
+}
+
+func (c *ClickupClient) createTimeEntry(ctx context.Context, taskId string, start time.Time, duration int) error {
 	reqBody := map[string]interface{}{
 		"start": start.Unix() * 1000,
 		"time":  duration,
 	}
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return err
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v2/task/%s/time", taskId), reqBody, nil)
+}
+
+type runningTimerResponse struct {
+	Data *TimeEntry `json:"data"`
+}
+
+// StartTimer starts tracking time on a task. ClickUp stops any timer already running.
+func (c *ClickupClient) StartTimer(ctx context.Context, taskId string) (TimeEntry, error) {
+	var data runningTimerResponse
+	path := fmt.Sprintf("/api/v2/team/%s/time_entries/start", c.TeamID)
+	if err := c.do(ctx, http.MethodPost, path, map[string]interface{}{"tid": taskId}, &data); err != nil {
+		return TimeEntry{}, err
 	}
-	req, err := http.NewRequest("POST", url, io.NopCloser(bytes.NewBuffer(body)))
-	if err != nil {
-		return err
+	if data.Data == nil {
+		return TimeEntry{}, fmt.Errorf("failed to start timer on task %s: empty response", taskId)
+	}
+	return *data.Data, nil
+}
+
+// StopTimer stops the running timer and returns the resulting time entry.
+func (c *ClickupClient) StopTimer(ctx context.Context) (TimeEntry, error) {
+	var data runningTimerResponse
+	path := fmt.Sprintf("/api/v2/team/%s/time_entries/stop", c.TeamID)
+	if err := c.do(ctx, http.MethodPost, path, nil, &data); err != nil {
+		return TimeEntry{}, err
+	}
+	ClearTimeentriesCache()
+	if data.Data == nil {
+		return TimeEntry{}, nil
+	}
+	return *data.Data, nil
+}
+
+// GetRunningTimer returns the timer currently running for the user, or nil if there is none.
+func (c *ClickupClient) GetRunningTimer(ctx context.Context) (*TimeEntry, error) {
+	var data runningTimerResponse
+	path := fmt.Sprintf("/api/v2/team/%s/time_entries/current", c.TeamID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &data); err != nil {
+		return nil, err
+	}
+	if data.Data == nil || data.Data.Id == "" {
+		return nil, nil
+	}
+	return data.Data, nil
+}
+
+// UpdateTimeEntry saves the start, duration, description and billable flag of an entry.
+// Fields that are not sent, such as tags, are left untouched by ClickUp.
+// While ClickUp cannot be reached, the change is queued.
+func (c *ClickupClient) UpdateTimeEntry(ctx context.Context, entry TimeEntry) error {
+	if strings.HasPrefix(entry.Id, localEntryPrefix) {
+		return errEntryQueued
+	}
+	write := QueuedWrite{Kind: WriteUpdateEntry, TaskId: entry.TaskId(), TaskName: entry.TaskName(), EntryId: entry.Id, Entry: &entry, Base: shownEntry(entry.Id)}
+	if c.queueing() {
+		return queueWrite(write)
+	}
+	err := c.updateTimeEntry(ctx, entry)
+	if c.QueueWrites && IsOffline(err) {
+		return queueWrite(write)
 	}
-	req.Header.Set("Authorization", c.APIToken)
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to create time entry: %s", resp.Status)
-	}
+	ClearTimeentriesCache()
 	return nil
 }
 
-func (c *ClickupClient) UpdateTracking(userId string, taskId string, day time.Time, hours float64) error {
-	allUserEntries, err := c.GetTimesheetsEntries(userId)
+func (c *ClickupClient) updateTimeEntry(ctx context.Context, entry TimeEntry) error {
+	start := int64(shared.ToInt(entry.Start))
+	duration := int64(shared.ToInt(entry.Duration))
+	reqBody := map[string]interface{}{
+		"start":       start,
+		"end":         start + duration,
+		"duration":    duration,
+		"description": entry.Description,
+		"billable":    entry.Billable,
+	}
+	path := fmt.Sprintf("/api/v2/team/%s/time_entries/%s", c.TeamID, entry.Id)
+	return c.do(ctx, http.MethodPut, path, reqBody, nil)
+}
+
+// FilterTimeEntries returns the entries tracked on a task during the given day, sorted by start time.
+func FilterTimeEntries(entries []TimeEntry, taskId string, day time.Time) []TimeEntry {
+	dayStr := day.Format("2006-01-02")
+	var filtered []TimeEntry
+	for _, entry := range entries {
+		if entry.TaskId() == taskId && shared.ToDateString(entry.Start) == dayStr {
+			filtered = append(filtered, entry)
+		}
+	}
+	slices.SortFunc(filtered, func(a, b TimeEntry) int {
+		return shared.ToInt(a.Start) - shared.ToInt(b.Start)
+	})
+	return filtered
+}
+
+// UpdateTracking changes the total time tracked on a task for a day, preserving the existing entries.
+// While ClickUp cannot be reached, the new total is queued and sent when the connection comes back,
+// unless the time tracked that day changed on ClickUp in the meantime.
+func (c *ClickupClient) UpdateTracking(ctx context.Context, userId string, taskId string, day time.Time, hours float64) error {
+	allUserEntries, err := c.GetTimesheetsEntries(ctx, userId)
 	if err != nil {
 		return fmt.Errorf("UpdateTracking: failed to get timesheet entries: %w", err)
 	}
+	entries := FilterTimeEntries(allUserEntries, taskId, day)
+	write := QueuedWrite{Kind: WriteTracking, TaskId: taskId, UserId: userId, Day: day.Format("2006-01-02"), Hours: hours, BaseMs: trackedMs(entries)}
+	if c.queueing() {
+		return queueWrite(write)
+	}
+	tracked, err := c.applyTracking(ctx, taskId, day, entries, hours)
+	if c.QueueWrites && IsOffline(err) {
+		// The rest of the change is sent on top of the part that reached ClickUp.
+		write.BaseMs = tracked
+		return queueWrite(write)
+	}
+	ClearTimeentriesCache()
+	return err
+}
 
-	entriesToDelete := []string{}
-	dayStr := day.Format("2006-01-02")
+// trackingPlan lists the changes bringing the time tracked on a task for a day to a new total.
+type trackingPlan struct {
+	add    *TimeEntry // start and duration of the new entry
+	remove []TimeEntry
+	trim   *TimeEntry // the entry with its new duration
+}
 
-	for _, entry := range allUserEntries {
-		entryTaskDetails, ok := entry.Task.(map[string]interface{})
-		if !ok {
-			continue
+// planTracking works out how to change the total of the entries of a day, sorted by start.
+// When the total grows, a new entry with the difference is added after the last one of the day,
+// moved back when needed so that it ends by midnight and stays on the day.
+// When it shrinks, the most recent entries are trimmed or removed.
+func planTracking(entries []TimeEntry, day time.Time, hours float64) trackingPlan {
+	plan := trackingPlan{}
+	delta := hoursToMs(hours) - trackedMs(entries)
+	if delta > 0 {
+		midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		start := midnight.Add(6 * time.Hour).UnixMilli()
+		if len(entries) > 0 {
+			last := entries[len(entries)-1]
+			start = int64(shared.ToInt(last.Start) + shared.ToInt(last.Duration))
 		}
-		entryTaskId, ok := entryTaskDetails["id"].(string)
-		if ok && entryTaskId == taskId {
-			entryStart := shared.ToDate(entry.Start)
-			if entryStart.Format("2006-01-02") == dayStr {
-				entriesToDelete = append(entriesToDelete, entry.Id)
-			}
+		start = max(min(start, midnight.AddDate(0, 0, 1).UnixMilli()-int64(delta)), midnight.UnixMilli())
+		plan.add = &TimeEntry{Start: strconv.FormatInt(start, 10), Duration: strconv.Itoa(delta)}
+		return plan
+	}
+
+	toRemove := -delta
+	for i := len(entries) - 1; i >= 0 && toRemove > 0; i-- {
+		entry := entries[i]
+		duration := shared.ToInt(entry.Duration)
+		if duration <= toRemove {
+			plan.remove = append(plan.remove, entry)
+			toRemove -= duration
+			continue
 		}
+		entry.Duration = strconv.Itoa(duration - toRemove)
+		entry.End = strconv.Itoa(shared.ToInt(entry.Start) + duration - toRemove)
+		plan.trim = &entry
+		toRemove = 0
 	}
+	return plan
+}
 
-	for _, entryId := range entriesToDelete {
-		err = c.DeleteTimeEntry(taskId, entryId)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "UpdateTracking: failed to delete entry %s for task %s on day %s: %v\n", entryId, taskId, dayStr, err)
+// applyTracking sends to ClickUp the changes bringing the entries of a day to the new total.
+// It returns the time tracked on ClickUp after the changes that went through, which differs
+// from the one of the entries when it fails halfway.
+func (c *ClickupClient) applyTracking(ctx context.Context, taskId string, day time.Time, entries []TimeEntry, hours float64) (int, error) {
+	dayStr := day.Format("2006-01-02")
+	tracked := trackedMs(entries)
+	plan := planTracking(entries, day, hours)
+	if plan.add != nil {
+		start := time.UnixMilli(int64(shared.ToInt(plan.add.Start)))
+		if err := c.createTimeEntry(ctx, taskId, start, shared.ToInt(plan.add.Duration)); err != nil {
+			return tracked, fmt.Errorf("UpdateTracking: failed to create time entry for task %s on day %s: %w", taskId, dayStr, err)
 		}
+		tracked += shared.ToInt(plan.add.Duration)
 	}
-
-	if hours > 0 {
-		durationMs := int(hours * 60 * 60 * 1000)
-		startOfTracking := time.Date(day.Year(), day.Month(), day.Day(), 6, 0, 0, 0, day.Location())
-		err = c.CreateTimeEntry(taskId, startOfTracking, durationMs, userId)
-		if err != nil {
-			return fmt.Errorf("UpdateTracking: failed to create time entry for task %s on day %s: %w", taskId, dayStr, err)
+	for _, entry := range plan.remove {
+		if err := c.deleteTimeEntry(ctx, taskId, entry.Id); err != nil {
+			return tracked, fmt.Errorf("UpdateTracking: failed to delete entry %s for task %s on day %s: %w", entry.Id, taskId, dayStr, err)
 		}
+		tracked -= shared.ToInt(entry.Duration)
 	}
-
-	ClearTimeentriesCache()
-
-	return nil
+	if plan.trim != nil {
+		if err := c.updateTimeEntry(ctx, *plan.trim); err != nil {
+			return tracked, fmt.Errorf("UpdateTracking: failed to trim entry %s for task %s on day %s: %w", plan.trim.Id, taskId, dayStr, err)
+		}
+		tracked = hoursToMs(hours)
+	}
+	return tracked, nil
 }
 
+// ClearCache expires everything cached for the active profile. The data is still shown
+// when ClickUp cannot be reached.
 func ClearCache() {
-	cache.Clear()
+	for _, kind := range storeKinds {
+		expireCached(kind)
+	}
 }
 
 func ClearTimeentriesCache() {
-	cache.TimeEntries = nil
-	SaveCache()
+	expireCached(kindTimeEntries)
 }
 
 func ClearTimesheetTasksCache() {
-	cache.TimesheetTasks = nil
-	SaveCache()
+	expireCached(kindTimesheetTasks)
 }
 
 func ClearViewTasksCache() {
-	cache.ViewTasks = nil
-	SaveCache()
+	expireCached(kindViewTasks)
+}
+
+func ClearCommentsCache(taskId string) {
+	expireCached(kindComments, taskId)
 }
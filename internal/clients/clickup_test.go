@@ -0,0 +1,515 @@
+package clients_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/fakeclickup"
+)
+
+func newServer(t *testing.T, fixtures fakeclickup.Fixtures) *fakeclickup.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	clients.ClearCache()
+	if err := clients.LoadLocalState(); err != nil {
+		t.Fatal(err)
+	}
+	srv := fakeclickup.New(fixtures)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetViewTasksFetchesAllPages(t *testing.T) {
+	var tasks []clients.Task
+	var ids []string
+	for i := 0; i < 250; i++ {
+		id := fmt.Sprintf("t%d", i)
+		tasks = append(tasks, clients.Task{Id: id, Name: "Task " + id})
+		ids = append(ids, id)
+	}
+	srv := newServer(t, fakeclickup.Fixtures{Tasks: tasks, Views: map[string][]string{"v1": ids}})
+
+	got, err := srv.Client().GetViewTasks(context.Background(), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 250 {
+		t.Fatalf("got %d tasks, want 250", len(got))
+	}
+	if got[249].Id != "t249" {
+		t.Errorf("last task = %s, want t249", got[249].Id)
+	}
+}
+
+func TestTimesheetTasksSyncOnlyTheChanges(t *testing.T) {
+	tagged := func(id string, name string) clients.Task {
+		return clients.Task{Id: id, Name: name, Tags: []clients.Tag{{Name: "timesheet"}}, DateUpdated: "1000"}
+	}
+	srv := newServer(t, fakeclickup.Fixtures{Tasks: []clients.Task{tagged("t1", "One"), tagged("t2", "Two"), tagged("t3", "Three")}})
+	client := srv.Client()
+	ctx := context.Background()
+	const filter = "tags[]=timesheet"
+	if _, err := client.GetTimesheetTasks(ctx, filter); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.PutTask(tagged("t2", "Two renamed"))
+	srv.PutTask(tagged("t4", "Four"))
+	srv.DeleteTask("t1")
+	clients.ClearTimesheetTasksCache()
+	got, err := client.GetTimesheetTasks(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, task := range got {
+		names = append(names, task.Name)
+	}
+	// The deleted task is kept until the next full sync.
+	if want := "One,Two renamed,Three,Four"; strings.Join(names, ",") != want {
+		t.Errorf("tasks after the sync = %v, want %s", names, want)
+	}
+	requests := srv.Requests()
+	if last := requests[len(requests)-1]; !strings.Contains(last, "date_updated_gt=1000") {
+		t.Errorf("the sync fetched every task: %s", last)
+	}
+
+	clients.ClearCache()
+	got, err = client.GetTimesheetTasks(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Id != "t2" {
+		t.Errorf("the full sync kept the deleted task: %+v", got)
+	}
+}
+
+func TestInvalidTokenReturnsAPIError(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{})
+	client := clients.NewClickupClientWithBaseURL(srv.URL, "wrong", fakeclickup.DefaultTeam)
+
+	_, err := client.GetTeams(context.Background(), "wrong")
+	var apiErr *clients.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != "OAUTH_025" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task", Status: clients.Status{Status: "to do"}}},
+	})
+	srv.FailNext("GET /api/v2/task/t1", http.StatusBadGateway, 1)
+
+	task, err := srv.Client().GetTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "Task" {
+		t.Errorf("unexpected task %+v", task)
+	}
+}
+
+func TestCancelledContextStopsRequest(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{Views: map[string][]string{"v1": {}}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := srv.Client().GetViewTasks(ctx, "v1")
+	if !clients.IsCanceled(err) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+}
+
+func TestUpdateTaskStatus(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{
+			{Id: "t1", Status: clients.Status{Status: "to do"}},
+			{Id: "t2", Status: clients.Status{Status: "done", Color: "#00ff00"}},
+		},
+	})
+
+	task, err := srv.Client().UpdateTaskStatus(context.Background(), "t1", "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status.Status != "done" || task.Status.Color != "#00ff00" {
+		t.Errorf("unexpected status %+v", task.Status)
+	}
+}
+
+func TestUpdateTaskFieldsAndTags(t *testing.T) {
+	alice := clients.User{Id: 1, Username: "alice"}
+	bob := clients.User{Id: 2, Username: "bob"}
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{
+			Id:        "t1",
+			Name:      "Old",
+			Assignees: []clients.User{alice},
+			Tags:      []clients.Tag{{Name: "bug"}},
+			Priority:  &clients.Priority{Id: "1", Priority: "urgent"},
+		}, {
+			Id:        "t2",
+			Assignees: []clients.User{bob},
+		}},
+	})
+	client := srv.Client()
+	if _, err := client.GetTask(context.Background(), "t1"); err != nil {
+		t.Fatal(err)
+	}
+
+	name, priority, estimate := "New", 0, int64(90*60*1000)
+	task, err := client.UpdateTask(context.Background(), "t1", clients.TaskUpdate{
+		Name:            &name,
+		Priority:        &priority,
+		TimeEstimate:    &estimate,
+		AddAssignees:    []int{2},
+		RemoveAssignees: []int{1},
+		AddTags:         []string{"ui"},
+		RemoveTags:      []string{"bug"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "New" || task.Priority != nil || task.TimeEstimate != estimate {
+		t.Errorf("fields not updated: %+v", task)
+	}
+	if len(task.Assignees) != 1 || task.Assignees[0].Username != "bob" {
+		t.Errorf("unexpected assignees %+v", task.Assignees)
+	}
+	if len(task.Tags) != 1 || task.Tags[0].Name != "ui" {
+		t.Errorf("unexpected tags %+v", task.Tags)
+	}
+	// The cached copy is replaced, so the modal does not show the old task.
+	cached, err := client.GetTask(context.Background(), "t1")
+	if err != nil || cached.Name != "New" {
+		t.Errorf("cache not updated: %+v %v", cached, err)
+	}
+}
+
+func TestResolveChecklistItemExpiresTheTask(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{
+			{Id: "t1", Checklists: []clients.Checklist{{Id: "c1", Items: []clients.ChecklistItem{{Id: "i1", Name: "Item"}}}}},
+			{Id: "s1", Parent: "t1"},
+		},
+	})
+	client := srv.Client()
+	task, err := client.GetTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(task.SubTasks) != 1 || task.SubTasks[0].Id != "s1" {
+		t.Errorf("subtasks not included: %+v", task.SubTasks)
+	}
+
+	if err := client.ResolveChecklistItem(context.Background(), "t1", "c1", "i1", true); err != nil {
+		t.Fatal(err)
+	}
+	task, err = client.GetTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.Checklists[0].Items[0].Resolved {
+		t.Errorf("the cached task was returned: %+v", task.Checklists)
+	}
+}
+
+func TestCustomFieldsAreDecodedByType(t *testing.T) {
+	var task clients.Task
+	err := json.Unmarshal([]byte(`{"id": "t1", "custom_fields": [
+		{"id": "f1", "name": "Priority", "type": "drop_down", "value": 1,
+			"type_config": {"options": [{"id": "o1", "name": "Low", "orderindex": 0}, {"id": "o2", "name": "High", "orderindex": 1}]}},
+		{"id": "f2", "name": "Budget", "type": "currency", "value": "12.5", "type_config": {"currency_type": "EUR", "precision": 2}},
+		{"id": "f3", "name": "Reviewers", "type": "users", "value": [{"id": 7, "username": "ann"}]},
+		{"id": "f4", "name": "Done", "type": "checkbox", "value": "true"},
+		{"id": "f5", "name": "Progress", "type": "automatic_progress", "value": {"percent_completed": 40}},
+		{"id": "f6", "name": "Notes", "type": "text"},
+		{"id": "f7", "name": "Unknown", "type": "drop_down", "type_config": {"options": "unexpected"}}
+	]}`), &task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := task.CustomFields
+	if got := fields[0].Typed.Options; len(got) != 1 || got[0] != "o2" {
+		t.Errorf("drop_down options = %v, want [o2]", got)
+	}
+	if v := fields[1].Typed; !v.Set || v.Number != 12.5 || *fields[1].TypeConfig.Precision != 2 {
+		t.Errorf("currency = %+v", v)
+	}
+	if v := fields[2].Typed; len(v.Users) != 1 || v.Users[0].Username != "ann" {
+		t.Errorf("users = %+v", v)
+	}
+	if !fields[3].Typed.Checked {
+		t.Error("checkbox not checked")
+	}
+	if v := fields[4].Typed; v.Number != 40 || !fields[4].ReadOnly() {
+		t.Errorf("automatic progress = %+v", v)
+	}
+	if fields[5].Typed.Set || fields[6].Typed.Set {
+		t.Errorf("fields without value are set: %+v %+v", fields[5].Typed, fields[6].Typed)
+	}
+}
+
+func TestSetCustomField(t *testing.T) {
+	priorities := clients.CustomFieldConfig{Options: []clients.CustomFieldOption{{Id: "o1", Name: "Low", Orderindex: 0}, {Id: "o2", Name: "High", Orderindex: 1}}}
+	srv := newServer(t, fakeclickup.Fixtures{
+		User: clients.User{Id: 7, Username: "ann"},
+		Tasks: []clients.Task{{Id: "t1", CustomFields: []clients.CustomField{
+			{Id: "f1", Name: "Priority", Type: clients.FieldDropDown, TypeConfig: priorities, Value: json.RawMessage(`0`)},
+			{Id: "f2", Name: "Points", Type: clients.FieldNumber, Value: json.RawMessage(`3`)},
+			{Id: "f3", Name: "Reviewers", Type: clients.FieldUsers},
+		}}},
+	})
+	client := srv.Client()
+	task, err := client.GetTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err = client.SetCustomField(context.Background(), "t1", task.CustomFields[0], "o2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := task.CustomFields[0].Typed.Options; len(got) != 1 || got[0] != "o2" {
+		t.Errorf("drop_down options = %v, want [o2]", got)
+	}
+	task, err = client.SetCustomField(context.Background(), "t1", task.CustomFields[2], map[string][]int{"add": {7}, "rem": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := task.CustomFields[2].Typed; len(v.Users) != 1 || v.Users[0].Username != "ann" {
+		t.Errorf("users = %+v", v)
+	}
+	if _, err := client.SetCustomField(context.Background(), "t1", task.CustomFields[1], nil); err != nil {
+		t.Fatal(err)
+	}
+	// The cached task was replaced.
+	task, err = client.GetTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.CustomFields[1].Typed.Set {
+		t.Errorf("the number was not removed: %+v", task.CustomFields[1])
+	}
+}
+
+func TestUploadAndDownloadAttachment(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{User: clients.User{Id: 7, Username: "ann"}, Tasks: []clients.Task{{Id: "t1"}}})
+	client := srv.Client()
+	if _, err := client.GetTask(context.Background(), "t1"); err != nil {
+		t.Fatal(err)
+	}
+
+	attachment, err := client.UploadAttachment(context.Background(), "t1", "notes.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachment.Title != "notes.txt" || attachment.Size != 5 || attachment.User.Username != "ann" {
+		t.Errorf("unexpected attachment %+v", attachment)
+	}
+	task, err := client.GetTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(task.Attachments) != 1 {
+		t.Fatalf("the cached task was returned: %+v", task.Attachments)
+	}
+
+	var content strings.Builder
+	if err := client.DownloadAttachment(context.Background(), task.Attachments[0], &content); err != nil {
+		t.Fatal(err)
+	}
+	if content.String() != "hello" {
+		t.Errorf("downloaded %q, want hello", content.String())
+	}
+}
+
+func TestDownloadAttachmentFromStorageDoesNotSendTheToken(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{})
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("the token was sent to the storage")
+		}
+		w.Write([]byte("content"))
+	}))
+	defer storage.Close()
+
+	var content strings.Builder
+	if err := srv.Client().DownloadAttachment(context.Background(), clients.Attachment{Url: storage.URL + "/file.png"}, &content); err != nil {
+		t.Fatal(err)
+	}
+	if content.String() != "content" {
+		t.Errorf("downloaded %q, want content", content.String())
+	}
+}
+
+func TestGetOlderTimeEntriesIsCachedApart(t *testing.T) {
+	recent := time.Now().Add(-time.Hour)
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task", TimeEstimate: 7200000, TimeSpent: 5400000}},
+		TimeEntries: []clients.TimeEntry{
+			trackedEntry("e1", time.Date(2019, 3, 4, 9, 0, 0, 0, time.Local), time.Hour, ""),
+			trackedEntry("e2", recent, 30*time.Minute, ""),
+		},
+	})
+	client := srv.Client()
+	task, err := client.GetTask(context.Background(), "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.TimeEstimate != 7200000 || task.TimeSpent != 5400000 {
+		t.Errorf("estimate %d and time spent %d not decoded", task.TimeEstimate, task.TimeSpent)
+	}
+
+	entries, err := client.GetOlderTimeEntries(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Id != "e1" {
+		t.Fatalf("got %+v, want only the entry of 2019", entries)
+	}
+
+	// Changing the recent entries keeps the history.
+	requests := len(srv.Requests())
+	clients.ClearTimeentriesCache()
+	srv.SetOffline(true)
+	if entries, err := client.GetOlderTimeEntries(context.Background(), "1"); err != nil || len(entries) != 1 {
+		t.Errorf("history not cached: %+v, %v", entries, err)
+	}
+	if got := srv.Requests()[requests:]; len(got) > 0 {
+		t.Errorf("history fetched again: %v", got)
+	}
+}
+
+func TestUpdateTrackingCreatesEntry(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task"}},
+	})
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+
+	if err := srv.Client().UpdateTracking(context.Background(), "1", "t1", day, 1.5); err != nil {
+		t.Fatal(err)
+	}
+	entries := srv.TimeEntries()
+	if len(entries) != 1 || entries[0].Duration != "5400000" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
+
+func trackedEntry(id string, start time.Time, duration time.Duration, description string) clients.TimeEntry {
+	return clients.TimeEntry{
+		Id:          id,
+		Task:        map[string]interface{}{"id": "t1", "name": "Task"},
+		Start:       strconv.FormatInt(start.UnixMilli(), 10),
+		End:         strconv.FormatInt(start.Add(duration).UnixMilli(), 10),
+		Duration:    strconv.FormatInt(duration.Milliseconds(), 10),
+		Description: description,
+		Billable:    true,
+	}
+}
+
+func TestUpdateTrackingAddsDeltaEntry(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks:       []clients.Task{{Id: "t1", Name: "Task"}},
+		TimeEntries: []clients.TimeEntry{trackedEntry("e1", day.Add(9*time.Hour), time.Hour, "Review")},
+	})
+
+	if err := srv.Client().UpdateTracking(context.Background(), "1", "t1", day, 1.5); err != nil {
+		t.Fatal(err)
+	}
+	entries := srv.TimeEntries()
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if entries[0].Description != "Review" || entries[0].Duration != "3600000" {
+		t.Errorf("existing entry changed: %+v", entries[0])
+	}
+	if entries[1].Duration != "1800000" || entries[1].Start != entries[0].End {
+		t.Errorf("unexpected delta entry %+v", entries[1])
+	}
+}
+
+func TestUpdateTrackingKeepsTheDeltaEntryOnTheDay(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks:       []clients.Task{{Id: "t1", Name: "Task"}},
+		TimeEntries: []clients.TimeEntry{trackedEntry("e1", day.Add(20*time.Hour), 2*time.Hour, "Late")},
+	})
+
+	if err := srv.Client().UpdateTracking(context.Background(), "1", "t1", day, 5); err != nil {
+		t.Fatal(err)
+	}
+	entries := srv.TimeEntries()
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	midnight := strconv.FormatInt(day.AddDate(0, 0, 1).UnixMilli(), 10)
+	if entries[1].Duration != "10800000" || entries[1].End != midnight {
+		t.Errorf("delta entry does not end by midnight: %+v", entries[1])
+	}
+}
+
+func TestUpdateTrackingTrimsMostRecentEntries(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task"}},
+		TimeEntries: []clients.TimeEntry{
+			trackedEntry("e1", day.Add(9*time.Hour), 2*time.Hour, "Morning"),
+			trackedEntry("e2", day.Add(14*time.Hour), time.Hour, "Afternoon"),
+			trackedEntry("e3", day.Add(16*time.Hour), 30*time.Minute, "Evening"),
+		},
+	})
+
+	if err := srv.Client().UpdateTracking(context.Background(), "1", "t1", day, 2.5); err != nil {
+		t.Fatal(err)
+	}
+	entries := srv.TimeEntries()
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if entries[0].Id != "e1" || entries[0].Duration != "7200000" {
+		t.Errorf("first entry changed: %+v", entries[0])
+	}
+	if entries[1].Id != "e2" || entries[1].Duration != "1800000" || entries[1].Description != "Afternoon" || !entries[1].Billable {
+		t.Errorf("unexpected trimmed entry %+v", entries[1])
+	}
+}
+
+func TestTimerLifecycle(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task"}},
+	})
+	client := srv.Client()
+	ctx := context.Background()
+
+	if running, err := client.GetRunningTimer(ctx); err != nil || running != nil {
+		t.Fatalf("expected no running timer, got %+v %v", running, err)
+	}
+	if _, err := client.StartTimer(ctx, "t1"); err != nil {
+		t.Fatal(err)
+	}
+	running, err := client.GetRunningTimer(ctx)
+	if err != nil || running == nil || running.TaskId() != "t1" {
+		t.Fatalf("expected timer on t1, got %+v %v", running, err)
+	}
+	if _, err := client.StopTimer(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if entries := srv.TimeEntries(); len(entries) != 1 || entries[0].TaskId() != "t1" {
+		t.Errorf("stopped timer did not create an entry: %+v", entries)
+	}
+}
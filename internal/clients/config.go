@@ -2,54 +2,274 @@ package clients
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 )
 
+// DefaultProfile is the profile stored at the top level of the config file,
+// where the settings lived before profiles were introduced.
+const DefaultProfile = "default"
+
+// DefaultTimesheetFilter selects the tasks of the timesheet when the config does not set one.
+const DefaultTimesheetFilter = "tags[]=timesheet"
+
 type Config struct {
-	ClickupToken    string `json:"clickup_token"`
-	TeamId          string `json:"team_id"`
-	UserId          string `json:"user_id"`
-	ViewId          string `json:"view_id"`
-	InitialView     string `json:"initial_view"` // "kanban", "timesheet"
-	TimesheetFilter string `json:"timesheet_filter"`
+	ClickupToken    string                 `json:"clickup_token"`
+	TeamId          string                 `json:"team_id"`
+	UserId          string                 `json:"user_id"`
+	ViewId          string                 `json:"view_id"`
+	InitialView     string                 `json:"initial_view"` // "kanban", "timesheet"
+	TimesheetFilter string                 `json:"timesheet_filter"`
+	RefreshInterval int                    `json:"refresh_interval,omitempty"` // seconds between background refreshes, 0 disables them
+	Filters         map[string]string      `json:"filters,omitempty"`          // saved board filters, by name
+	Layouts         map[string]BoardLayout `json:"layouts,omitempty"`          // board grouping, by view id
+	BaseURL         string                 `json:"base_url,omitempty"`         // defaults to the public ClickUp API
+	DownloadDir     string                 `json:"download_dir,omitempty"`     // where attachments are saved, defaults to ~/Downloads
+	WorkWeek        WorkWeek               `json:"work_week,omitzero"`         // days and hours of the timesheet
+	Profile         string                 `json:"-"`                          // name of the profile the config belongs to
+}
+
+// BoardLayout is how the board groups the cards of a view: in columns by a field
+// ("status", "assignee", "list", "priority", "tag" or "due") and optionally in
+// horizontal swimlanes by another one.
+type BoardLayout struct {
+	Columns string   `json:"columns"`
+	Lanes   string   `json:"lanes,omitempty"`
+	Fields  []string `json:"fields,omitempty"` // custom fields shown on the cards, by name
+}
+
+// configFile is the layout of config.json. The default profile is kept at the top level
+// so that existing files keep working, the other ones are stored by name.
+type configFile struct {
+	Config
+	CurrentProfile string            `json:"current_profile,omitempty"`
+	Profiles       map[string]Config `json:"profiles,omitempty"`
+}
+
+// NewClient creates a ClickUp client for the configured workspace.
+func (c Config) NewClient() *ClickupClient {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return NewClickupClientWithBaseURL(baseURL, c.ClickupToken, c.TeamId)
+}
+
+// Downloads returns the directory where attachments are saved.
+func (c Config) Downloads() string {
+	if c.DownloadDir == "" {
+		return os.ExpandEnv("$HOME/Downloads")
+	}
+	return expandHome(c.DownloadDir)
+}
+
+// expandHome expands the environment variables of a path and a leading ~/.
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		return os.ExpandEnv("$HOME" + path[1:])
+	}
+	return os.ExpandEnv(path)
 }
 
 var config *Config
 
-func GetConfig() Config {
-	if config != nil {
-		return *config
+// setConfig replaces the config of the process, nil to have it read again from the file,
+// and resolves the scope of the store it uses.
+func setConfig(c *Config) {
+	config = c
+	scope = nil
+	if c != nil {
+		scope = &storeScope{profile: c.Profile, team: c.TeamId}
 	}
-	// read from file
-	dirPath := os.ExpandEnv("$HOME/.config/clickup-tui")
-	file, err := os.ReadFile(dirPath + "/config.json")
-	c := Config{}
+}
+
+// profileOverride is the profile chosen with --profile, it is not persisted.
+var profileOverride string
+
+func configDir() string {
+	return os.ExpandEnv("$HOME/.config/clickup-tui")
+}
+
+func readConfigFile() configFile {
+	f := configFile{}
+	file, err := os.ReadFile(configDir() + "/config.json")
 	if err == nil {
-		err = json.Unmarshal(file, &c)
-		if err == nil {
-		}
+		_ = json.Unmarshal(file, &f)
 	}
-	config = &c
-	return c
+	return f
 }
 
-func SaveConfig(c Config) error {
-	file, err := json.MarshalIndent(c, "", " ")
+func writeConfigFile(f configFile) error {
+	file, err := json.MarshalIndent(f, "", " ")
 	if err != nil {
 		return err
 	}
 	// create directory if it doesn't exist
-	dirPath := os.ExpandEnv("$HOME/.config/clickup-tui")
-	err = os.MkdirAll(dirPath, 0755)
+	err = os.MkdirAll(configDir(), 0755)
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(dirPath+"/config.json", file, 0644)
-	if err != nil {
+	return os.WriteFile(configDir()+"/config.json", file, 0644)
+}
+
+func (f configFile) activeProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if _, ok := f.Profiles[f.CurrentProfile]; ok {
+		return f.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+func (f configFile) profile(name string) (Config, bool) {
+	if name == DefaultProfile {
+		return f.Config, true
+	}
+	c, ok := f.Profiles[name]
+	return c, ok
+}
+
+func GetConfig() Config {
+	if config != nil {
+		return *config
+	}
+	// read from file
+	f := readConfigFile()
+	name := f.activeProfile()
+	c, _ := f.profile(name)
+	c.Profile = name
+	setConfig(&c)
+	return c
+}
+
+// SaveConfig saves the config in its profile, or in the active one when the profile is not set.
+// The cache of the active profile is cleared when it points to another workspace: a new
+// token, server, team or view.
+func SaveConfig(c Config) error {
+	return saveConfig(c, true)
+}
+
+// SavePreferences saves the config like SaveConfig but never clears the cache, for the
+// settings of the interface such as the board layouts and the saved filters.
+func SavePreferences(c Config) error {
+	return saveConfig(c, false)
+}
+
+func saveConfig(c Config, clearChanged bool) error {
+	if c.Profile == "" {
+		c.Profile = GetConfig().Profile
+	}
+	f := readConfigFile()
+	previous, _ := f.profile(c.Profile)
+	if c.Profile == DefaultProfile {
+		f.Config = c
+	} else {
+		if f.Profiles == nil {
+			f.Profiles = map[string]Config{}
+		}
+		f.Profiles[c.Profile] = c
+	}
+	if err := writeConfigFile(f); err != nil {
 		return err
 	}
-	config = &c
-	ClearCache()
-	SaveCache()
+	if c.Profile == GetConfig().Profile {
+		setConfig(&c)
+		if clearChanged && !previous.sameWorkspace(c) {
+			ClearCache()
+		}
+	}
 	return nil
 }
+
+// sameWorkspace reports whether two configs read the same data from ClickUp.
+func (c Config) sameWorkspace(other Config) bool {
+	return c.ClickupToken == other.ClickupToken && c.BaseURL == other.BaseURL &&
+		c.TeamId == other.TeamId && c.ViewId == other.ViewId
+}
+
+// Profiles returns the names of the configured profiles, the default one first.
+func Profiles() []string {
+	f := readConfigFile()
+	names := []string{DefaultProfile}
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names[1:])
+	return names
+}
+
+// UseProfile activates a profile for the current process only, as done by the --profile flag.
+func UseProfile(name string) error {
+	if _, ok := readConfigFile().profile(name); !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	profileOverride = name
+	setConfig(nil)
+	return nil
+}
+
+// SwitchProfile activates a profile and remembers it for the next runs.
+func SwitchProfile(name string) error {
+	f := readConfigFile()
+	if _, ok := f.profile(name); !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	f.CurrentProfile = name
+	if name == DefaultProfile {
+		f.CurrentProfile = ""
+	}
+	if err := writeConfigFile(f); err != nil {
+		return err
+	}
+	profileOverride = ""
+	setConfig(nil)
+	return nil
+}
+
+// CreateProfile adds an empty profile. It inherits the base URL of the active one.
+func CreateProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	f := readConfigFile()
+	if _, ok := f.profile(name); ok {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]Config{}
+	}
+	f.Profiles[name] = Config{BaseURL: GetConfig().BaseURL}
+	return writeConfigFile(f)
+}
+
+// DeleteProfile removes a profile, its cache and its write queue. The default and the active profile cannot be deleted.
+func DeleteProfile(name string) error {
+	if name == DefaultProfile {
+		return errors.New("the default profile cannot be deleted")
+	}
+	if name == GetConfig().Profile {
+		return errors.New("the active profile cannot be deleted")
+	}
+	f := readConfigFile()
+	if _, ok := f.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	delete(f.Profiles, name)
+	if f.CurrentProfile == name {
+		f.CurrentProfile = ""
+	}
+	if err := writeConfigFile(f); err != nil {
+		return err
+	}
+	for _, file := range []string{legacyCacheFile(name), queueFile(name)} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return deleteStoredProfile(name)
+}
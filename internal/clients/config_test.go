@@ -0,0 +1,122 @@
+package clients
+
+import (
+	"os"
+	"testing"
+)
+
+func newConfigHome(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	setConfig(nil)
+	profileOverride = ""
+	t.Cleanup(func() {
+		setConfig(nil)
+		profileOverride = ""
+	})
+	if content != "" {
+		if err := os.MkdirAll(configDir(), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(configDir()+"/config.json", []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return home
+}
+
+func TestLegacyConfigIsDefaultProfile(t *testing.T) {
+	newConfigHome(t, `{"clickup_token": "pk_1", "team_id": "9"}`)
+
+	c := GetConfig()
+	if c.Profile != DefaultProfile || c.ClickupToken != "pk_1" || c.TeamId != "9" {
+		t.Errorf("unexpected config %+v", c)
+	}
+}
+
+func TestProfilesKeepSeparateCaches(t *testing.T) {
+	newConfigHome(t, "")
+	if err := SaveConfig(Config{ClickupToken: "pk_own", TeamId: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := putTasks(kindViewTasks, "v1", []Task{{Id: "own"}}, []Task{{Id: "own"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CreateProfile("client"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SwitchProfile("client"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveConfig(Config{ClickupToken: "pk_client", TeamId: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, found, _ := cachedTasks(kindViewTasks, "v1"); found {
+		t.Fatalf("client profile sees the cache of the default one: %+v", tasks)
+	}
+
+	// The active profile is remembered in the config file.
+	setConfig(nil)
+	if c := GetConfig(); c.Profile != "client" || c.ClickupToken != "pk_client" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	if err := UseProfile(DefaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, found, _ := cachedTasks(kindViewTasks, "v1"); !found || len(tasks) != 1 || tasks[0].Id != "own" {
+		t.Errorf("default cache lost: %+v", tasks)
+	}
+	if c := GetConfig(); c.ClickupToken != "pk_own" {
+		t.Errorf("unexpected config %+v", c)
+	}
+	if err := DeleteProfile(DefaultProfile); err == nil {
+		t.Error("the default profile was deleted")
+	}
+	if err := DeleteProfile("client"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Profiles(); len(got) != 1 {
+		t.Errorf("unexpected profiles %v", got)
+	}
+}
+
+func TestSavingPreferencesKeepsTheCache(t *testing.T) {
+	newConfigHome(t, "")
+	c := Config{ClickupToken: "pk_own", TeamId: "1", ViewId: "v1"}
+	if err := SaveConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := putTasks(kindViewTasks, "v1", []Task{{Id: "t1"}}, []Task{{Id: "t1"}}); err != nil {
+		t.Fatal(err)
+	}
+	fresh := func() bool {
+		_, _, fresh := cachedTasks(kindViewTasks, "v1")
+		return fresh
+	}
+
+	c.Layouts = map[string]BoardLayout{"v1": {Columns: "assignee"}}
+	if err := SavePreferences(c); err != nil {
+		t.Fatal(err)
+	}
+	c.RefreshInterval = 60
+	if err := SaveConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	if !fresh() {
+		t.Fatal("saving the same workspace expired the cache")
+	}
+	if got := GetConfig().Layouts["v1"].Columns; got != "assignee" {
+		t.Errorf("layout = %q, want assignee", got)
+	}
+
+	c.ViewId = "v2"
+	if err := SaveConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	if fresh() {
+		t.Error("changing the view kept the cache fresh")
+	}
+}
@@ -1,17 +1,44 @@
 package clients
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 type TimeEntry struct {
-	Id       string      `json:"id"`
-	Task     interface{} `json:"task"`
-	Duration string      `json:"duration"`
-	Start    string      `json:"start"`
-	End      string      `json:"end"`
+	Id          string      `json:"id"`
+	Task        interface{} `json:"task"`
+	Duration    string      `json:"duration"`
+	Start       string      `json:"start"`
+	End         string      `json:"end"`
+	Description string      `json:"description"`
+	Billable    bool        `json:"billable"`
+	Tags        []Tag       `json:"tags"`
+}
+
+// TaskId returns the id of the task the entry is tracked on.
+func (e TimeEntry) TaskId() string {
+	if t, ok := e.Task.(map[string]interface{}); ok {
+		id, _ := t["id"].(string)
+		return id
+	}
+	return ""
+}
+
+// TaskName returns the name of the task the entry is tracked on.
+func (e TimeEntry) TaskName() string {
+	if t, ok := e.Task.(map[string]interface{}); ok {
+		name, _ := t["name"].(string)
+		return name
+	}
+	return ""
 }
 
 type Status struct {
 	Id         string `json:"id"`
 	Status     string `json:"status"`
 	Color      string `json:"color"`
+	Type       string `json:"type"` // "open", "custom", "done" or "closed"
 	Orderindex int    `json:"orderindex"`
 }
 
@@ -23,6 +50,19 @@ type User struct {
 	ProfilePicture string `json:"profilePicture"`
 }
 
+// IdString returns the id of the user as a string: ClickUp sends it as a number.
+func (u User) IdString() string {
+	switch v := u.Id.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
 type List struct {
 	Id   string `json:"id"`
 	Name string `json:"name"`
@@ -35,25 +75,364 @@ type Tag struct {
 }
 
 type Task struct {
-	Id            string    `json:"id"`
-	Name          string    `json:"name"`
-	Description   string    `json:"markdown_description"`
-	Status        Status    `json:"status"`
-	Url           string    `json:"url"`
-	CustomId      string    `json:"custom_id"`
-	Assignees     []User    `json:"assignees"`
-	List          List      `json:"list"`
-	Tags          []Tag     `json:"tags"`
-	SubTasksCount int       `json:"subtasks_count"`
-	Comments      []Comment `json:"comments,omitempty"`
+	Id            string        `json:"id"`
+	Name          string        `json:"name"`
+	Description   string        `json:"markdown_description"`
+	Status        Status        `json:"status"`
+	Url           string        `json:"url"`
+	CustomId      string        `json:"custom_id"`
+	Assignees     []User        `json:"assignees"`
+	List          List          `json:"list"`
+	Tags          []Tag         `json:"tags"`
+	SubTasksCount int           `json:"subtasks_count"`
+	Priority      *Priority     `json:"priority"`
+	DueDate       string        `json:"due_date"`      // unix milliseconds
+	StartDate     string        `json:"start_date"`    // unix milliseconds
+	TimeEstimate  int64         `json:"time_estimate"` // milliseconds
+	TimeSpent     int64         `json:"time_spent"`    // milliseconds tracked by everyone
+	DateUpdated   string        `json:"date_updated"`  // unix milliseconds
+	Parent        string        `json:"parent"`        // id of the parent task, empty for a top level task
+	CustomFields  []CustomField `json:"custom_fields,omitempty"`
+	Attachments   []Attachment  `json:"attachments,omitempty"`
+	Comments      []Comment     `json:"comments,omitempty"`
+	// Loaded with GetTask only.
+	SubTasks     []Task       `json:"subtasks,omitempty"`
+	Checklists   []Checklist  `json:"checklists,omitempty"`
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+	LinkedTasks  []LinkedTask `json:"linked_tasks,omitempty"`
+}
+
+// CustomFieldType is the type of a custom field, as named by ClickUp.
+type CustomFieldType string
+
+const (
+	FieldDropDown          CustomFieldType = "drop_down"
+	FieldLabels            CustomFieldType = "labels"
+	FieldNumber            CustomFieldType = "number"
+	FieldCurrency          CustomFieldType = "currency"
+	FieldDate              CustomFieldType = "date"
+	FieldUsers             CustomFieldType = "users"
+	FieldCheckbox          CustomFieldType = "checkbox"
+	FieldShortText         CustomFieldType = "short_text"
+	FieldText              CustomFieldType = "text"
+	FieldEmail             CustomFieldType = "email"
+	FieldURL               CustomFieldType = "url"
+	FieldPhone             CustomFieldType = "phone"
+	FieldEmoji             CustomFieldType = "emoji" // a rating
+	FieldManualProgress    CustomFieldType = "manual_progress"
+	FieldAutomaticProgress CustomFieldType = "automatic_progress"
+	FieldTasks             CustomFieldType = "tasks"
+	FieldLocation          CustomFieldType = "location"
+	FieldFormula           CustomFieldType = "formula"
+)
+
+// CustomField is a custom field of a task with its value. ClickUp sends the value in a
+// shape that depends on the type: it is kept as received, so that the task can be cached,
+// and decoded in Typed.
+type CustomField struct {
+	Id         string            `json:"id"`
+	Name       string            `json:"name"`
+	Type       CustomFieldType   `json:"type"`
+	TypeConfig CustomFieldConfig `json:"type_config"`
+	Required   bool              `json:"required"`
+	Value      json.RawMessage   `json:"value,omitempty"`
+	Typed      CustomFieldValue  `json:"-"`
+}
+
+// CustomFieldConfig holds the settings of the custom field types that have some.
+type CustomFieldConfig struct {
+	Options      []CustomFieldOption `json:"options,omitempty"`       // drop_down and labels
+	CurrencyType string              `json:"currency_type,omitempty"` // currency, such as "EUR"
+	Precision    *int                `json:"precision,omitempty"`     // number and currency decimals
+	Count        int                 `json:"count,omitempty"`         // emoji: the highest rating
+	CodePoint    string              `json:"code_point,omitempty"`    // emoji: hexadecimal code of the emoji
+	Start        *float64            `json:"start,omitempty"`         // manual_progress
+	End          *float64            `json:"end,omitempty"`           // manual_progress
+}
+
+// CustomFieldOption is a choice of a drop_down or labels field. Drop-down options have
+// a name, labels a label.
+type CustomFieldOption struct {
+	Id         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	Label      string `json:"label,omitempty"`
+	Color      string `json:"color,omitempty"`
+	Orderindex int    `json:"orderindex"`
+}
+
+func (o CustomFieldOption) Title() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return o.Label
+}
+
+// CustomFieldValue is the decoded value of a custom field. Only the members matching
+// the type of the field are set.
+type CustomFieldValue struct {
+	Set     bool      // false when the task has no value for the field
+	Text    string    // text types, formula, and the address of a location
+	Number  float64   // number, currency, emoji, and the percentage of the progress fields
+	Date    int64     // date, unix milliseconds
+	Checked bool      // checkbox
+	Options []string  // drop_down (a single option) and labels: ids of the selected options
+	Users   []User    // users
+	Tasks   []TaskRef // tasks
+}
+
+// TaskRef is a task referenced by a tasks custom field.
+type TaskRef struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Option returns an option of a drop_down or labels field by id.
+func (f CustomField) Option(id string) (CustomFieldOption, bool) {
+	for _, o := range f.TypeConfig.Options {
+		if o.Id == id {
+			return o, true
+		}
+	}
+	return CustomFieldOption{}, false
+}
+
+// ReadOnly tells whether the value of a field is computed by ClickUp.
+func (f CustomField) ReadOnly() bool {
+	switch f.Type {
+	case FieldAutomaticProgress, FieldFormula, FieldLocation:
+		return true
+	}
+	return false
+}
+
+func (f *CustomField) UnmarshalJSON(data []byte) error {
+	type plain CustomField
+	var raw struct {
+		plain
+		TypeConfig json.RawMessage `json:"type_config"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = CustomField(raw.plain)
+	// The settings of the types not used here may not fit the struct, they are not needed.
+	json.Unmarshal(raw.TypeConfig, &f.TypeConfig)
+	f.Typed = f.decodeValue()
+	return nil
+}
+
+// decodeValue decodes the value of the field for its type. A value that does not have
+// the expected shape is left unset.
+func (f CustomField) decodeValue() CustomFieldValue {
+	if len(f.Value) == 0 || string(f.Value) == "null" {
+		return CustomFieldValue{}
+	}
+	v := CustomFieldValue{Set: true}
+	switch f.Type {
+	case FieldDropDown:
+		// ClickUp sends the orderindex of the option, or its id.
+		if n, ok := jsonNumber(f.Value); ok {
+			for _, o := range f.TypeConfig.Options {
+				if float64(o.Orderindex) == n {
+					v.Options = []string{o.Id}
+				}
+			}
+		} else if id := jsonString(f.Value); id != "" {
+			v.Options = []string{id}
+		}
+		v.Set = len(v.Options) > 0
+	case FieldLabels:
+		json.Unmarshal(f.Value, &v.Options)
+		v.Set = len(v.Options) > 0
+	case FieldNumber, FieldCurrency, FieldEmoji:
+		v.Number, v.Set = jsonNumber(f.Value)
+	case FieldDate:
+		n, ok := jsonNumber(f.Value)
+		v.Date, v.Set = int64(n), ok
+	case FieldCheckbox:
+		v.Checked = string(f.Value) == "true" || jsonString(f.Value) == "true"
+	case FieldUsers:
+		json.Unmarshal(f.Value, &v.Users)
+		v.Set = len(v.Users) > 0
+	case FieldTasks:
+		json.Unmarshal(f.Value, &v.Tasks)
+		v.Set = len(v.Tasks) > 0
+	case FieldManualProgress, FieldAutomaticProgress:
+		var progress struct {
+			Current          json.RawMessage `json:"current"`
+			PercentCompleted json.RawMessage `json:"percent_completed"`
+			PercentComplete  json.RawMessage `json:"percent_complete"`
+		}
+		if json.Unmarshal(f.Value, &progress) != nil {
+			return CustomFieldValue{}
+		}
+		if n, ok := jsonNumber(progress.Current); ok && f.Type == FieldManualProgress {
+			v.Number = n
+		} else if n, ok := jsonNumber(progress.PercentCompleted); ok {
+			v.Number = n
+		} else {
+			v.Number, v.Set = jsonNumber(progress.PercentComplete)
+		}
+	case FieldLocation:
+		var location struct {
+			Address string `json:"formatted_address"`
+		}
+		json.Unmarshal(f.Value, &location)
+		v.Text, v.Set = location.Address, location.Address != ""
+	default:
+		if n, ok := jsonNumber(f.Value); ok {
+			v.Text = strconv.FormatFloat(n, 'f', -1, 64)
+		} else {
+			v.Text = jsonString(f.Value)
+		}
+		v.Set = v.Text != ""
+	}
+	return v
+}
+
+// jsonNumber decodes a number that ClickUp may send as a string.
+func jsonNumber(raw json.RawMessage) (float64, bool) {
+	var n float64
+	if json.Unmarshal(raw, &n) == nil {
+		return n, true
+	}
+	n, err := strconv.ParseFloat(jsonString(raw), 64)
+	return n, err == nil
+}
+
+func jsonString(raw json.RawMessage) string {
+	var s string
+	json.Unmarshal(raw, &s)
+	return s
+}
+
+// Checklist is a named list of items to tick on a task.
+type Checklist struct {
+	Id         string          `json:"id"`
+	TaskId     string          `json:"task_id"`
+	Name       string          `json:"name"`
+	Orderindex int             `json:"orderindex"`
+	Items      []ChecklistItem `json:"items"`
+}
+
+// ChecklistItem is an item of a checklist. Items can be nested under another item.
+type ChecklistItem struct {
+	Id         string          `json:"id"`
+	Name       string          `json:"name"`
+	Orderindex int             `json:"orderindex"`
+	Resolved   bool            `json:"resolved"`
+	Assignee   *User           `json:"assignee"`
+	Children   []ChecklistItem `json:"children,omitempty"`
+}
+
+// Dependency tells that the task TaskId waits on the task DependsOn: DependsOn blocks TaskId.
+// A task lists both the tasks it waits on and the ones it blocks.
+type Dependency struct {
+	TaskId    string `json:"task_id"`
+	DependsOn string `json:"depends_on"`
+	Type      int    `json:"type"`
+}
+
+// LinkedTask is a task linked to another one without any ordering between them.
+type LinkedTask struct {
+	TaskId string `json:"task_id"`
+	LinkId string `json:"link_id"`
+}
+
+// Attachment is a file attached to a task. Url is where its content is downloaded from.
+type Attachment struct {
+	Id        string      `json:"id"`
+	Title     string      `json:"title"` // the file name
+	Extension string      `json:"extension"`
+	Mimetype  string      `json:"mimetype"`
+	Size      int64       `json:"size"` // bytes
+	Date      json.Number `json:"date"` // unix milliseconds
+	Url       string      `json:"url"`
+	User      User        `json:"user"`
+}
+
+// TaskUpdate lists the changes to apply to a task. Nil fields are left untouched,
+// a priority or a date set to 0 is removed from the task.
+type TaskUpdate struct {
+	Name            *string
+	Description     *string // markdown
+	Status          *string
+	Priority        *int
+	DueDate         *int64 // unix milliseconds
+	StartDate       *int64 // unix milliseconds
+	TimeEstimate    *int64 // milliseconds
+	AddAssignees    []int
+	RemoveAssignees []int
+	AddTags         []string
+	RemoveTags      []string
+}
+
+// body returns the fields of the update sent with PUT /task/{id}. Tags have their own endpoints.
+func (u TaskUpdate) body() map[string]any {
+	body := map[string]any{}
+	if u.Name != nil {
+		body["name"] = *u.Name
+	}
+	if u.Description != nil {
+		body["markdown_description"] = *u.Description
+	}
+	if u.Status != nil {
+		body["status"] = *u.Status
+	}
+	clearable := func(key string, v *int64) {
+		if v == nil {
+			return
+		}
+		if *v == 0 {
+			body[key] = nil
+		} else {
+			body[key] = *v
+		}
+	}
+	if u.Priority != nil {
+		p := int64(*u.Priority)
+		clearable("priority", &p)
+	}
+	clearable("due_date", u.DueDate)
+	clearable("start_date", u.StartDate)
+	clearable("time_estimate", u.TimeEstimate)
+	if len(u.AddAssignees) > 0 || len(u.RemoveAssignees) > 0 {
+		body["assignees"] = map[string][]int{"add": u.AddAssignees, "rem": u.RemoveAssignees}
+	}
+	return body
+}
+
+// Priority levels go from 1 (urgent) to 4 (low), tasks without a priority have none.
+type Priority struct {
+	Id       string `json:"id"`
+	Priority string `json:"priority"` // "urgent", "high", "normal", "low"
+	Color    string `json:"color"`
+}
+
+// NewTask holds the fields of a task to create. Empty fields are left to the list defaults.
+type NewTask struct {
+	Name        string   `json:"name"`
+	Description string   `json:"markdown_description,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Assignees   []int    `json:"assignees,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Priority    int      `json:"priority,omitempty"` // 1 urgent, 2 high, 3 normal, 4 low
+	DueDate     int64    `json:"due_date,omitempty"` // unix milliseconds
 }
 
 // CommentText represents a part of a comment, with text and attributes.
+// Inline attributes (bold, code, link...) style the text of the part, block attributes
+// (list, code-block, header...) are set on the newline that ends a line and style the whole line.
 type CommentText struct {
-	Text       string                 `json:"text"`
-	Type       string                 `json:"type,omitempty"`
-	Bookmark   map[string]interface{} `json:"bookmark,omitempty"`
-	Attributes map[string]interface{} `json:"attributes"`
+	Text        string                 `json:"text"`
+	Type        string                 `json:"type,omitempty"`
+	Bookmark    map[string]interface{} `json:"bookmark,omitempty"`
+	User        *User                  `json:"user,omitempty"`         // type "tag", a mention
+	Emoticon    map[string]interface{} `json:"emoticon,omitempty"`     // type "emoticon"
+	TaskMention map[string]interface{} `json:"task_mention,omitempty"` // type "task_mention"
+	Attachment  map[string]interface{} `json:"attachment,omitempty"`   // type "attachment"
+	Image       map[string]interface{} `json:"image,omitempty"`        // type "image"
+	Attributes  map[string]interface{} `json:"attributes"`
 }
 
 // Comment represents a comment on a task.
@@ -62,14 +441,62 @@ type Comment struct {
 	Comment       []CommentText `json:"comment"`
 	CommentText   string        `json:"comment_text"`
 	User          User          `json:"user"`
-	Assignee      interface{}   `json:"assignee"`
+	Assignee      *User         `json:"assignee"`
 	GroupAssignee interface{}   `json:"group_assignee"`
-	Reactions     []interface{} `json:"reactions"`
+	Reactions     []Reaction    `json:"reactions"`
+	Resolved      bool          `json:"resolved"`
 	Date          string        `json:"date"`
 	ReplyCount    int           `json:"reply_count"`
+	Replies       []Comment     `json:"replies,omitempty"` // loaded with GetCommentReplies
+}
+
+// Reaction is an emoji left by a user on a comment, such as "thumbsup".
+type Reaction struct {
+	Reaction string `json:"reaction"`
+	Date     string `json:"date"`
+	User     User   `json:"user"`
+}
+
+// CommentUpdate lists the changes to apply to a comment. Nil fields are left untouched.
+type CommentUpdate struct {
+	Text     *string
+	Assignee *int
+	Resolved *bool
+}
+
+func (u CommentUpdate) body() map[string]any {
+	body := map[string]any{}
+	if u.Text != nil {
+		body["comment_text"] = *u.Text
+	}
+	if u.Assignee != nil {
+		body["assignee"] = *u.Assignee
+	}
+	if u.Resolved != nil {
+		body["resolved"] = *u.Resolved
+	}
+	return body
 }
 
 type Team struct {
 	Id   string `json:"id"`
 	Name string `json:"name"`
 }
+
+type Space struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Folder struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Lists []List `json:"lists"`
+}
+
+// View is a saved view of a team, space, folder or list, such as a board.
+type View struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"` // "board", "list", "calendar", ...
+}
@@ -0,0 +1,535 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mceck/clickup-tui/internal/shared"
+)
+
+// WriteKind is the change a queued write makes to the time entries.
+type WriteKind string
+
+const (
+	WriteTracking    WriteKind = "tracking"     // UpdateTracking
+	WriteCreateEntry WriteKind = "create_entry" // CreateTimeEntry
+	WriteUpdateEntry WriteKind = "update_entry" // UpdateTimeEntry
+	WriteDeleteEntry WriteKind = "delete_entry" // DeleteTimeEntry
+)
+
+// WriteState tells whether a queued write is waiting for the connection or for the user.
+type WriteState string
+
+const (
+	WritePending  WriteState = "pending"
+	WriteConflict WriteState = "conflict" // the data changed on ClickUp after the write was made
+	WriteFailed   WriteState = "failed"   // ClickUp rejected the write
+)
+
+// localEntryPrefix marks the ids of the entries that only exist in the queue.
+const localEntryPrefix = "local-"
+
+var errEntryQueued = errors.New("the entry is not on ClickUp yet, change the hours of the day instead")
+
+// QueuedWrite is a change to the time entries made while ClickUp could not be reached.
+// Until it is sent, the pending writes are applied on top of the entries read from the cache.
+type QueuedWrite struct {
+	Id       int        `json:"id"`
+	Kind     WriteKind  `json:"kind"`
+	State    WriteState `json:"state"`
+	TaskId   string     `json:"task_id"`
+	TaskName string     `json:"task_name"`
+	UserId   string     `json:"user_id,omitempty"`
+	// Tracking writes set the hours of a day. BaseMs is the time tracked that day when the
+	// hours were changed: ClickUp must still have it when the write is sent.
+	Day    string  `json:"day,omitempty"`
+	Hours  float64 `json:"hours,omitempty"`
+	BaseMs int     `json:"base_ms,omitempty"`
+	// Created entries.
+	Start    int64 `json:"start,omitempty"`
+	Duration int   `json:"duration,omitempty"`
+	// Updated and deleted entries, with the entry as it was shown when it was changed.
+	EntryId string     `json:"entry_id,omitempty"`
+	Entry   *TimeEntry `json:"entry,omitempty"`
+	Base    *TimeEntry `json:"base,omitempty"`
+	// Overwrite sends the write even though the data changed on ClickUp.
+	Overwrite bool   `json:"overwrite,omitempty"`
+	QueuedAt  int64  `json:"queued_at"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Date returns the day the write changes, as "2006-01-02".
+func (w QueuedWrite) Date() string {
+	switch {
+	case w.Day != "":
+		return w.Day
+	case w.Entry != nil:
+		return shared.ToDateString(w.Entry.Start)
+	case w.Base != nil:
+		return shared.ToDateString(w.Base.Start)
+	}
+	return time.UnixMilli(w.Start).Format("2006-01-02")
+}
+
+func (w QueuedWrite) day() time.Time {
+	day, _ := time.ParseInLocation("2006-01-02", w.Date(), time.Local)
+	return day
+}
+
+func (w QueuedWrite) localEntry(start int64, duration int) TimeEntry {
+	return TimeEntry{
+		Id:       localEntryPrefix + strconv.Itoa(w.Id),
+		Task:     map[string]interface{}{"id": w.TaskId, "name": w.TaskName},
+		Start:    strconv.FormatInt(start, 10),
+		Duration: strconv.Itoa(duration),
+		End:      strconv.FormatInt(start+int64(duration), 10),
+	}
+}
+
+// apply makes the change of the write to a list of entries.
+func (w QueuedWrite) apply(entries []TimeEntry) []TimeEntry {
+	switch w.Kind {
+	case WriteCreateEntry:
+		return append(entries, w.localEntry(w.Start, w.Duration))
+	case WriteUpdateEntry:
+		for i := range entries {
+			if entries[i].Id == w.EntryId {
+				entries[i] = *w.Entry
+			}
+		}
+	case WriteDeleteEntry:
+		return slices.DeleteFunc(entries, func(e TimeEntry) bool { return e.Id == w.EntryId })
+	case WriteTracking:
+		day := w.day()
+		plan := planTracking(FilterTimeEntries(entries, w.TaskId, day), day, w.Hours)
+		entries = slices.DeleteFunc(entries, func(e TimeEntry) bool {
+			return slices.ContainsFunc(plan.remove, func(r TimeEntry) bool { return r.Id == e.Id })
+		})
+		if plan.trim != nil {
+			for i := range entries {
+				if entries[i].Id == plan.trim.Id {
+					entries[i] = *plan.trim
+				}
+			}
+		}
+		if plan.add != nil {
+			entries = append(entries, w.localEntry(int64(shared.ToInt(plan.add.Start)), shared.ToInt(plan.add.Duration)))
+		}
+	}
+	return entries
+}
+
+type writeQueue struct {
+	NextId int           `json:"next_id"`
+	Writes []QueuedWrite `json:"writes"`
+}
+
+var (
+	queueMu  sync.Mutex
+	queue    writeQueue
+	replayMu sync.Mutex
+)
+
+// queueFile returns the path of the write queue of a profile.
+func queueFile(profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return configDir() + "/queue.json"
+	}
+	return configDir() + "/queue-" + profile + ".json"
+}
+
+// LoadLocalState drops the expired records of the store and loads the write queue of
+// the active profile. It runs at startup and again whenever the profile changes.
+func LoadLocalState() error {
+	return errors.Join(purgeStore(), loadQueue())
+}
+
+// loadQueue replaces the in-memory queue with the one saved for the active profile.
+// The queue stays empty when the file cannot be read.
+func loadQueue() error {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	queue = writeQueue{}
+	file, err := os.ReadFile(queueFile(GetConfig().Profile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err == nil {
+		err = json.Unmarshal(file, &queue)
+	}
+	if err != nil {
+		queue = writeQueue{}
+		return fmt.Errorf("reading the write queue: %w", err)
+	}
+	return nil
+}
+
+// saveQueue persists the queue. The caller holds queueMu.
+func saveQueue() error {
+	file, err := json.MarshalIndent(queue, "", " ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configDir(), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(queueFile(GetConfig().Profile), file)
+}
+
+// writeFileAtomic replaces a file through a temporary one, so that a crash never leaves it half written.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func hasPendingWrites() bool {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	return slices.ContainsFunc(queue.Writes, func(w QueuedWrite) bool { return w.State == WritePending })
+}
+
+// withQueuedWrites applies the pending writes to the entries read from ClickUp or the cache.
+func withQueuedWrites(entries []TimeEntry) []TimeEntry {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	if !slices.ContainsFunc(queue.Writes, func(w QueuedWrite) bool { return w.State == WritePending }) {
+		return entries
+	}
+	entries = slices.Clone(entries)
+	for _, w := range queue.Writes {
+		if w.State == WritePending {
+			entries = w.apply(entries)
+		}
+	}
+	return entries
+}
+
+// IsOffline reports whether a request failed because ClickUp could not be reached,
+// rather than because ClickUp answered with an error.
+func IsOffline(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && !IsCanceled(err)
+}
+
+// Offline reports whether the last request failed to reach ClickUp.
+func (c *ClickupClient) Offline() bool {
+	return c.offline.Load()
+}
+
+// queueing reports whether a write must go to the queue: ClickUp cannot be reached,
+// or earlier writes are still waiting and the new one has to be sent after them.
+func (c *ClickupClient) queueing() bool {
+	return c.QueueWrites && (c.offline.Load() || hasPendingWrites())
+}
+
+// queueWrite adds a write to the queue and persists it. A tracking write on the same
+// day and task as the last write of the queue replaces it, keeping its base.
+func queueWrite(w QueuedWrite) error {
+	if w.TaskName == "" {
+		w.TaskName = cachedTaskName(w.TaskId)
+	}
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	if n := len(queue.Writes); n > 0 && w.Kind == WriteTracking {
+		last := &queue.Writes[n-1]
+		if last.Kind == WriteTracking && last.State == WritePending && last.TaskId == w.TaskId && last.Day == w.Day {
+			last.Hours = w.Hours
+			return saveQueue()
+		}
+	}
+	queue.NextId++
+	w.Id = queue.NextId
+	w.State = WritePending
+	w.QueuedAt = time.Now().UnixMilli()
+	queue.Writes = append(queue.Writes, w)
+	return saveQueue()
+}
+
+// cachedTaskName looks for the name of a task in the cached tasks and time entries.
+func cachedTaskName(taskId string) string {
+	for _, kind := range []storeKind{kindTask, kindListTask} {
+		var task Task
+		if found, _ := cached(kind, taskId, &task); found {
+			return task.Name
+		}
+	}
+	name := ""
+	eachCached(kindTimeEntries, func(_ string, r record) {
+		var entries []TimeEntry
+		_ = json.Unmarshal(r.Data, &entries)
+		for _, entry := range entries {
+			if entry.TaskId() == taskId && entry.TaskName() != "" && name == "" {
+				name = entry.TaskName()
+			}
+		}
+	})
+	if name != "" {
+		return name
+	}
+	return taskId
+}
+
+// shownEntry returns an entry as the user sees it, with the pending writes applied.
+func shownEntry(entryId string) *TimeEntry {
+	var shown *TimeEntry
+	eachCached(kindTimeEntries, func(_ string, r record) {
+		var entries []TimeEntry
+		_ = json.Unmarshal(r.Data, &entries)
+		for _, entry := range withQueuedWrites(entries) {
+			if entry.Id == entryId {
+				shown = &entry
+			}
+		}
+	})
+	return shown
+}
+
+// QueuedWrites returns the writes waiting in the queue, in the order they were made.
+func (c *ClickupClient) QueuedWrites() []QueuedWrite {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	return slices.Clone(queue.Writes)
+}
+
+// RetryWrite puts a conflicting or failed write back in the queue. With overwrite, it is
+// sent even though the data changed on ClickUp.
+func (c *ClickupClient) RetryWrite(id int, overwrite bool) error {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	idx := slices.IndexFunc(queue.Writes, func(w QueuedWrite) bool { return w.Id == id })
+	if idx < 0 {
+		return fmt.Errorf("write %d is not in the queue", id)
+	}
+	queue.Writes[idx].State = WritePending
+	queue.Writes[idx].Overwrite = overwrite
+	queue.Writes[idx].Error = ""
+	return saveQueue()
+}
+
+// DiscardWrite removes a write from the queue, the change it made is lost.
+func (c *ClickupClient) DiscardWrite(id int) error {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	idx := slices.IndexFunc(queue.Writes, func(w QueuedWrite) bool { return w.Id == id })
+	if idx < 0 {
+		return fmt.Errorf("write %d is not in the queue", id)
+	}
+	queue.Writes = slices.Delete(queue.Writes, idx, idx+1)
+	return saveQueue()
+}
+
+// ReplayWrites sends the pending writes to ClickUp in the order they were made and returns
+// how many were sent. It stops at the first write that cannot reach ClickUp. Conflicts and
+// writes rejected by ClickUp are set aside in the queue for the user to review.
+func (c *ClickupClient) ReplayWrites(ctx context.Context) (int, error) {
+	replayMu.Lock()
+	defer replayMu.Unlock()
+	sent := 0
+	defer func() {
+		if sent > 0 && !hasPendingWrites() {
+			ClearTimeentriesCache()
+		}
+	}()
+	for {
+		w, ok := nextPendingWrite()
+		if !ok {
+			return sent, nil
+		}
+		err := c.sendWrite(ctx, w)
+		var conflict writeConflict
+		switch {
+		case err == nil:
+			if err := finishWrite(w.Id, "", ""); err != nil {
+				return sent, err
+			}
+			sent++
+		case IsOffline(err) || IsCanceled(err):
+			return sent, err
+		case errors.As(err, &conflict):
+			if err := finishWrite(w.Id, WriteConflict, err.Error()); err != nil {
+				return sent, err
+			}
+		default:
+			if err := finishWrite(w.Id, WriteFailed, err.Error()); err != nil {
+				return sent, err
+			}
+		}
+	}
+}
+
+func nextPendingWrite() (QueuedWrite, bool) {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	for _, w := range queue.Writes {
+		if w.State == WritePending {
+			return w, true
+		}
+	}
+	return QueuedWrite{}, false
+}
+
+// finishWrite removes a sent write from the queue, or sets it aside with the reason.
+func finishWrite(id int, state WriteState, reason string) error {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	idx := slices.IndexFunc(queue.Writes, func(w QueuedWrite) bool { return w.Id == id })
+	if idx < 0 {
+		return nil
+	}
+	if state == "" {
+		queue.Writes = slices.Delete(queue.Writes, idx, idx+1)
+	} else {
+		queue.Writes[idx].State = state
+		queue.Writes[idx].Error = reason
+	}
+	return saveQueue()
+}
+
+// rebaseWrite sets the time tracked on ClickUp that a tracking write expects.
+func rebaseWrite(id int, baseMs int) error {
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	idx := slices.IndexFunc(queue.Writes, func(w QueuedWrite) bool { return w.Id == id })
+	if idx < 0 {
+		return nil
+	}
+	queue.Writes[idx].BaseMs = baseMs
+	return saveQueue()
+}
+
+// writeConflict explains why a queued write no longer applies to the data on ClickUp.
+type writeConflict string
+
+func (e writeConflict) Error() string {
+	return string(e)
+}
+
+// sendWrite sends a queued write, after checking that the data it changes on ClickUp
+// is still the one the user saw.
+func (c *ClickupClient) sendWrite(ctx context.Context, w QueuedWrite) error {
+	switch w.Kind {
+	case WriteTracking:
+		day := w.day()
+		from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		all, err := c.GetTimeEntriesBetween(ctx, w.UserId, from, from.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		entries := FilterTimeEntries(all, w.TaskId, day)
+		current := trackedMs(entries)
+		if current == hoursToMs(w.Hours) {
+			return nil
+		}
+		if current != w.BaseMs && !w.Overwrite {
+			return writeConflict(fmt.Sprintf("ClickUp has %s tracked on the day, there were %s when the hours were changed", formatMs(current), formatMs(w.BaseMs)))
+		}
+		tracked, err := c.applyTracking(ctx, w.TaskId, day, entries, w.Hours)
+		if err != nil && tracked != current {
+			// The next attempt starts from the part of the change that reached ClickUp.
+			if err := rebaseWrite(w.Id, tracked); err != nil {
+				return err
+			}
+		}
+		return err
+	case WriteCreateEntry:
+		// The entry may already be on ClickUp when the answer to the first attempt was lost.
+		start := time.UnixMilli(w.Start)
+		from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		all, err := c.GetTimeEntriesBetween(ctx, w.UserId, from, from.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		for _, entry := range FilterTimeEntries(all, w.TaskId, start) {
+			if int64(shared.ToInt(entry.Start)) == w.Start && shared.ToInt(entry.Duration) == w.Duration {
+				return nil
+			}
+		}
+		return c.createTimeEntry(ctx, w.TaskId, start, w.Duration)
+	case WriteUpdateEntry:
+		remote, err := c.getTimeEntry(ctx, w.EntryId)
+		if err != nil {
+			return err
+		}
+		switch {
+		case remote == nil && !w.Overwrite:
+			return writeConflict("the entry was deleted on ClickUp")
+		case remote != nil && sameEntry(*remote, *w.Entry):
+			return nil
+		case remote != nil && w.Base != nil && !sameEntry(*remote, *w.Base) && !w.Overwrite:
+			return writeConflict("the entry was changed on ClickUp")
+		}
+		return c.updateTimeEntry(ctx, *w.Entry)
+	case WriteDeleteEntry:
+		remote, err := c.getTimeEntry(ctx, w.EntryId)
+		if err != nil || remote == nil {
+			return err
+		}
+		if w.Base != nil && !sameEntry(*remote, *w.Base) && !w.Overwrite {
+			return writeConflict("the entry was changed on ClickUp")
+		}
+		return c.deleteTimeEntry(ctx, w.TaskId, w.EntryId)
+	}
+	return fmt.Errorf("unknown write %q", w.Kind)
+}
+
+// getTimeEntry fetches a time entry, or returns nil when it does not exist anymore.
+func (c *ClickupClient) getTimeEntry(ctx context.Context, entryId string) (*TimeEntry, error) {
+	var data runningTimerResponse
+	path := fmt.Sprintf("/api/v2/team/%s/time_entries/%s", c.TeamID, entryId)
+	err := c.do(ctx, http.MethodGet, path, nil, &data)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data.Data, nil
+}
+
+func sameEntry(a, b TimeEntry) bool {
+	return a.Start == b.Start && a.Duration == b.Duration && a.Description == b.Description && a.Billable == b.Billable
+}
+
+func trackedMs(entries []TimeEntry) int {
+	total := 0
+	for _, entry := range entries {
+		total += shared.ToInt(entry.Duration)
+	}
+	return total
+}
+
+func hoursToMs(hours float64) int {
+	return int(math.Round(hours * 60 * 60 * 1000))
+}
+
+func formatMs(ms int) string {
+	return strings.TrimSuffix(strconv.FormatFloat(float64(ms)/3600000, 'f', 2, 64), ".00") + "h"
+}
@@ -0,0 +1,249 @@
+package clients_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/fakeclickup"
+)
+
+func offlineClient(t *testing.T, srv *fakeclickup.Server) *clients.ClickupClient {
+	t.Helper()
+	client := srv.Client()
+	client.QueueWrites = true
+	// Fill the cache before the connection drops.
+	if _, err := client.GetTimesheetsEntries(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetOffline(true)
+	return client
+}
+
+func TestOfflineTrackingIsQueuedAndReplayed(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks:       []clients.Task{{Id: "t1", Name: "Task"}},
+		TimeEntries: []clients.TimeEntry{trackedEntry("e1", day.Add(9*time.Hour), time.Hour, "Review")},
+	})
+	client := offlineClient(t, srv)
+	ctx := context.Background()
+
+	if err := client.UpdateTracking(ctx, "1", "t1", day, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UpdateTracking(ctx, "1", "t1", day, 2.5); err != nil {
+		t.Fatal(err)
+	}
+	if !client.Offline() {
+		t.Error("client does not know it is offline")
+	}
+	entries, err := client.GetTimesheetsEntries(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := clients.FilterTimeEntries(entries, "t1", day); len(got) != 2 || got[1].Duration != "5400000" {
+		t.Fatalf("queued change not shown in the entries: %+v", got)
+	}
+	if len(srv.TimeEntries()) != 1 {
+		t.Fatalf("offline change reached the server: %+v", srv.TimeEntries())
+	}
+
+	// The queue survives a restart, the edits of the same cell are merged.
+	client = srv.Client()
+	writes := client.QueuedWrites()
+	if len(writes) != 1 || writes[0].Kind != clients.WriteTracking || writes[0].Hours != 2.5 || writes[0].TaskName != "Task" {
+		t.Fatalf("unexpected queue %+v", writes)
+	}
+
+	srv.SetOffline(false)
+	sent, err := client.ReplayWrites(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("replay sent %d writes: %v", sent, err)
+	}
+	if got := srv.TimeEntries(); len(got) != 2 || got[1].Duration != "5400000" || got[1].Start != got[0].End {
+		t.Errorf("unexpected entries after replay %+v", got)
+	}
+	if writes := client.QueuedWrites(); len(writes) != 0 {
+		t.Errorf("queue not emptied: %+v", writes)
+	}
+}
+
+func TestReplayDetectsConflicts(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task"}},
+		TimeEntries: []clients.TimeEntry{
+			trackedEntry("e1", day.Add(9*time.Hour), time.Hour, "Review"),
+			trackedEntry("e2", day.AddDate(0, 0, 1).Add(9*time.Hour), time.Hour, "Planning"),
+			trackedEntry("e3", day.AddDate(0, 0, 2).Add(9*time.Hour), time.Hour, "Retro"),
+		},
+	})
+	client := offlineClient(t, srv)
+	ctx := context.Background()
+
+	if err := client.UpdateTracking(ctx, "1", "t1", day, 3); err != nil {
+		t.Fatal(err)
+	}
+	edited := trackedEntry("e2", day.AddDate(0, 0, 1).Add(9*time.Hour), time.Hour, "Sprint planning")
+	if err := client.UpdateTimeEntry(ctx, edited); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteTimeEntry(ctx, "t1", "e3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Meanwhile, on ClickUp: time is added on the first day and the second entry is changed.
+	srv.PutTimeEntry(trackedEntry("e4", day.Add(14*time.Hour), time.Hour, "Call"))
+	srv.PutTimeEntry(trackedEntry("e2", day.AddDate(0, 0, 1).Add(9*time.Hour), 2*time.Hour, "Planning"))
+	srv.SetOffline(false)
+
+	sent, err := client.ReplayWrites(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("replay sent %d writes: %v", sent, err)
+	}
+	writes := client.QueuedWrites()
+	if len(writes) != 2 || writes[0].State != clients.WriteConflict || writes[1].State != clients.WriteConflict {
+		t.Fatalf("expected two conflicts, got %+v", writes)
+	}
+	if got := len(srv.TimeEntries()); got != 3 {
+		t.Errorf("the deletion was not sent: %d entries", got)
+	}
+
+	if err := client.RetryWrite(writes[0].Id, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DiscardWrite(writes[1].Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ReplayWrites(ctx); err != nil {
+		t.Fatal(err)
+	}
+	entries := clients.FilterTimeEntries(srv.TimeEntries(), "t1", day)
+	if len(entries) != 3 || entries[2].Duration != "3600000" {
+		t.Errorf("overwrite did not bring the day to 3h: %+v", entries)
+	}
+	if len(client.QueuedWrites()) != 0 {
+		t.Errorf("queue not emptied: %+v", client.QueuedWrites())
+	}
+}
+
+func TestOfflineReadsUseTheLastFetchedData(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task"}},
+		Views: map[string][]string{"v1": {"t1"}},
+	})
+	client := srv.Client()
+	ctx := context.Background()
+	if _, err := client.GetViewTasks(ctx, "v1"); err != nil {
+		t.Fatal(err)
+	}
+	clients.ClearViewTasksCache()
+	srv.SetOffline(true)
+
+	tasks, err := client.GetViewTasks(ctx, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].Id != "t1" {
+		t.Errorf("unexpected tasks %+v", tasks)
+	}
+	if _, err := client.GetTaskComments(ctx, "t1"); !clients.IsOffline(err) {
+		t.Errorf("expected an offline error for data never fetched, got %v", err)
+	}
+}
+
+func TestLoadLocalStateReportsAnUnreadableQueue(t *testing.T) {
+	srv := newServer(t, fakeclickup.Fixtures{Tasks: []clients.Task{{Id: "t1", Name: "Task"}}})
+	dir := os.ExpandEnv("$HOME/.config/clickup-tui")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "queue.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := clients.LoadLocalState(); err == nil {
+		t.Fatal("unreadable queue accepted")
+	}
+	if writes := srv.Client().QueuedWrites(); len(writes) != 0 {
+		t.Errorf("queue = %v, want it empty", writes)
+	}
+}
+
+func TestReplayedCreationIsNotDuplicated(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newServer(t, fakeclickup.Fixtures{Tasks: []clients.Task{{Id: "t1", Name: "Task"}}})
+	client := offlineClient(t, srv)
+	ctx := context.Background()
+
+	if err := client.CreateTimeEntry(ctx, "t1", day.Add(9*time.Hour), 3600000, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateTimeEntry(ctx, "t1", day.Add(14*time.Hour), 1800000, "1"); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetOffline(false)
+	// The first creation reached ClickUp, but its answer was lost.
+	if err := srv.Client().CreateTimeEntry(ctx, "t1", day.Add(9*time.Hour), 3600000, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := client.ReplayWrites(ctx)
+	if err != nil || sent != 2 {
+		t.Fatalf("replay sent %d writes: %v", sent, err)
+	}
+	if got := srv.TimeEntries(); len(got) != 2 || got[0].Duration != "3600000" || got[1].Duration != "1800000" {
+		t.Errorf("unexpected entries after replay %+v", got)
+	}
+}
+
+func TestTrackingInterruptedHalfwayIsQueuedFromWhatWasApplied(t *testing.T) {
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	srv := newServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Task"}},
+		TimeEntries: []clients.TimeEntry{
+			trackedEntry("e1", day.Add(9*time.Hour), 2*time.Hour, "Morning"),
+			trackedEntry("e2", day.Add(14*time.Hour), time.Hour, "Afternoon"),
+			trackedEntry("e3", day.Add(16*time.Hour), 30*time.Minute, "Evening"),
+		},
+	})
+	client := srv.Client()
+	client.QueueWrites = true
+	ctx := context.Background()
+	if _, err := client.GetTimesheetsEntries(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// e3 is deleted, then the connection drops while deleting e2, retry included.
+	srv.FailNext("DELETE /api/v2/task/t1/time/e2", 0, 2)
+	if err := client.UpdateTracking(ctx, "1", "t1", day, 1); err != nil {
+		t.Fatal(err)
+	}
+	writes := client.QueuedWrites()
+	if len(writes) != 1 || writes[0].BaseMs != 3*3600000 {
+		t.Fatalf("unexpected queue %+v", writes)
+	}
+	if got := srv.TimeEntries(); len(got) != 2 {
+		t.Fatalf("unexpected entries on ClickUp %+v", got)
+	}
+
+	// The replay is interrupted too, after deleting e2: the write is rebased again.
+	srv.FailNext("PUT /api/v2/team/"+fakeclickup.DefaultTeam+"/time_entries/e1", 0, 2)
+	if sent, err := client.ReplayWrites(ctx); !clients.IsOffline(err) || sent != 0 {
+		t.Fatalf("replay sent %d writes: %v", sent, err)
+	}
+	if writes := client.QueuedWrites(); len(writes) != 1 || writes[0].BaseMs != 2*3600000 || writes[0].State != clients.WritePending {
+		t.Fatalf("unexpected queue %+v", writes)
+	}
+
+	sent, err := client.ReplayWrites(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("replay sent %d writes: %v", sent, err)
+	}
+	if got := srv.TimeEntries(); len(got) != 1 || got[0].Id != "e1" || got[0].Duration != "3600000" {
+		t.Errorf("unexpected entries after replay %+v", got)
+	}
+}
@@ -0,0 +1,538 @@
+package clients
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// The store is a bbolt database in the config directory. Records are scoped by profile,
+// team and kind, and keyed by the id of what they hold (a task, a view, a user):
+//
+//	meta                          version of the schema
+//	profile:<name>/team:<id>/<kind>/<key>  record
+//
+// The lists of tasks hold the ids of their tasks, the tasks themselves are stored once
+// under list_tasks, so that a change to a task rewrites a single record.
+//
+// The database is opened once and shared by the transactions of the process, but it is
+// closed when idle, because bbolt locks the file: the command line run by a cron job must
+// be able to use it while the interface is running.
+
+// storeVersion is the version of the layout of the database, see storeMigrations.
+const storeVersion = 2
+
+// storeRetention is how long a record is kept after it was stored. Expired records are
+// kept until then, to be shown when ClickUp cannot be reached.
+const storeRetention = 30 * 24 * time.Hour
+
+type storeKind string
+
+const (
+	kindTask           storeKind = "tasks"           // by task id
+	kindComments       storeKind = "comments"        // by task id
+	kindViewTasks      storeKind = "view_tasks"      // ids of the tasks, by view id
+	kindTimesheetTasks storeKind = "timesheet_tasks" // ids of the tasks, by filter
+	kindListTask       storeKind = "list_tasks"      // the tasks of the lists, by task id
+	kindTimeEntries    storeKind = "time_entries"    // by user id
+	kindTimeHistory    storeKind = "time_history"    // by user id and first recent day
+	kindSync           storeKind = "sync"            // by kind and key of a list of tasks
+)
+
+var storeKinds = []storeKind{kindTask, kindComments, kindViewTasks, kindTimesheetTasks, kindListTask, kindTimeEntries, kindTimeHistory, kindSync}
+
+// fullSyncInterval is how often every task of a list is fetched again, to drop the
+// deleted ones. In between only the tasks updated since the last sync are fetched.
+const fullSyncInterval = time.Hour
+
+// storeTTL is how long a record is used before it is fetched again. The tasks of the lists
+// are as fresh as their lists.
+var storeTTL = map[storeKind]time.Duration{
+	kindTask:           time.Hour,
+	kindComments:       10 * time.Minute,
+	kindViewTasks:      15 * time.Minute,
+	kindTimesheetTasks: 15 * time.Minute,
+	kindTimeEntries:    time.Hour,
+	kindTimeHistory:    24 * time.Hour,
+}
+
+var metaBucket = []byte("meta")
+
+// record is the value stored for a key, with the times it was stored and expires at in
+// unix milliseconds. An expired record has ExpiresAt 0 or in the past.
+type record struct {
+	StoredAt  int64           `json:"stored_at"`
+	ExpiresAt int64           `json:"expires_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (r record) fresh() bool {
+	return r.ExpiresAt > time.Now().UnixMilli()
+}
+
+// storeScope is the part of the store that belongs to a profile and a team.
+type storeScope struct {
+	profile string
+	team    string
+}
+
+// scope is the scope of the active profile and of its team, set with the config.
+var scope *storeScope
+
+// activeScope is the scope of the active profile and of its team.
+func activeScope() storeScope {
+	if scope == nil {
+		GetConfig()
+	}
+	return *scope
+}
+
+func profileBucket(profile string) []byte {
+	return []byte("profile:" + profile)
+}
+
+// bucket returns the bucket of a kind in the scope. Without create, it is nil when
+// nothing was stored there yet.
+func (s storeScope) bucket(tx *bolt.Tx, kind storeKind, create bool) (*bolt.Bucket, error) {
+	path := [][]byte{profileBucket(s.profile), []byte("team:" + s.team), []byte(kind)}
+	if !create {
+		b := tx.Bucket(path[0])
+		for _, name := range path[1:] {
+			if b == nil {
+				return nil, nil
+			}
+			b = b.Bucket(name)
+		}
+		return b, nil
+	}
+	b, err := tx.CreateBucketIfNotExists(path[0])
+	for _, name := range path[1:] {
+		if err != nil {
+			return nil, err
+		}
+		b, err = b.CreateBucketIfNotExists(name)
+	}
+	return b, err
+}
+
+func storeFile() string {
+	return configDir() + "/store.db"
+}
+
+// storeIdle is how long the database stays open after the last transaction.
+const storeIdle = time.Second
+
+var (
+	// storeMu serialises the transactions of the process, the file lock does it across processes.
+	storeMu sync.Mutex
+	// storeDB is the open database, nil when closed. storeIdleTimer closes it when idle.
+	storeDB        *bolt.DB
+	storeIdleTimer *time.Timer
+)
+
+// withStore runs a transaction on the database, opening and migrating it first if needed.
+func withStore(writable bool, fn func(tx *bolt.Tx) error) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	if storeIdleTimer == nil {
+		storeIdleTimer = time.AfterFunc(storeIdle, func() { _ = CloseStore() })
+	} else {
+		storeIdleTimer.Reset(storeIdle)
+	}
+	if writable {
+		return db.Update(fn)
+	}
+	return db.View(fn)
+}
+
+// openStore returns the open database, opening it when it is closed or when the config
+// directory changed. The caller holds storeMu.
+func openStore() (*bolt.DB, error) {
+	if storeDB != nil && storeDB.Path() == storeFile() {
+		return storeDB, nil
+	}
+	if err := closeStore(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(configDir(), 0755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(storeFile(), 0644, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening the local store: %w", err)
+	}
+	if err := migrateStore(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	storeDB = db
+	return db, nil
+}
+
+// CloseStore closes the database and releases its lock. It is opened again by the next
+// transaction.
+func CloseStore() error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return closeStore()
+}
+
+func closeStore() error {
+	if storeDB == nil {
+		return nil
+	}
+	err := storeDB.Close()
+	storeDB = nil
+	return err
+}
+
+// storeMigrations bring the database from a version to the next one: the migration at
+// index i upgrades version i to i+1. Cleanups to do once the migration is committed,
+// such as removing the files it imported, are returned as a function.
+var storeMigrations = []func(tx *bolt.Tx) (func(), error){
+	importCacheFiles,
+	indexListTasks,
+}
+
+func migrateStore(db *bolt.DB) error {
+	version := 0
+	err := db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(metaBucket); b != nil {
+			version, _ = strconv.Atoi(string(b.Get([]byte("version"))))
+		}
+		return nil
+	})
+	if err != nil || version >= storeVersion {
+		return err
+	}
+	var cleanups []func()
+	err = db.Update(func(tx *bolt.Tx) error {
+		for v := version; v < storeVersion; v++ {
+			cleanup, err := storeMigrations[v](tx)
+			if err != nil {
+				return fmt.Errorf("migrating the local store to version %d: %w", v+1, err)
+			}
+			if cleanup != nil {
+				cleanups = append(cleanups, cleanup)
+			}
+		}
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		return meta.Put([]byte("version"), []byte(strconv.Itoa(storeVersion)))
+	})
+	if err != nil {
+		return err
+	}
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+	return nil
+}
+
+// legacyCache is the layout of the cache files used before the store, one per profile.
+type legacyCache struct {
+	TimesheetTasks   []Task               `json:"timesheet_tasks"`
+	TimeEntries      []TimeEntry          `json:"time_entries"`
+	ViewTasks        []Task               `json:"view_tasks"`
+	TaskByID         map[string]Task      `json:"task_by_id"`
+	CommentsByTaskID map[string][]Comment `json:"comments_by_task_id"`
+}
+
+// legacyCacheFile returns the path of the cache file of a profile.
+func legacyCacheFile(profile string) string {
+	if profile == DefaultProfile {
+		return configDir() + "/cache.json"
+	}
+	return configDir() + "/cache-" + profile + ".json"
+}
+
+// importCacheFiles moves the cache files of the profiles to the store. The lists did not
+// say which view, filter or user they were fetched for, they are stored under the ones of
+// the profile config. Everything is imported as expired: it is only shown offline.
+func importCacheFiles(tx *bolt.Tx) (func(), error) {
+	f := readConfigFile()
+	var imported []string
+	for _, name := range Profiles() {
+		file, err := os.ReadFile(legacyCacheFile(name))
+		if err != nil {
+			continue
+		}
+		imported = append(imported, legacyCacheFile(name))
+		var legacy legacyCache
+		if json.Unmarshal(file, &legacy) != nil {
+			continue
+		}
+		c, _ := f.profile(name)
+		filter := c.TimesheetFilter
+		if filter == "" {
+			filter = DefaultTimesheetFilter
+		}
+		scope := storeScope{profile: name, team: c.TeamId}
+		put := func(kind storeKind, key string, v any) error {
+			if key == "" {
+				return nil
+			}
+			return scope.put(tx, kind, key, v, 0)
+		}
+		if legacy.ViewTasks != nil {
+			if err := put(kindViewTasks, c.ViewId, legacy.ViewTasks); err != nil {
+				return nil, err
+			}
+		}
+		if legacy.TimesheetTasks != nil {
+			if err := put(kindTimesheetTasks, filter, legacy.TimesheetTasks); err != nil {
+				return nil, err
+			}
+		}
+		if legacy.TimeEntries != nil {
+			if err := put(kindTimeEntries, c.UserId, legacy.TimeEntries); err != nil {
+				return nil, err
+			}
+		}
+		for id, task := range legacy.TaskByID {
+			if err := put(kindTask, id, task); err != nil {
+				return nil, err
+			}
+		}
+		for id, comments := range legacy.CommentsByTaskID {
+			if err := put(kindComments, id, comments); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return func() {
+		for _, file := range imported {
+			_ = os.Remove(file)
+		}
+	}, nil
+}
+
+// indexListTasks stores the tasks of the lists apart, by id, and leaves their ids in the lists.
+func indexListTasks(tx *bolt.Tx) (func(), error) {
+	var scopes []storeScope
+	err := tx.ForEach(func(name []byte, profile *bolt.Bucket) error {
+		if string(name) == string(metaBucket) {
+			return nil
+		}
+		return profile.ForEachBucket(func(team []byte) error {
+			scopes = append(scopes, storeScope{
+				profile: strings.TrimPrefix(string(name), "profile:"),
+				team:    strings.TrimPrefix(string(team), "team:"),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		for _, kind := range []storeKind{kindViewTasks, kindTimesheetTasks} {
+			b, err := scope.bucket(tx, kind, false)
+			if b == nil || err != nil {
+				continue
+			}
+			lists := map[string]record{}
+			_ = b.ForEach(func(k, value []byte) error {
+				var r record
+				if json.Unmarshal(value, &r) == nil {
+					lists[string(k)] = r
+				}
+				return nil
+			})
+			for key, r := range lists {
+				var tasks []Task
+				if json.Unmarshal(r.Data, &tasks) != nil {
+					continue
+				}
+				for _, task := range tasks {
+					if err := scope.put(tx, kindListTask, task.Id, task, 0); err != nil {
+						return nil, err
+					}
+				}
+				if err := scope.put(tx, kind, key, taskIds(tasks), r.ExpiresAt); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// put stores a value under a key, fresh until expiresAt (unix milliseconds).
+func (s storeScope) put(tx *bolt.Tx, kind storeKind, key string, v any, expiresAt int64) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(record{StoredAt: time.Now().UnixMilli(), ExpiresAt: expiresAt, Data: data})
+	if err != nil {
+		return err
+	}
+	b, err := s.bucket(tx, kind, true)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), value)
+}
+
+// cached reads the record of a key of the active scope into v. fresh is false when the
+// record is expired: it can be shown when ClickUp cannot be reached, not otherwise.
+func cached(kind storeKind, key string, v any) (found bool, fresh bool) {
+	err := withStore(false, func(tx *bolt.Tx) error {
+		b, err := activeScope().bucket(tx, kind, false)
+		if b == nil || err != nil {
+			return err
+		}
+		value := b.Get([]byte(key))
+		if value == nil {
+			return nil
+		}
+		var r record
+		if err := json.Unmarshal(value, &r); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(r.Data, v); err != nil {
+			return err
+		}
+		found, fresh = true, r.fresh()
+		return nil
+	})
+	if err != nil {
+		return false, false
+	}
+	return found, fresh
+}
+
+// putCached stores a value fetched from ClickUp under a key of the active scope.
+func putCached(kind storeKind, key string, v any) error {
+	return putCachedUntil(kind, key, v, time.Now().Add(storeTTL[kind]).UnixMilli())
+}
+
+// putCachedUntil stores a value that expires at the given time, in unix milliseconds.
+func putCachedUntil(kind storeKind, key string, v any, expiresAt int64) error {
+	return withStore(true, func(tx *bolt.Tx) error {
+		return activeScope().put(tx, kind, key, v, expiresAt)
+	})
+}
+
+// eachCached calls fn with the records of a kind of the active scope, expired or not.
+func eachCached(kind storeKind, fn func(key string, r record)) error {
+	return withStore(false, func(tx *bolt.Tx) error {
+		b, err := activeScope().bucket(tx, kind, false)
+		if b == nil || err != nil {
+			return err
+		}
+		return b.ForEach(func(k, value []byte) error {
+			var r record
+			if json.Unmarshal(value, &r) == nil {
+				fn(string(k), r)
+			}
+			return nil
+		})
+	})
+}
+
+// updateCached calls fn with the records of a kind of the active scope. fn changes a
+// record in place and reports whether it did, to have it saved.
+func updateCached(kind storeKind, fn func(key string, r *record) (bool, error)) error {
+	return withStore(true, func(tx *bolt.Tx) error {
+		b, err := activeScope().bucket(tx, kind, false)
+		if b == nil || err != nil {
+			return err
+		}
+		updates := map[string]record{}
+		err = b.ForEach(func(k, value []byte) error {
+			var r record
+			if json.Unmarshal(value, &r) != nil {
+				return nil
+			}
+			changed, err := fn(string(k), &r)
+			if changed {
+				updates[string(k)] = r
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		for k, r := range updates {
+			value, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(k), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// expireCached marks records of the active scope as expired, all the ones of the kind
+// when no key is given. They are kept to be shown when ClickUp cannot be reached.
+func expireCached(kind storeKind, keys ...string) error {
+	return updateCached(kind, func(key string, r *record) (bool, error) {
+		if len(keys) > 0 && !slices.Contains(keys, key) {
+			return false, nil
+		}
+		r.ExpiresAt = 0
+		return true, nil
+	})
+}
+
+// purgeStore removes the records stored longer ago than storeRetention, in every scope.
+func purgeStore() error {
+	limit := time.Now().Add(-storeRetention).UnixMilli()
+	return withStore(true, func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, profile *bolt.Bucket) error {
+			if string(name) == string(metaBucket) {
+				return nil
+			}
+			return profile.ForEachBucket(func(team []byte) error {
+				teamBucket := profile.Bucket(team)
+				return teamBucket.ForEachBucket(func(kind []byte) error {
+					b := teamBucket.Bucket(kind)
+					var old [][]byte
+					_ = b.ForEach(func(k, value []byte) error {
+						var r record
+						if json.Unmarshal(value, &r) != nil || r.StoredAt < limit {
+							old = append(old, k)
+						}
+						return nil
+					})
+					for _, k := range old {
+						if err := b.Delete(k); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			})
+		})
+	})
+}
+
+// deleteStoredProfile removes everything stored for a profile.
+func deleteStoredProfile(name string) error {
+	return withStore(true, func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket(profileBucket(name))
+		if errors.Is(err, bolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+}
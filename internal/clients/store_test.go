@@ -0,0 +1,153 @@
+package clients
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStoreExpiresRecordsByKind(t *testing.T) {
+	newConfigHome(t, `{"team_id": "1"}`)
+	if err := putCached(kindTask, "t1", Task{Id: "t1", Name: "Task"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := putCached(kindComments, "t1", []Comment{{Id: "c1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := forgetComment("c1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var comments []Comment
+	if found, fresh := cached(kindComments, "t1", &comments); !found || fresh || len(comments) != 1 {
+		t.Errorf("comments: found %v, fresh %v, %+v", found, fresh, comments)
+	}
+	var task Task
+	if found, fresh := cached(kindTask, "t1", &task); !found || !fresh || task.Name != "Task" {
+		t.Errorf("task: found %v, fresh %v, %+v", found, fresh, task)
+	}
+
+	// Records are scoped to the team of the profile.
+	c := GetConfig()
+	c.TeamId = "2"
+	setConfig(&c)
+	if found, _ := cached(kindTask, "t1", &task); found {
+		t.Error("the task of another team was read")
+	}
+}
+
+func TestCacheFilesAreMigratedToTheStore(t *testing.T) {
+	newConfigHome(t, `{"team_id": "1", "view_id": "v1", "user_id": "u1"}`)
+	legacy := `{
+		"view_tasks": [{"id": "t1", "name": "Task"}],
+		"time_entries": [{"id": "e1", "duration": "3600000"}],
+		"task_by_id": {"t1": {"id": "t1", "name": "Task"}},
+		"expired_at": 1
+	}`
+	if err := os.WriteFile(configDir()+"/cache.json", []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, found, fresh := cachedTasks(kindViewTasks, "v1")
+	if !found || fresh || len(tasks) != 1 || tasks[0].Name != "Task" {
+		t.Errorf("view tasks: found %v, fresh %v, %+v", found, fresh, tasks)
+	}
+	var entries []TimeEntry
+	if found, _ := cached(kindTimeEntries, "u1", &entries); !found || len(entries) != 1 {
+		t.Errorf("time entries not migrated: %+v", entries)
+	}
+	if _, err := os.Stat(configDir() + "/cache.json"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the cache file was not removed: %v", err)
+	}
+}
+
+func TestStoreIsOpenedOnceAndReleased(t *testing.T) {
+	newConfigHome(t, `{"team_id": "1"}`)
+	if err := putCached(kindTask, "t1", Task{Id: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	db := storeDB
+	var task Task
+	if found, _ := cached(kindTask, "t1", &task); !found || storeDB != db {
+		t.Fatalf("found %v, the store was opened again: %v", found, storeDB != db)
+	}
+
+	// Once closed, another process can lock the file.
+	if err := CloseStore(); err != nil {
+		t.Fatal(err)
+	}
+	other, err := bolt.Open(storeFile(), 0644, &bolt.Options{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("the store is still locked: %v", err)
+	}
+	if err := other.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := cached(kindTask, "t1", &task); !found {
+		t.Error("the store was not opened again")
+	}
+}
+
+func TestListsShareTheStoredTasks(t *testing.T) {
+	newConfigHome(t, `{"team_id": "1"}`)
+	tasks := []Task{{Id: "t1", Name: "First"}, {Id: "t2", Name: "Second"}}
+	if err := putTasks(kindViewTasks, "v1", tasks, tasks); err != nil {
+		t.Fatal(err)
+	}
+	if err := putTasks(kindTimesheetTasks, "filter", tasks[:1], tasks[:1]); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	if found, _ := cached(kindViewTasks, "v1", &ids); !found || len(ids) != 2 || ids[0] != "t1" {
+		t.Fatalf("the list does not hold the ids of its tasks: %v", ids)
+	}
+
+	// A change to a task is a single record, seen by every list.
+	if err := cacheTask(Task{Id: "t1", Name: "First, renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	for kind, key := range map[storeKind]string{kindViewTasks: "v1", kindTimesheetTasks: "filter"} {
+		got, found, fresh := cachedTasks(kind, key)
+		if !found || !fresh || got[0].Name != "First, renamed" {
+			t.Errorf("%s: found %v, fresh %v, %+v", kind, found, fresh, got)
+		}
+	}
+
+	// A list missing one of its tasks is fetched again.
+	if err := withStore(true, func(tx *bolt.Tx) error {
+		b, err := activeScope().bucket(tx, kindListTask, false)
+		if err != nil {
+			return err
+		}
+		return b.Delete([]byte("t2"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := cachedTasks(kindViewTasks, "v1"); found {
+		t.Error("incomplete list found")
+	}
+}
+
+func TestListsOfTasksAreIndexedByMigration(t *testing.T) {
+	newConfigHome(t, `{"team_id": "1"}`)
+	// A store of version 1, where the lists held their tasks.
+	if err := withStore(true, func(tx *bolt.Tx) error {
+		if err := tx.Bucket(metaBucket).Put([]byte("version"), []byte("1")); err != nil {
+			return err
+		}
+		return activeScope().put(tx, kindViewTasks, "v1", []Task{{Id: "t1", Name: "Task"}}, 0)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := CloseStore(); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, found, fresh := cachedTasks(kindViewTasks, "v1")
+	if !found || fresh || len(tasks) != 1 || tasks[0].Name != "Task" {
+		t.Errorf("view tasks: found %v, fresh %v, %+v", found, fresh, tasks)
+	}
+}
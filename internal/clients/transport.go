@@ -0,0 +1,197 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	maxRetries        = 4
+	maxNetworkRetries = 1 // lower, so that the client notices quickly that it is offline
+	baseBackoff       = 500 * time.Millisecond
+	maxBackoff        = 30 * time.Second
+	maxRateLimitWait  = 90 * time.Second
+)
+
+// APIError is returned when ClickUp answers with a non 2xx status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	Message    string `json:"err"`
+	Code       string `json:"ECODE"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", e.Method, e.Path, e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Code != "" {
+		msg += " (" + e.Code + ")"
+	}
+	return msg
+}
+
+// Retryable reports whether the request can be safely sent again.
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// do sends an authenticated request to the ClickUp API using the client token.
+func (c *ClickupClient) do(ctx context.Context, method string, path string, body any, out any) error {
+	return c.doWithToken(ctx, c.APIToken, method, path, body, out)
+}
+
+// doWithToken sends a request to the ClickUp API, retrying transient failures
+// with exponential backoff and waiting for the rate limit window to reset on 429.
+// Requests that are not idempotent are only retried when ClickUp rate limited them,
+// because in that case they were never processed.
+func (c *ClickupClient) doWithToken(ctx context.Context, token string, method string, path string, body any, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+	return c.request(ctx, token, method, path, "application/json", payload, out)
+}
+
+// request sends a payload of the given content type, with the retries of doWithToken.
+func (c *ClickupClient) request(ctx context.Context, token string, method string, path string, contentType string, payload []byte, out any) error {
+	idempotent := method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+	// Once ClickUp could not be reached, requests fail right away until one gets an answer.
+	wasOffline := c.offline.Load()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, token, method, path, contentType, payload)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			if !idempotent || attempt >= maxNetworkRetries || wasOffline {
+				c.offline.Store(true)
+				return err
+			}
+			if err := sleep(ctx, backoff(attempt)); err != nil {
+				return err
+			}
+			continue
+		}
+		c.offline.Store(false)
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			err = decodeResponse(resp, out)
+			resp.Body.Close()
+			return err
+		}
+
+		apiErr := newAPIError(method, path, resp)
+		wait := backoff(attempt)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait = rateLimitWait(resp.Header, wait)
+		}
+		resp.Body.Close()
+		if !apiErr.Retryable() || attempt >= maxRetries {
+			return apiErr
+		}
+		if !idempotent && resp.StatusCode != http.StatusTooManyRequests {
+			return apiErr
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *ClickupClient) send(ctx context.Context, token string, method string, path string, contentType string, payload []byte) (*http.Response, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", contentType)
+	return c.HTTPClient.Do(req)
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(responseBody, out)
+}
+
+func newAPIError(method string, path string, resp *http.Response) *APIError {
+	apiErr := &APIError{}
+	if responseBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024)); err == nil {
+		_ = json.Unmarshal(responseBody, apiErr)
+	}
+	apiErr.Method = method
+	apiErr.Path = path
+	apiErr.StatusCode = resp.StatusCode
+	apiErr.Status = resp.Status
+	return apiErr
+}
+
+// backoff returns the exponential backoff for the given attempt with full jitter.
+func backoff(attempt int) time.Duration {
+	d := baseBackoff << attempt
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+	return time.Duration(rand.Int64N(int64(d))) + baseBackoff/2
+}
+
+// rateLimitWait computes how long to wait before the rate limit window resets.
+// ClickUp sends X-RateLimit-Reset as a unix timestamp in seconds.
+func rateLimitWait(header http.Header, fallback time.Duration) time.Duration {
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		wait := time.Until(time.Unix(reset, 0)) + 100*time.Millisecond
+		if wait > 0 {
+			return min(wait, maxRateLimitWait)
+		}
+		return fallback
+	}
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		return min(time.Duration(seconds)*time.Second, maxRateLimitWait)
+	}
+	return fallback
+}
+
+// sleep waits between two attempts, or until the context is done. Tests replace it to
+// check the waits without waiting.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IsCanceled reports whether the error comes from a request cancelled by the user.
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,153 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordWaits replaces the waits between attempts with a record of their durations.
+func recordWaits(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	previous := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = previous })
+	return &waits
+}
+
+// transportServer answers each request with the result of respond, given the number of
+// the request from 1, and returns a client for it with the number of requests received.
+func transportServer(t *testing.T, respond func(w http.ResponseWriter, n int)) (*ClickupClient, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond(w, int(requests.Add(1)))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClickupClientWithBaseURL(srv.URL, "pk_test", "1"), &requests
+}
+
+// dropConnection closes the connection without answering, as when the network is down.
+func dropConnection(w http.ResponseWriter) {
+	if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+func TestRateLimitWaitsForTheReset(t *testing.T) {
+	waits := recordWaits(t)
+	client, requests := transportServer(t, func(w http.ResponseWriter, n int) {
+		if n == 1 {
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(5*time.Second).Unix(), 10))
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+
+	if err := client.do(context.Background(), http.MethodGet, "/api/v2/user", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 2 || len(*waits) != 1 {
+		t.Fatalf("%d requests, waits %v", requests.Load(), *waits)
+	}
+	// The reset is in whole seconds: the wait ends within the second of the reset.
+	if wait := (*waits)[0]; wait < 4*time.Second || wait > 5200*time.Millisecond {
+		t.Errorf("waited %s, want until the reset in 5s", wait)
+	}
+}
+
+func TestRateLimitWaitsForRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		retryAfter string
+		want       time.Duration
+	}{
+		{"7", 7 * time.Second},
+		{"600", maxRateLimitWait},
+	} {
+		waits := recordWaits(t)
+		client, _ := transportServer(t, func(w http.ResponseWriter, n int) {
+			if n == 1 {
+				w.Header().Set("Retry-After", tc.retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		})
+		if err := client.do(context.Background(), http.MethodGet, "/api/v2/user", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if len(*waits) != 1 || (*waits)[0] != tc.want {
+			t.Errorf("Retry-After %s: waits %v, want %s", tc.retryAfter, *waits, tc.want)
+		}
+	}
+}
+
+func TestPostIsRetriedOnlyWhenRateLimited(t *testing.T) {
+	recordWaits(t)
+	ctx := context.Background()
+
+	client, requests := transportServer(t, func(w http.ResponseWriter, n int) {
+		if n <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+	if err := client.do(ctx, http.MethodPost, "/api/v2/task/t1/time", map[string]int{"time": 1}, nil); err != nil || requests.Load() != 3 {
+		t.Errorf("rate limited POST: %d requests, %v", requests.Load(), err)
+	}
+
+	// The server may have processed the request before failing, or before the
+	// connection dropped: sending it again could create a duplicate.
+	client, requests = transportServer(t, func(w http.ResponseWriter, n int) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	err := client.do(ctx, http.MethodPost, "/api/v2/task/t1/time", map[string]int{"time": 1}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || requests.Load() != 1 {
+		t.Errorf("POST failing with 503: %d requests, %v", requests.Load(), err)
+	}
+
+	client, requests = transportServer(t, func(w http.ResponseWriter, n int) {
+		dropConnection(w)
+	})
+	err = client.do(ctx, http.MethodPost, "/api/v2/task/t1/time", map[string]int{"time": 1}, nil)
+	if !IsOffline(err) || requests.Load() != 1 {
+		t.Errorf("POST without an answer: %d requests, %v", requests.Load(), err)
+	}
+}
+
+func TestRetriesStopAtTheLimit(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name    string
+		method  string
+		respond func(w http.ResponseWriter, n int)
+		want    int
+	}{
+		{"server error", http.MethodGet, func(w http.ResponseWriter, n int) { w.WriteHeader(http.StatusBadGateway) }, maxRetries + 1},
+		{"rate limit", http.MethodPost, func(w http.ResponseWriter, n int) { w.WriteHeader(http.StatusTooManyRequests) }, maxRetries + 1},
+		{"network error", http.MethodGet, func(w http.ResponseWriter, n int) { dropConnection(w) }, maxNetworkRetries + 1},
+		{"client error", http.MethodGet, func(w http.ResponseWriter, n int) { w.WriteHeader(http.StatusNotFound) }, 1},
+	} {
+		waits := recordWaits(t)
+		client, requests := transportServer(t, tc.respond)
+		if err := client.do(ctx, tc.method, "/api/v2/user", nil, nil); err == nil {
+			t.Errorf("%s: no error", tc.name)
+		}
+		if int(requests.Load()) != tc.want || len(*waits) != tc.want-1 {
+			t.Errorf("%s: %d requests and %d waits, want %d requests", tc.name, requests.Load(), len(*waits), tc.want)
+		}
+	}
+
+	// Once offline, requests fail on the first network error.
+	client, requests := transportServer(t, func(w http.ResponseWriter, n int) { dropConnection(w) })
+	client.offline.Store(true)
+	if err := client.do(ctx, http.MethodGet, "/api/v2/user", nil, nil); !IsOffline(err) || requests.Load() != 1 {
+		t.Errorf("offline client: %d requests, %v", requests.Load(), err)
+	}
+}
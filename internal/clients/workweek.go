@@ -0,0 +1,198 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultDailyTarget is the number of hours expected on a day without a target.
+const defaultDailyTarget = 8.0
+
+// maxHolidayDays bounds the days of a single holiday, against calendars of recurring events.
+const maxHolidayDays = 366
+
+var defaultWorkDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// WorkWeek is the week of the timesheet: the day it starts on, the days it shows and the
+// hours expected on each of them. Days are English names, full or abbreviated ("mon").
+type WorkWeek struct {
+	FirstDay string             `json:"first_day,omitempty"` // Monday by default
+	Days     []string           `json:"days,omitempty"`      // Monday to Friday by default
+	Targets  map[string]float64 `json:"targets,omitempty"`   // hours by day, 8 for the days not listed
+	Holidays string             `json:"holidays,omitempty"`  // ICS or JSON file of the public holidays
+}
+
+// ParseWeekday parses the English name of a day of the week, or its first three letters.
+func ParseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("unknown day %q", name)
+}
+
+// Validate reports the names of days that cannot be parsed and the negative targets.
+func (w WorkWeek) Validate() error {
+	names := slices.Clone(w.Days)
+	if w.FirstDay != "" {
+		names = append(names, w.FirstDay)
+	}
+	for name, hours := range w.Targets {
+		if hours < 0 {
+			return fmt.Errorf("negative target for %s", name)
+		}
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if _, err := ParseWeekday(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start returns the first day of the week.
+func (w WorkWeek) Start() time.Weekday {
+	if day, err := ParseWeekday(w.FirstDay); err == nil {
+		return day
+	}
+	return time.Monday
+}
+
+// Weekdays returns the days shown, in their order from the first day of the week.
+func (w WorkWeek) Weekdays() []time.Weekday {
+	var days []time.Weekday
+	for _, name := range w.Days {
+		if day, err := ParseWeekday(name); err == nil && !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		days = slices.Clone(defaultWorkDays)
+	}
+	start := w.Start()
+	slices.SortFunc(days, func(a, b time.Weekday) int {
+		return (int(a-start)+7)%7 - (int(b-start)+7)%7
+	})
+	return days
+}
+
+// Target returns the hours expected on a day of the week.
+func (w WorkWeek) Target(day time.Weekday) float64 {
+	for name, hours := range w.Targets {
+		if d, err := ParseWeekday(name); err == nil && d == day {
+			return hours
+		}
+	}
+	return defaultDailyTarget
+}
+
+// WeekStart returns the midnight of the first day of the week that contains t.
+func (w WorkWeek) WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()-w.Start()) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// LoadHolidays reads the public holidays of a file, by date as "2006-01-02". The file is
+// either an iCalendar (.ics) export, or JSON: an object of names by date, or an array of
+// objects with a date and a name such as the ones of date.nager.at.
+func LoadHolidays(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "BEGIN:VCALENDAR") {
+		return parseICSHolidays(string(data))
+	}
+	return parseJSONHolidays(data)
+}
+
+func parseJSONHolidays(data []byte) (map[string]string, error) {
+	holidays := map[string]string{}
+	if err := json.Unmarshal(data, &holidays); err != nil {
+		var list []struct {
+			Date      string `json:"date"`
+			Name      string `json:"name"`
+			LocalName string `json:"localName"`
+		}
+		if json.Unmarshal(data, &list) != nil {
+			return nil, fmt.Errorf("holidays: expected an object of names by date or an array of dates: %w", err)
+		}
+		for _, h := range list {
+			if h.Name == "" {
+				h.Name = h.LocalName
+			}
+			holidays[h.Date] = h.Name
+		}
+	}
+	for date := range holidays {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("holidays: invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	return holidays, nil
+}
+
+// parseICSHolidays reads the events of a calendar as holidays. DTEND is exclusive, as in
+// the all day events of the exports, and the times are ignored.
+func parseICSHolidays(data string) (map[string]string, error) {
+	// Long lines are folded on the next ones, which start with a space or a tab.
+	data = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(data)
+	holidays := map[string]string{}
+	var start, end time.Time
+	var summary string
+	for _, line := range strings.Split(data, "\n") {
+		property, value, ok := strings.Cut(strings.TrimRight(line, "\r"), ":")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(property, ";")
+		var err error
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if value == "VEVENT" {
+				start, end, summary = time.Time{}, time.Time{}, ""
+			}
+		case "DTSTART":
+			start, err = parseICSDate(value)
+		case "DTEND":
+			end, err = parseICSDate(value)
+		case "SUMMARY":
+			summary = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(value)
+		case "END":
+			if value != "VEVENT" || start.IsZero() {
+				continue
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day, n := start, 0; day.Before(end) && n < maxHolidayDays; day, n = day.AddDate(0, 0, 1), n+1 {
+				holidays[day.Format("2006-01-02")] = summary
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return holidays, nil
+}
+
+// parseICSDate parses the date of a DATE or DATE-TIME value such as 20261225 or 20261225T090000Z.
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) >= 8 {
+		if day, err := time.Parse("20060102", value[:8]); err == nil {
+			return day, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("holidays: invalid date %q", value)
+}
@@ -0,0 +1,107 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkWeekDefaultsToMondayToFriday(t *testing.T) {
+	newConfigHome(t, `{"clickup_token": "pk_1"}`)
+	week := GetConfig().WorkWeek
+	if got := week.Weekdays(); !slices.Equal(got, defaultWorkDays) {
+		t.Errorf("days = %v, want Monday to Friday", got)
+	}
+	if got := week.Target(time.Wednesday); got != 8 {
+		t.Errorf("target = %v, want 8", got)
+	}
+	sunday := time.Date(2026, 10, 18, 15, 0, 0, 0, time.Local)
+	if got := week.WeekStart(sunday); !got.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("week of %s starts on %s, want Monday 12", sunday, got)
+	}
+
+	if err := SaveConfig(GetConfig()); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(configDir() + "/config.json"); strings.Contains(string(data), "work_week") {
+		t.Errorf("empty work week saved:\n%s", data)
+	}
+}
+
+func TestWorkWeekFromSundayToThursday(t *testing.T) {
+	newConfigHome(t, `{"work_week": {"first_day": "sunday", "days": ["Thu", "sun", "Mon", "tue", "wed"], "targets": {"thursday": 6}}}`)
+	week := GetConfig().WorkWeek
+	if err := week.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday}
+	if got := week.Weekdays(); !slices.Equal(got, want) {
+		t.Errorf("days = %v, want %v", got, want)
+	}
+	if week.Target(time.Thursday) != 6 || week.Target(time.Sunday) != 8 {
+		t.Errorf("targets = %v and %v, want 6 and 8", week.Target(time.Thursday), week.Target(time.Sunday))
+	}
+	saturday := time.Date(2026, 10, 17, 15, 0, 0, 0, time.Local)
+	if got := week.WeekStart(saturday); !got.Equal(time.Date(2026, 10, 11, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("week of %s starts on %s, want Sunday 11", saturday, got)
+	}
+
+	if err := (WorkWeek{Days: []string{"mon", "funday"}}).Validate(); err == nil {
+		t.Error("unknown day accepted")
+	}
+	if err := (WorkWeek{Targets: map[string]float64{"fri": -1}}).Validate(); err == nil {
+		t.Error("negative target accepted")
+	}
+}
+
+func TestLoadHolidays(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	ics := write("holidays.ics", strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261225",
+		"DTEND;VALUE=DATE:20261227",
+		"SUMMARY:Christmas\\, Boxing",
+		"  Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20260101T000000Z",
+		"SUMMARY:New Year",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n"))
+	holidays, err := LoadHolidays(ics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holidays) != 3 || holidays["2026-12-25"] != "Christmas, Boxing Day" || holidays["2026-12-26"] == "" || holidays["2026-01-01"] != "New Year" {
+		t.Errorf("unexpected holidays %v", holidays)
+	}
+
+	byDate := write("by-date.json", `{"2026-05-01": "Labour Day"}`)
+	if holidays, err := LoadHolidays(byDate); err != nil || holidays["2026-05-01"] != "Labour Day" {
+		t.Errorf("holidays = %v, %v", holidays, err)
+	}
+	nager := write("nager.json", `[{"date": "2026-06-02", "localName": "Festa della Repubblica", "name": "Republic Day"}]`)
+	if holidays, err := LoadHolidays(nager); err != nil || holidays["2026-06-02"] != "Republic Day" {
+		t.Errorf("holidays = %v, %v", holidays, err)
+	}
+	invalid := write("invalid.json", `{"1st of May": "Labour Day"}`)
+	if _, err := LoadHolidays(invalid); err == nil {
+		t.Error("invalid date accepted")
+	}
+	if _, err := LoadHolidays(filepath.Join(dir, "missing.ics")); err == nil {
+		t.Error("missing file accepted")
+	}
+}
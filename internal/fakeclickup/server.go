@@ -0,0 +1,1123 @@
+// Package fakeclickup provides an in-memory ClickUp API server for tests.
+//
+// The server implements the subset of the ClickUp v2 API used by clickup-tui
+// on top of seedable fixtures, so that the client and the views can be
+// exercised end-to-end without network access.
+package fakeclickup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+)
+
+const (
+	DefaultToken = "pk_test_token"
+	DefaultTeam  = "team-1"
+	pageSize     = 100
+)
+
+// Fixtures is the initial state of the fake workspace.
+type Fixtures struct {
+	Token       string
+	User        clients.User
+	Teams       []clients.Team
+	Tasks       []clients.Task
+	Views       map[string][]string          // view id -> task ids, in board order
+	Spaces      map[string][]clients.Space   // team id -> spaces
+	Folders     map[string][]clients.Folder  // space id -> folders
+	Lists       map[string][]clients.List    // folder or space id -> lists
+	ViewDefs    map[string][]clients.View    // team, space, folder or list id -> views
+	Comments    map[string][]clients.Comment // task id -> comments
+	Replies     map[string][]clients.Comment // comment id -> replies
+	TimeEntries []clients.TimeEntry
+	Files       map[string][]byte // attachment id -> content
+}
+
+// Server is an httptest server backed by in-memory fixtures.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	data     Fixtures
+	running  *clients.TimeEntry
+	nextId   int
+	failures map[string]failure
+	offline  bool
+	updated  int64 // last date_updated given to a task
+	requests []string
+}
+
+type failure struct {
+	status int
+	count  int
+}
+
+// New starts a fake ClickUp server seeded with the given fixtures.
+// The caller must Close it when done.
+func New(fixtures Fixtures) *Server {
+	if fixtures.Token == "" {
+		fixtures.Token = DefaultToken
+	}
+	if fixtures.Teams == nil {
+		fixtures.Teams = []clients.Team{{Id: DefaultTeam, Name: "Test team"}}
+	}
+	if fixtures.Views == nil {
+		fixtures.Views = map[string][]string{}
+	}
+	if fixtures.Comments == nil {
+		fixtures.Comments = map[string][]clients.Comment{}
+	}
+	if fixtures.Replies == nil {
+		fixtures.Replies = map[string][]clients.Comment{}
+	}
+	if fixtures.Files == nil {
+		fixtures.Files = map[string][]byte{}
+	}
+	s := &Server{data: fixtures, nextId: 1000, failures: map[string]failure{}}
+	s.Server = httptest.NewServer(s.routes())
+	// The attachments of the fixtures are served by the server itself.
+	for i := range s.data.Tasks {
+		// As on ClickUp, every task has a date_updated.
+		if s.data.Tasks[i].DateUpdated == "" {
+			s.touch(&s.data.Tasks[i])
+		}
+		for j := range s.data.Tasks[i].Attachments {
+			if a := &s.data.Tasks[i].Attachments[j]; a.Url == "" {
+				a.Url = s.attachmentURL(*a)
+			}
+		}
+	}
+	return s
+}
+
+// Client returns a ClickUp client authenticated against the fake server.
+func (s *Server) Client() *clients.ClickupClient {
+	return clients.NewClickupClientWithBaseURL(s.URL, s.data.Token, s.data.Teams[0].Id)
+}
+
+// FailNext makes the next n requests matching the pattern ("METHOD /path")
+// answer with the given status code. With status 0 the connection is dropped instead,
+// as when the network goes down in the middle of a change.
+func (s *Server) FailNext(pattern string, status int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[pattern] = failure{status: status, count: n}
+}
+
+// SetOffline makes the server drop every connection without answering, as when
+// the network is down, until it is called again with false.
+func (s *Server) SetOffline(offline bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offline = offline
+}
+
+// Task returns the current state of a task.
+func (s *Server) Task(id string) (clients.Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(id)
+	if idx < 0 {
+		return clients.Task{}, false
+	}
+	return s.data.Tasks[idx], true
+}
+
+// TimeEntries returns a copy of the time entries currently stored.
+func (s *Server) TimeEntries() []clients.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.data.TimeEntries)
+}
+
+// PutTimeEntry adds a time entry, or replaces the one with the same id, as if it
+// was changed on ClickUp by someone else.
+func (s *Server) PutTimeEntry(entry clients.TimeEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if idx := slices.IndexFunc(s.data.TimeEntries, func(e clients.TimeEntry) bool { return e.Id == entry.Id }); idx >= 0 {
+		s.data.TimeEntries[idx] = entry
+		return
+	}
+	s.data.TimeEntries = append(s.data.TimeEntries, entry)
+}
+
+// PutTask adds a task, or replaces the one with the same id, as if it was changed on
+// ClickUp by someone else. Its date_updated is set to now.
+func (s *Server) PutTask(task clients.Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.touch(&task)
+	if idx := s.taskIndex(task.Id); idx >= 0 {
+		s.data.Tasks[idx] = task
+		return
+	}
+	s.data.Tasks = append(s.data.Tasks, task)
+}
+
+// DeleteTask removes a task and takes it out of the views.
+func (s *Server) DeleteTask(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Tasks = slices.DeleteFunc(s.data.Tasks, func(t clients.Task) bool { return t.Id == id })
+	for view, ids := range s.data.Views {
+		s.data.Views[view] = slices.DeleteFunc(ids, func(taskId string) bool { return taskId == id })
+	}
+}
+
+// Requests returns the requests received so far, as "METHOD /path?query".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Comments returns a copy of the comments of a task.
+func (s *Server) Comments(taskId string) []clients.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.data.Comments[taskId])
+}
+
+// Replies returns the replies in the thread of a comment.
+func (s *Server) Replies(commentId string) []clients.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.data.Replies[commentId])
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v2/user", s.handleUser)
+	mux.HandleFunc("GET /api/v2/team", s.handleTeams)
+	mux.HandleFunc("GET /api/v2/team/{team}/task", s.handleTeamTasks)
+	mux.HandleFunc("GET /api/v2/team/{team}/space", s.handleSpaces)
+	mux.HandleFunc("GET /api/v2/space/{space}/folder", s.handleFolders)
+	mux.HandleFunc("GET /api/v2/space/{parent}/list", s.handleLists)
+	mux.HandleFunc("GET /api/v2/folder/{parent}/list", s.handleLists)
+	mux.HandleFunc("GET /api/v2/team/{parent}/view", s.handleViews)
+	mux.HandleFunc("GET /api/v2/space/{parent}/view", s.handleViews)
+	mux.HandleFunc("GET /api/v2/folder/{parent}/view", s.handleViews)
+	mux.HandleFunc("GET /api/v2/list/{parent}/view", s.handleViews)
+	mux.HandleFunc("GET /api/v2/view/{view}/task", s.handleViewTasks)
+	mux.HandleFunc("GET /api/v2/task/{task}", s.handleGetTask)
+	mux.HandleFunc("PUT /api/v2/task/{task}", s.handleUpdateTask)
+	mux.HandleFunc("POST /api/v2/list/{list}/task", s.handleCreateTask)
+	mux.HandleFunc("POST /api/v2/task/{task}/field/{field}", s.handleSetCustomField)
+	mux.HandleFunc("DELETE /api/v2/task/{task}/field/{field}", s.handleSetCustomField)
+	mux.HandleFunc("PUT /api/v2/checklist/{checklist}/checklist_item/{item}", s.handleUpdateChecklistItem)
+	mux.HandleFunc("POST /api/v2/task/{task}/attachment", s.handleUploadAttachment)
+	mux.HandleFunc("GET /attachments/{attachment}/{name}", s.handleDownloadAttachment)
+	mux.HandleFunc("POST /api/v2/task/{task}/tag/{tag}", s.handleAddTag)
+	mux.HandleFunc("DELETE /api/v2/task/{task}/tag/{tag}", s.handleRemoveTag)
+	mux.HandleFunc("GET /api/v2/task/{task}/comment", s.handleComments)
+	mux.HandleFunc("POST /api/v2/task/{task}/comment", s.handleCreateComment)
+	mux.HandleFunc("GET /api/v2/comment/{comment}/reply", s.handleReplies)
+	mux.HandleFunc("POST /api/v2/comment/{comment}/reply", s.handleCreateReply)
+	mux.HandleFunc("PUT /api/v2/comment/{comment}", s.handleUpdateComment)
+	mux.HandleFunc("POST /api/v2/comment/{comment}/reaction", s.handleAddReaction)
+	mux.HandleFunc("DELETE /api/v2/comment/{comment}/reaction/{reaction}", s.handleRemoveReaction)
+	mux.HandleFunc("GET /api/v2/team/{team}/time_entries", s.handleTimeEntries)
+	mux.HandleFunc("POST /api/v2/task/{task}/time", s.handleCreateTimeEntry)
+	mux.HandleFunc("DELETE /api/v2/task/{task}/time/{entry}", s.handleDeleteTimeEntry)
+	mux.HandleFunc("GET /api/v2/team/{team}/time_entries/{entry}", s.handleGetTimeEntry)
+	mux.HandleFunc("PUT /api/v2/team/{team}/time_entries/{entry}", s.handleUpdateTimeEntry)
+	mux.HandleFunc("POST /api/v2/team/{team}/time_entries/start", s.handleStartTimer)
+	mux.HandleFunc("POST /api/v2/team/{team}/time_entries/stop", s.handleStopTimer)
+	mux.HandleFunc("GET /api/v2/team/{team}/time_entries/current", s.handleCurrentTimer)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		offline := s.offline
+		s.mu.Unlock()
+		if offline {
+			if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				conn.Close()
+			}
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+		s.mu.Unlock()
+		if r.Header.Get("Authorization") != s.data.Token {
+			writeError(w, http.StatusUnauthorized, "Token invalid", "OAUTH_025")
+			return
+		}
+		s.mu.Lock()
+		pattern := r.Method + " " + r.URL.Path
+		if f := s.failures[pattern]; f.count > 0 {
+			f.count--
+			s.failures[pattern] = f
+			s.mu.Unlock()
+			if f.status == 0 {
+				if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+					conn.Close()
+				}
+				return
+			}
+			writeError(w, f.status, "Injected failure", "TEST_001")
+			return
+		}
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) newId() string {
+	s.nextId++
+	return strconv.Itoa(s.nextId)
+}
+
+// touch sets the date_updated of a task to now, always later than the one of the
+// previous change so that date_updated_gt never misses a change.
+func (s *Server) touch(task *clients.Task) {
+	s.updated = max(time.Now().UnixMilli(), s.updated+1)
+	task.DateUpdated = strconv.FormatInt(s.updated, 10)
+}
+
+// updatedSince keeps the tasks changed after the date_updated_gt of the request, if any.
+func updatedSince(r *http.Request, tasks []clients.Task) []clients.Task {
+	gt, err := strconv.ParseInt(r.URL.Query().Get("date_updated_gt"), 10, 64)
+	if err != nil {
+		return tasks
+	}
+	return slices.DeleteFunc(tasks, func(t clients.Task) bool {
+		updated, _ := strconv.ParseInt(t.DateUpdated, 10, 64)
+		return updated <= gt
+	})
+}
+
+func (s *Server) taskIndex(id string) int {
+	return slices.IndexFunc(s.data.Tasks, func(t clients.Task) bool { return t.Id == id })
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, clients.UserResponse{User: s.data.User})
+}
+
+func (s *Server) handleTeams(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, clients.TeamsResponse{Teams: s.data.Teams})
+}
+
+func (s *Server) handleSpaces(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spaces := s.data.Spaces[r.PathValue("team")]
+	if spaces == nil {
+		spaces = []clients.Space{}
+	}
+	writeJSON(w, map[string]any{"spaces": spaces})
+}
+
+func (s *Server) handleFolders(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	folders := slices.Clone(s.data.Folders[r.PathValue("space")])
+	for i := range folders {
+		folders[i].Lists = s.data.Lists[folders[i].Id]
+	}
+	if folders == nil {
+		folders = []clients.Folder{}
+	}
+	writeJSON(w, map[string]any{"folders": folders})
+}
+
+func (s *Server) handleLists(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lists := s.data.Lists[r.PathValue("parent")]
+	if lists == nil {
+		lists = []clients.List{}
+	}
+	writeJSON(w, map[string]any{"lists": lists})
+}
+
+func (s *Server) handleViews(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	views := s.data.ViewDefs[r.PathValue("parent")]
+	if views == nil {
+		views = []clients.View{}
+	}
+	writeJSON(w, map[string]any{"views": views})
+}
+
+func (s *Server) handleTeamTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tags := r.URL.Query()["tags[]"]
+	var tasks []clients.Task
+	for _, t := range s.data.Tasks {
+		if len(tags) > 0 && !slices.ContainsFunc(t.Tags, func(tag clients.Tag) bool { return slices.Contains(tags, tag.Name) }) {
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	writePage(w, r, updatedSince(r, tasks))
+}
+
+func (s *Server) handleViewTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, ok := s.data.Views[r.PathValue("view")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "View not found", "VIEW_001")
+		return
+	}
+	var tasks []clients.Task
+	for _, id := range ids {
+		if idx := s.taskIndex(id); idx >= 0 {
+			tasks = append(tasks, s.data.Tasks[idx])
+		}
+	}
+	writePage(w, r, updatedSince(r, tasks))
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(r.PathValue("task"))
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	task := s.data.Tasks[idx]
+	if r.URL.Query().Get("include_subtasks") == "true" {
+		for _, t := range s.data.Tasks {
+			if t.Parent == task.Id {
+				task.SubTasks = append(task.SubTasks, t)
+			}
+		}
+	}
+	writeJSON(w, task)
+}
+
+func (s *Server) attachmentURL(a clients.Attachment) string {
+	return fmt.Sprintf("%s/attachments/%s/%s", s.URL, a.Id, url.PathEscape(a.Title))
+}
+
+// handleUploadAttachment stores the file sent in the "attachment" part of the form.
+func (s *Server) handleUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	file, header, err := r.FormFile("attachment")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(r.PathValue("task"))
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	attachment := clients.Attachment{
+		Id:        s.newId(),
+		Title:     header.Filename,
+		Extension: strings.TrimPrefix(path.Ext(header.Filename), "."),
+		Mimetype:  http.DetectContentType(content),
+		Size:      int64(len(content)),
+		Date:      json.Number(strconv.FormatInt(time.Now().UnixMilli(), 10)),
+		User:      s.data.User,
+	}
+	attachment.Url = s.attachmentURL(attachment)
+	s.data.Files[attachment.Id] = content
+	task := &s.data.Tasks[idx]
+	task.Attachments = append(task.Attachments, attachment)
+	s.touch(task)
+	writeJSON(w, attachment)
+}
+
+func (s *Server) handleDownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	content, ok := s.data.Files[r.PathValue("attachment")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "Attachment not found", "ATTCH_001")
+		return
+	}
+	w.Write(content)
+}
+
+// handleSetCustomField sets or removes the value of a custom field, stored in the shape
+// ClickUp sends it back: drop-downs by orderindex, users and tasks as objects.
+func (s *Server) handleSetCustomField(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(r.PathValue("task"))
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	task := &s.data.Tasks[idx]
+	fieldIdx := slices.IndexFunc(task.CustomFields, func(f clients.CustomField) bool { return f.Id == r.PathValue("field") })
+	if fieldIdx < 0 {
+		writeError(w, http.StatusNotFound, "Custom field not found", "FIELD_001")
+		return
+	}
+	field := &task.CustomFields[fieldIdx]
+	if r.Method == http.MethodDelete {
+		field.Value = nil
+		s.touch(task)
+		writeJSON(w, map[string]any{})
+		return
+	}
+	var body struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	value := body.Value
+	switch field.Type {
+	case clients.FieldDropDown:
+		var id string
+		json.Unmarshal(body.Value, &id)
+		option, ok := field.Option(id)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Option not found", "FIELD_002")
+			return
+		}
+		value, _ = json.Marshal(option.Orderindex)
+	case clients.FieldUsers, clients.FieldTasks:
+		var change struct {
+			Add []json.RawMessage `json:"add"`
+			Rem []json.RawMessage `json:"rem"`
+		}
+		if err := json.Unmarshal(body.Value, &change); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+			return
+		}
+		var items []map[string]any
+		json.Unmarshal(field.Value, &items)
+		key := func(raw json.RawMessage) string { return strings.Trim(string(raw), `"`) }
+		items = slices.DeleteFunc(items, func(item map[string]any) bool {
+			return slices.ContainsFunc(change.Rem, func(raw json.RawMessage) bool { return fmt.Sprint(item["id"]) == key(raw) })
+		})
+		for _, raw := range change.Add {
+			if field.Type == clients.FieldUsers {
+				id, _ := strconv.Atoi(key(raw))
+				user := s.findUser(id)
+				items = append(items, map[string]any{"id": id, "username": user.Username, "initials": user.Initials})
+			} else {
+				item := map[string]any{"id": key(raw)}
+				if i := s.taskIndex(key(raw)); i >= 0 {
+					item["name"] = s.data.Tasks[i].Name
+				}
+				items = append(items, item)
+			}
+		}
+		value, _ = json.Marshal(items)
+	}
+	field.Value = value
+	field.Typed = clients.CustomFieldValue{}
+	s.touch(task)
+	writeJSON(w, map[string]any{})
+}
+
+func (s *Server) handleUpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body struct {
+		Resolved *bool `json:"resolved"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	for i := range s.data.Tasks {
+		task := &s.data.Tasks[i]
+		for _, checklist := range task.Checklists {
+			if checklist.Id != r.PathValue("checklist") {
+				continue
+			}
+			item := findChecklistItem(checklist.Items, r.PathValue("item"))
+			if item == nil {
+				break
+			}
+			if body.Resolved != nil {
+				item.Resolved = *body.Resolved
+			}
+			s.touch(task)
+			writeJSON(w, map[string]any{"checklist": checklist})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Checklist item not found", "CHECK_001")
+}
+
+func findChecklistItem(items []clients.ChecklistItem, id string) *clients.ChecklistItem {
+	for i := range items {
+		if items[i].Id == id {
+			return &items[i]
+		}
+		if item := findChecklistItem(items[i].Children, id); item != nil {
+			return item
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(r.PathValue("task"))
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	var update struct {
+		Name         *string `json:"name"`
+		Description  *string `json:"markdown_description"`
+		Status       *string `json:"status"`
+		Priority     *int    `json:"priority"`
+		DueDate      *int64  `json:"due_date"`
+		StartDate    *int64  `json:"start_date"`
+		TimeEstimate *int64  `json:"time_estimate"`
+		Assignees    *struct {
+			Add []int `json:"add"`
+			Rem []int `json:"rem"`
+		} `json:"assignees"`
+	}
+	raw, _ := json.Marshal(body)
+	if err := json.Unmarshal(raw, &update); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	task := &s.data.Tasks[idx]
+	if update.Name != nil {
+		if *update.Name == "" {
+			writeError(w, http.StatusBadRequest, "Task name invalid", "INPUT_002")
+			return
+		}
+		task.Name = *update.Name
+	}
+	if update.Description != nil {
+		task.Description = *update.Description
+	}
+	if update.Status != nil {
+		task.Status = s.findStatus(*update.Status)
+	}
+	if _, ok := body["priority"]; ok {
+		task.Priority = nil
+		if update.Priority != nil && *update.Priority > 0 {
+			task.Priority = newPriority(*update.Priority)
+		}
+	}
+	if _, ok := body["due_date"]; ok {
+		task.DueDate = formatMillis(update.DueDate)
+	}
+	if _, ok := body["start_date"]; ok {
+		task.StartDate = formatMillis(update.StartDate)
+	}
+	if _, ok := body["time_estimate"]; ok {
+		task.TimeEstimate = 0
+		if update.TimeEstimate != nil {
+			task.TimeEstimate = *update.TimeEstimate
+		}
+	}
+	if update.Assignees != nil {
+		task.Assignees = slices.DeleteFunc(task.Assignees, func(u clients.User) bool {
+			id, _ := strconv.Atoi(u.IdString())
+			return slices.Contains(update.Assignees.Rem, id)
+		})
+		for _, id := range update.Assignees.Add {
+			task.Assignees = append(task.Assignees, s.findUser(id))
+		}
+	}
+	s.touch(task)
+	writeJSON(w, *task)
+}
+
+func (s *Server) handleAddTag(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(r.PathValue("task"))
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	task := &s.data.Tasks[idx]
+	name := r.PathValue("tag")
+	if !slices.ContainsFunc(task.Tags, func(t clients.Tag) bool { return t.Name == name }) {
+		task.Tags = append(task.Tags, clients.Tag{Name: name})
+	}
+	s.touch(task)
+	writeJSON(w, map[string]any{})
+}
+
+func (s *Server) handleRemoveTag(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(r.PathValue("task"))
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	task := &s.data.Tasks[idx]
+	name := r.PathValue("tag")
+	task.Tags = slices.DeleteFunc(task.Tags, func(t clients.Tag) bool { return t.Name == name })
+	s.touch(task)
+	writeJSON(w, map[string]any{})
+}
+
+func newPriority(level int) *clients.Priority {
+	names := []string{"urgent", "high", "normal", "low"}
+	return &clients.Priority{Id: strconv.Itoa(level), Priority: names[level-1]}
+}
+
+func formatMillis(v *int64) string {
+	if v == nil || *v == 0 {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body clients.NewTask
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	if body.Name == "" {
+		writeError(w, http.StatusBadRequest, "Task name invalid", "INPUT_002")
+		return
+	}
+	task := clients.Task{
+		Id:          s.newId(),
+		Name:        body.Name,
+		Description: body.Description,
+		Status:      s.findStatus(body.Status),
+		List:        s.findList(r.PathValue("list")),
+		Tags:        []clients.Tag{},
+		Assignees:   []clients.User{},
+	}
+	if body.Status == "" {
+		task.Status = s.findStatus("to do")
+	}
+	task.Url = "https://app.clickup.com/t/" + task.Id
+	for _, id := range body.Assignees {
+		task.Assignees = append(task.Assignees, s.findUser(id))
+	}
+	for _, name := range body.Tags {
+		task.Tags = append(task.Tags, clients.Tag{Name: name})
+	}
+	if body.Priority > 0 {
+		task.Priority = newPriority(body.Priority)
+	}
+	task.DueDate = formatMillis(&body.DueDate)
+	s.touch(&task)
+	s.data.Tasks = append(s.data.Tasks, task)
+	writeJSON(w, task)
+}
+
+// findList returns the list with that id, looking at the list fixtures and at the tasks.
+func (s *Server) findList(id string) clients.List {
+	for _, lists := range s.data.Lists {
+		for _, l := range lists {
+			if l.Id == id {
+				return l
+			}
+		}
+	}
+	for _, t := range s.data.Tasks {
+		if t.List.Id == id {
+			return t.List
+		}
+	}
+	return clients.List{Id: id}
+}
+
+// findUser returns the user with that id among the current user and the assignees of the tasks.
+func (s *Server) findUser(id int) clients.User {
+	if s.data.User.IdString() == strconv.Itoa(id) {
+		return s.data.User
+	}
+	for _, t := range s.data.Tasks {
+		for _, u := range t.Assignees {
+			if u.IdString() == strconv.Itoa(id) {
+				return u
+			}
+		}
+	}
+	return clients.User{Id: id}
+}
+
+// findStatus returns the full status of any task already using that name.
+func (s *Server) findStatus(name string) clients.Status {
+	for _, t := range s.data.Tasks {
+		if t.Status.Status == name {
+			return t.Status
+		}
+	}
+	return clients.Status{Status: name}
+}
+
+func (s *Server) handleComments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comments := s.data.Comments[r.PathValue("task")]
+	if comments == nil {
+		comments = []clients.Comment{}
+	}
+	writeJSON(w, map[string]any{"comments": comments})
+}
+
+func (s *Server) handleCreateComment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	taskId := r.PathValue("task")
+	if s.taskIndex(taskId) < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	comment, ok := s.decodeComment(w, r)
+	if !ok {
+		return
+	}
+	s.data.Comments[taskId] = append(s.data.Comments[taskId], comment)
+	id, _ := strconv.Atoi(comment.Id)
+	writeJSON(w, map[string]any{"id": id, "hist_id": comment.Id, "date": comment.Date})
+}
+
+// decodeComment reads the text of a new comment from the request body.
+func (s *Server) decodeComment(w http.ResponseWriter, r *http.Request) (clients.Comment, bool) {
+	var body struct {
+		CommentText string `json:"comment_text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return clients.Comment{}, false
+	}
+	return clients.Comment{
+		Id:          s.newId(),
+		Comment:     []clients.CommentText{{Text: body.CommentText}},
+		CommentText: body.CommentText,
+		User:        s.data.User,
+		Date:        strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}, true
+}
+
+// findComment returns a comment or a reply by id.
+func (s *Server) findComment(id string) *clients.Comment {
+	for _, comments := range []map[string][]clients.Comment{s.data.Comments, s.data.Replies} {
+		for key := range comments {
+			for i := range comments[key] {
+				if comments[key][i].Id == id {
+					return &comments[key][i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleReplies(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	replies := s.data.Replies[r.PathValue("comment")]
+	if replies == nil {
+		replies = []clients.Comment{}
+	}
+	writeJSON(w, map[string]any{"comments": replies})
+}
+
+func (s *Server) handleCreateReply(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parentId := r.PathValue("comment")
+	parent := s.findComment(parentId)
+	if parent == nil {
+		writeError(w, http.StatusNotFound, "Comment not found", "OAUTH_057")
+		return
+	}
+	reply, ok := s.decodeComment(w, r)
+	if !ok {
+		return
+	}
+	parent.ReplyCount++
+	s.data.Replies[parentId] = append(s.data.Replies[parentId], reply)
+	id, _ := strconv.Atoi(reply.Id)
+	writeJSON(w, map[string]any{"id": id, "hist_id": reply.Id, "date": reply.Date})
+}
+
+func (s *Server) handleUpdateComment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comment := s.findComment(r.PathValue("comment"))
+	if comment == nil {
+		writeError(w, http.StatusNotFound, "Comment not found", "OAUTH_057")
+		return
+	}
+	var body struct {
+		CommentText *string `json:"comment_text"`
+		Assignee    *int    `json:"assignee"`
+		Resolved    *bool   `json:"resolved"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	if body.CommentText != nil {
+		comment.CommentText = *body.CommentText
+		comment.Comment = []clients.CommentText{{Text: *body.CommentText}}
+	}
+	if body.Assignee != nil {
+		user := s.findUser(*body.Assignee)
+		comment.Assignee = &user
+	}
+	if body.Resolved != nil {
+		comment.Resolved = *body.Resolved
+	}
+	writeJSON(w, map[string]any{})
+}
+
+func (s *Server) handleAddReaction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comment := s.findComment(r.PathValue("comment"))
+	if comment == nil {
+		writeError(w, http.StatusNotFound, "Comment not found", "OAUTH_057")
+		return
+	}
+	var body struct {
+		Reaction string `json:"reaction"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Reaction == "" {
+		writeError(w, http.StatusBadRequest, "Reaction invalid", "INPUT_001")
+		return
+	}
+	comment.Reactions = append(comment.Reactions, clients.Reaction{
+		Reaction: body.Reaction,
+		Date:     strconv.FormatInt(time.Now().UnixMilli(), 10),
+		User:     s.data.User,
+	})
+	writeJSON(w, map[string]any{})
+}
+
+func (s *Server) handleRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comment := s.findComment(r.PathValue("comment"))
+	if comment == nil {
+		writeError(w, http.StatusNotFound, "Comment not found", "OAUTH_057")
+		return
+	}
+	reaction := r.PathValue("reaction")
+	comment.Reactions = slices.DeleteFunc(comment.Reactions, func(re clients.Reaction) bool {
+		return re.Reaction == reaction && re.User.IdString() == s.data.User.IdString()
+	})
+	writeJSON(w, map[string]any{})
+}
+
+// handleTimeEntries honours the start_date and end_date filters, in unix milliseconds.
+func (s *Server) handleTimeEntries(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from, _ := strconv.ParseInt(r.URL.Query().Get("start_date"), 10, 64)
+	to, _ := strconv.ParseInt(r.URL.Query().Get("end_date"), 10, 64)
+	entries := []clients.TimeEntry{}
+	for _, entry := range s.data.TimeEntries {
+		start, _ := strconv.ParseInt(entry.Start, 10, 64)
+		if (from > 0 && start < from) || (to > 0 && start >= to) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	writeJSON(w, clients.TsResponse{Data: entries})
+}
+
+func (s *Server) handleCreateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.taskIndex(r.PathValue("task"))
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	var body struct {
+		Start int64 `json:"start"`
+		Time  int64 `json:"time"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	task := s.data.Tasks[idx]
+	entry := clients.TimeEntry{
+		Id:       s.newId(),
+		Task:     map[string]interface{}{"id": task.Id, "name": task.Name},
+		Duration: strconv.FormatInt(body.Time, 10),
+		Start:    strconv.FormatInt(body.Start, 10),
+		End:      strconv.FormatInt(body.Start+body.Time, 10),
+	}
+	s.data.TimeEntries = append(s.data.TimeEntries, entry)
+	writeJSON(w, map[string]any{"data": entry})
+}
+
+func (s *Server) handleDeleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("entry")
+	idx := slices.IndexFunc(s.data.TimeEntries, func(e clients.TimeEntry) bool { return e.Id == id })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Time entry not found", "TIME_001")
+		return
+	}
+	s.data.TimeEntries = slices.Delete(s.data.TimeEntries, idx, idx+1)
+	writeJSON(w, map[string]any{})
+}
+
+func (s *Server) handleGetTimeEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("entry")
+	idx := slices.IndexFunc(s.data.TimeEntries, func(e clients.TimeEntry) bool { return e.Id == id })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Time entry not found", "TIME_001")
+		return
+	}
+	writeJSON(w, map[string]any{"data": s.data.TimeEntries[idx]})
+}
+
+func (s *Server) handleUpdateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("entry")
+	idx := slices.IndexFunc(s.data.TimeEntries, func(e clients.TimeEntry) bool { return e.Id == id })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Time entry not found", "TIME_001")
+		return
+	}
+	var body struct {
+		Start       *int64  `json:"start"`
+		End         *int64  `json:"end"`
+		Duration    *int64  `json:"duration"`
+		Description *string `json:"description"`
+		Billable    *bool   `json:"billable"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	entry := &s.data.TimeEntries[idx]
+	if body.Start != nil {
+		entry.Start = strconv.FormatInt(*body.Start, 10)
+	}
+	if body.Duration != nil {
+		entry.Duration = strconv.FormatInt(*body.Duration, 10)
+	}
+	if body.End != nil {
+		entry.End = strconv.FormatInt(*body.End, 10)
+	}
+	if body.Description != nil {
+		entry.Description = *body.Description
+	}
+	if body.Billable != nil {
+		entry.Billable = *body.Billable
+	}
+	writeJSON(w, map[string]any{"data": []clients.TimeEntry{*entry}})
+}
+
+func (s *Server) handleStartTimer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var body struct {
+		Tid string `json:"tid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "INPUT_001")
+		return
+	}
+	idx := s.taskIndex(body.Tid)
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "ITEM_013")
+		return
+	}
+	s.stopRunning()
+	start := time.Now().UnixMilli()
+	task := s.data.Tasks[idx]
+	s.running = &clients.TimeEntry{
+		Id:       s.newId(),
+		Task:     map[string]interface{}{"id": task.Id, "name": task.Name},
+		Start:    strconv.FormatInt(start, 10),
+		Duration: strconv.FormatInt(-start, 10),
+	}
+	writeJSON(w, map[string]any{"data": s.running})
+}
+
+func (s *Server) handleStopTimer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running == nil {
+		writeError(w, http.StatusBadRequest, "No timer running", "TIMER_002")
+		return
+	}
+	writeJSON(w, map[string]any{"data": s.stopRunning()})
+}
+
+func (s *Server) handleCurrentTimer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, map[string]any{"data": s.running})
+}
+
+// stopRunning turns the running timer, if any, into a regular time entry.
+func (s *Server) stopRunning() *clients.TimeEntry {
+	if s.running == nil {
+		return nil
+	}
+	entry := *s.running
+	start, _ := strconv.ParseInt(entry.Start, 10, 64)
+	end := time.Now().UnixMilli()
+	entry.Duration = strconv.FormatInt(end-start, 10)
+	entry.End = strconv.FormatInt(end, 10)
+	s.data.TimeEntries = append(s.data.TimeEntries, entry)
+	s.running = nil
+	return &entry
+}
+
+func writePage(w http.ResponseWriter, r *http.Request, tasks []clients.Task) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	start := min(page*pageSize, len(tasks))
+	end := min(start+pageSize, len(tasks))
+	writeJSON(w, clients.TaskResponse{Tasks: tasks[start:end], LastPage: end == len(tasks)})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf("encode: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"err": message, "ECODE": code})
+}
@@ -0,0 +1,37 @@
+package shared
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch reports whether the characters of pattern appear in text in the same order,
+// ignoring case, like "brd" in "Board". The score rewards consecutive characters and
+// matches at the start of words: the higher, the better the match.
+func FuzzyMatch(pattern string, text string) (int, bool) {
+	p := []rune(strings.ToLower(strings.TrimSpace(pattern)))
+	if len(p) == 0 {
+		return 0, true
+	}
+	t := []rune(text)
+	score, pi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if unicode.ToLower(t[ti]) != p[pi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 3
+		}
+		if ti == 0 || (!unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1])) {
+			score += 5
+		}
+		prev = ti
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	// Prefer shorter texts when the pattern matches equally well.
+	return score*100 - len(t), true
+}
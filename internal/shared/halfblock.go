@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// RenderHalfBlocks draws an image with "▀" characters in truecolor: the foreground of a cell
+// is its upper pixel and the background its lower one, so that a cell holds two square-ish
+// pixels. The image is scaled to fit in width columns and height rows, keeping its ratio.
+func RenderHalfBlocks(img image.Image, width int, height int) string {
+	b := img.Bounds()
+	if b.Empty() || width <= 0 || height <= 0 {
+		return ""
+	}
+	scale := min(float64(width)/float64(b.Dx()), float64(height*2)/float64(b.Dy()))
+	cols := max(int(float64(b.Dx())*scale), 1)
+	rows := max(int(float64(b.Dy())*scale), 1)
+
+	// sample averages the source pixels covered by a pixel of the scaled image.
+	sample := func(x int, y int) color.RGBA {
+		x0, x1 := b.Min.X+int(float64(x)/scale), b.Min.X+int(float64(x+1)/scale)
+		y0, y1 := b.Min.Y+int(float64(y)/scale), b.Min.Y+int(float64(y+1)/scale)
+		x1, y1 = min(max(x1, x0+1), b.Max.X), min(max(y1, y0+1), b.Max.Y)
+		var r, g, bl, n uint64
+		for sy := y0; sy < y1; sy++ {
+			for sx := x0; sx < x1; sx++ {
+				// Premultiplied values: transparent pixels are drawn over black.
+				pr, pg, pb, _ := img.At(sx, sy).RGBA()
+				r, g, bl, n = r+uint64(pr), g+uint64(pg), bl+uint64(pb), n+1
+			}
+		}
+		if n == 0 {
+			return color.RGBA{}
+		}
+		return color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: 0xff}
+	}
+
+	lines := make([]string, 0, (rows+1)/2)
+	for y := 0; y < rows; y += 2 {
+		var line strings.Builder
+		for x := 0; x < cols; x++ {
+			top := sample(x, y)
+			fmt.Fprintf(&line, "\x1b[38;2;%d;%d;%dm", top.R, top.G, top.B)
+			if y+1 < rows {
+				bottom := sample(x, y+1)
+				fmt.Fprintf(&line, "\x1b[48;2;%d;%d;%dm", bottom.R, bottom.G, bottom.B)
+			}
+			line.WriteString("▀")
+		}
+		line.WriteString("\x1b[0m")
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,41 @@
+package shared
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestRenderHalfBlocks(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if y < 10 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	// 40x20 pixels fit in 20 columns and 5 rows of two pixels.
+	out := RenderHalfBlocks(img, 20, 10)
+	lines := strings.Split(out, "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5", len(lines))
+	}
+	for _, line := range lines {
+		if w := lipgloss.Width(line); w != 20 {
+			t.Errorf("line width = %d, want 20", w)
+		}
+	}
+	if !strings.Contains(lines[0], "38;2;255;0;0m") || !strings.Contains(lines[4], "38;2;0;0;255m") {
+		t.Errorf("unexpected colors:\n%q", out)
+	}
+	if RenderHalfBlocks(img, 0, 10) != "" {
+		t.Error("rendered in no space")
+	}
+}
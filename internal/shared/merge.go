@@ -0,0 +1,103 @@
+package shared
+
+import "strings"
+
+// Merge3 merges two versions of a text edited from the same base, line by line, like diff3.
+// Changes made on one side only are applied; when both sides changed the same lines
+// differently the result contains both versions between conflict markers and ok is false.
+func Merge3(base string, mine string, theirs string) (merged string, ok bool) {
+	b, m, t := splitLines(base), splitLines(mine), splitLines(theirs)
+	bm, bt := matchLines(b, m), matchLines(b, t)
+
+	var out []string
+	ok = true
+	i, mi, ti := 0, 0, 0
+	for i < len(b) || mi < len(m) || ti < len(t) {
+		// Find the next base line kept by both sides.
+		j := i
+		for j < len(b) && (bm[j] < 0 || bt[j] < 0) {
+			j++
+		}
+		mEnd, tEnd := len(m), len(t)
+		if j < len(b) {
+			mEnd, tEnd = bm[j], bt[j]
+		}
+		if j == i && mEnd == mi && tEnd == ti {
+			if j == len(b) {
+				break
+			}
+			out = append(out, b[i])
+			i, mi, ti = i+1, mi+1, ti+1
+			continue
+		}
+		baseChunk, mineChunk, theirsChunk := b[i:j], m[mi:mEnd], t[ti:tEnd]
+		switch {
+		case equalLines(mineChunk, baseChunk):
+			out = append(out, theirsChunk...)
+		case equalLines(theirsChunk, baseChunk), equalLines(mineChunk, theirsChunk):
+			out = append(out, mineChunk...)
+		default:
+			ok = false
+			out = append(out, "<<<<<<< local")
+			out = append(out, mineChunk...)
+			out = append(out, "=======")
+			out = append(out, theirsChunk...)
+			out = append(out, ">>>>>>> clickup")
+		}
+		i, mi, ti = j, mEnd, tEnd
+	}
+	return strings.Join(out, "\n"), ok
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLines returns, for each line of a, the index of the same line in b along
+// their longest common subsequence, or -1 when the line was removed.
+func matchLines(a []string, b []string) []int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	match := make([]int, len(a))
+	i, j := 0, 0
+	for i < len(a) {
+		switch {
+		case j < len(b) && a[i] == b[j]:
+			match[i] = j
+			i, j = i+1, j+1
+		case j < len(b) && lcs[i][j+1] >= lcs[i+1][j]:
+			j++
+		default:
+			match[i] = -1
+			i++
+		}
+	}
+	return match
+}
@@ -0,0 +1,34 @@
+package shared
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	base := "# Title\n\nfirst\nsecond\nthird\n"
+	tests := []struct {
+		name   string
+		mine   string
+		theirs string
+		want   string
+		ok     bool
+	}{
+		{"only mine", "# Title\n\nfirst\nsecond!\nthird\n", base, "# Title\n\nfirst\nsecond!\nthird", true},
+		{"only theirs", base, "# Title\n\nfirst\nsecond\nthird\nfourth\n", "# Title\n\nfirst\nsecond\nthird\nfourth", true},
+		{"both, different lines", "# New title\n\nfirst\nsecond\nthird\n", "# Title\n\nfirst\nsecond\n", "# New title\n\nfirst\nsecond", true},
+		{"both, same change", "# Title\n\nfirst\n2nd\nthird\n", "# Title\n\nfirst\n2nd\nthird\n", "# Title\n\nfirst\n2nd\nthird", true},
+		{
+			"conflict",
+			"# Title\n\nfirst\nmine\nthird\n",
+			"# Title\n\nfirst\ntheirs\nthird\n",
+			"# Title\n\nfirst\n<<<<<<< local\nmine\n=======\ntheirs\n>>>>>>> clickup\nthird",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Merge3(base, tt.mine, tt.theirs)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Merge3() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -86,3 +86,55 @@ func hexToRGB(s string) (int, int, int) {
 	b, _ := strconv.ParseUint(s[4:], 16, 8)
 	return int(r), int(g), int(b)
 }
+
+// ParseHoursInput parses a duration typed by the user, either as decimal hours (1.5)
+// or with units (1h30m, 1h 30m, 45m), and returns it in hours.
+func ParseHoursInput(input string) (float64, error) {
+	var hours, minutes float64
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	if strings.Contains(input, "h") || strings.Contains(input, "m") {
+		parts := strings.Fields(input)
+		if len(parts) == 0 {
+			parts = []string{input}
+		}
+
+		for _, part := range parts {
+			if strings.Contains(part, "h") && strings.Contains(part, "m") {
+				hPart := strings.Split(part, "h")[0]
+				mPart := strings.Split(strings.Split(part, "h")[1], "m")[0]
+
+				h, err := strconv.ParseFloat(hPart, 64)
+				if err != nil {
+					return 0, err
+				}
+				hours = h
+
+				m, err := strconv.ParseFloat(mPart, 64)
+				if err != nil {
+					return 0, err
+				}
+				minutes = m
+				continue
+			}
+
+			if strings.HasSuffix(part, "h") {
+				h, err := strconv.ParseFloat(strings.TrimSuffix(part, "h"), 64)
+				if err != nil {
+					return 0, err
+				}
+				hours = h
+			}
+			if strings.HasSuffix(part, "m") {
+				m, err := strconv.ParseFloat(strings.TrimSuffix(part, "m"), 64)
+				if err != nil {
+					return 0, err
+				}
+				minutes = m
+			}
+		}
+		return hours + minutes/60.0, nil
+	}
+
+	return strconv.ParseFloat(input, 64)
+}
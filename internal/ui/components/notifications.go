@@ -0,0 +1,246 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+const maxNotifications = 50
+
+// notificationTimeout is how long an error without a retry action stays in the status bar.
+// It remains in the log afterwards.
+const notificationTimeout = 10 * time.Second
+
+type ErrorKind int
+
+const (
+	ErrorUnknown ErrorKind = iota
+	ErrorNetwork
+	ErrorAuth
+	ErrorRateLimit
+	ErrorNotFound
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorNetwork:
+		return "network"
+	case ErrorAuth:
+		return "auth"
+	case ErrorRateLimit:
+		return "rate limit"
+	case ErrorNotFound:
+		return "not found"
+	}
+	return "error"
+}
+
+// ClassifyError maps an error returned by the ClickUp client to an ErrorKind.
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ErrorUnknown
+	}
+	var apiErr *clients.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrorAuth
+		case http.StatusTooManyRequests:
+			return ErrorRateLimit
+		case http.StatusNotFound:
+			return ErrorNotFound
+		}
+		return ErrorUnknown
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorNetwork
+	}
+	return ErrorUnknown
+}
+
+// NotifyMsg asks the application to show an error to the user.
+// Retry, when set, is run again if the user chooses to retry.
+type NotifyMsg struct {
+	Source string
+	Err    error
+	Retry  tea.Cmd
+}
+
+// Notify returns a command that posts an error notification.
+func Notify(source string, err error, retry tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return NotifyMsg{Source: source, Err: err, Retry: retry}
+	}
+}
+
+type Notification struct {
+	id     int
+	Kind   ErrorKind
+	Source string
+	Err    error
+	Retry  tea.Cmd
+	Time   time.Time
+}
+
+func (n Notification) Message() string {
+	hint := ""
+	switch n.Kind {
+	case ErrorAuth:
+		hint = " (check your token in settings)"
+	case ErrorRateLimit:
+		hint = " (ClickUp rate limit reached, retry in a minute)"
+	case ErrorNetwork:
+		hint = " (network unavailable)"
+	}
+	return fmt.Sprintf("%s: %v%s", n.Source, n.Err, hint)
+}
+
+// Notifications holds the current toast and a log of recent errors.
+type Notifications struct {
+	log       []Notification
+	current   *Notification
+	showLog   bool
+	logOffset int
+	pushed    int
+}
+
+type notificationExpiredMsg struct {
+	id int
+}
+
+// Push shows a new notification. Errors of requests cancelled by the user are ignored.
+// The returned command hides the notification after notificationTimeout, unless it can be
+// retried: those stay until they are retried or dismissed.
+func (n *Notifications) Push(msg NotifyMsg) tea.Cmd {
+	if msg.Err == nil || clients.IsCanceled(msg.Err) {
+		return nil
+	}
+	n.pushed++
+	notification := Notification{
+		id:     n.pushed,
+		Kind:   ClassifyError(msg.Err),
+		Source: msg.Source,
+		Err:    msg.Err,
+		Retry:  msg.Retry,
+		Time:   time.Now(),
+	}
+	n.log = append([]Notification{notification}, n.log...)
+	if len(n.log) > maxNotifications {
+		n.log = n.log[:maxNotifications]
+	}
+	n.current = &notification
+	if msg.Retry != nil {
+		return nil
+	}
+	id := notification.id
+	return tea.Tick(notificationTimeout, func(time.Time) tea.Msg {
+		return notificationExpiredMsg{id: id}
+	})
+}
+
+// Update hides the notification whose time is up. It reports whether the message was handled.
+func (n *Notifications) Update(msg tea.Msg) (tea.Cmd, bool) {
+	if msg, ok := msg.(notificationExpiredMsg); ok {
+		if n.current != nil && n.current.id == msg.id {
+			n.current = nil
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+func (n *Notifications) Dismiss() {
+	n.current = nil
+}
+
+// Retry dismisses the current notification and returns its retry command, if any.
+func (n *Notifications) Retry() tea.Cmd {
+	if n.current == nil {
+		return nil
+	}
+	cmd := n.current.Retry
+	n.current = nil
+	return cmd
+}
+
+func (n Notifications) HasCurrent() bool {
+	return n.current != nil
+}
+
+func (n Notifications) LogVisible() bool {
+	return n.showLog
+}
+
+func (n *Notifications) ToggleLog() {
+	n.showLog = !n.showLog
+	n.logOffset = 0
+}
+
+// HandleLogKey handles navigation inside the error log.
+func (n *Notifications) HandleLogKey(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "esc", "q", "ctrl+e":
+		n.showLog = false
+	case "up", "k":
+		if n.logOffset > 0 {
+			n.logOffset--
+		}
+	case "down", "j":
+		if n.logOffset < len(n.log)-1 {
+			n.logOffset++
+		}
+	}
+}
+
+// StatusBar renders the one line status bar shown at the bottom of the screen.
+func (n Notifications) StatusBar(width int) string {
+	if n.current == nil {
+		return lipgloss.NewStyle().Width(width).Render("")
+	}
+	actions := "[ctrl+x] Dismiss  [ctrl+e] Log"
+	if n.current.Retry != nil {
+		actions = "[ctrl+r] Retry  " + actions
+	}
+	color := ui.Error
+	if n.current.Kind == ErrorRateLimit || n.current.Kind == ErrorNetwork {
+		color = ui.Warning
+	}
+	actionsView := lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(color).Padding(0, 1).Render(actions)
+	msgWidth := max(width-lipgloss.Width(actionsView), 0)
+	msgView := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#000000")).Background(color).Padding(0, 1).Width(msgWidth).MaxWidth(msgWidth).MaxHeight(1).Render("✖ " + n.current.Message())
+	return lipgloss.JoinHorizontal(lipgloss.Top, msgView, actionsView)
+}
+
+// LogView renders the scrollable list of recent errors.
+func (n Notifications) LogView(width, height int) string {
+	title := ui.TitleStyle.Render("Recent errors")
+	rowsHeight := max(height-6, 1)
+	var rows []string
+	if len(n.log) == 0 {
+		rows = append(rows, lipgloss.NewStyle().Foreground(ui.Subtle).Render("No errors"))
+	}
+	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	kindStyle := lipgloss.NewStyle().Foreground(ui.Error).Bold(true).Width(12)
+	for i := n.logOffset; i < len(n.log) && len(rows) < rowsHeight; i++ {
+		item := n.log[i]
+		line := lipgloss.JoinHorizontal(lipgloss.Top,
+			timeStyle.Render(item.Time.Format("15:04:05")+"  "),
+			kindStyle.Render(item.Kind.String()),
+			lipgloss.NewStyle().MaxWidth(width-30).Render(item.Message()),
+		)
+		rows = append(rows, line)
+	}
+	help := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("[↑ ↓] Scroll    [esc] Close")
+	content := lipgloss.JoinVertical(lipgloss.Left, title, "", lipgloss.JoinVertical(lipgloss.Left, rows...))
+	panel := ui.PanelStyle.Width(width - 4).Height(height - 4).Render(content)
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
@@ -0,0 +1,220 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+)
+
+func TestClassifyError(t *testing.T) {
+	srv := newTimerServer(t)
+	client := srv.Client()
+	getTask := func() error {
+		_, err := client.GetTask(context.Background(), "t1")
+		return err
+	}
+
+	srv.SetOffline(true)
+	offline := getTask()
+	srv.SetOffline(false)
+	srv.FailNext("GET /api/v2/task/t1", http.StatusUnauthorized, 1)
+	unauthorized := getTask()
+	srv.FailNext("GET /api/v2/task/t1", http.StatusBadRequest, 1)
+	rejected := getTask()
+	srv.FailNext("GET /api/v2/task/t1", http.StatusNotFound, 1)
+	missing := getTask()
+
+	tests := []struct {
+		name string
+		err  error
+		kind ErrorKind
+		text string
+	}{
+		{"offline", offline, ErrorNetwork, "(network unavailable)"},
+		{"unauthorized", unauthorized, ErrorAuth, "(check your token in settings)"},
+		{"forbidden", &clients.APIError{StatusCode: http.StatusForbidden, Status: "403 Forbidden"}, ErrorAuth, "(check your token in settings)"},
+		{"rate limited", &clients.APIError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}, ErrorRateLimit, "(ClickUp rate limit reached, retry in a minute)"},
+		{"not found", missing, ErrorNotFound, "404 Not Found"},
+		{"error code", rejected, ErrorUnknown, "Injected failure (TEST_001)"},
+		{"wrapped", fmt.Errorf("loading: %w", unauthorized), ErrorAuth, "loading: "},
+		{"other", errors.New("broken"), ErrorUnknown, "Loading: broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("expected an error from the client")
+			}
+			if kind := ClassifyError(tt.err); kind != tt.kind {
+				t.Errorf("expected %v, got %v for %v", tt.kind, kind, tt.err)
+			}
+			var n Notifications
+			n.Push(NotifyMsg{Source: "Loading", Err: tt.err})
+			if msg := n.current.Message(); !strings.Contains(msg, tt.text) {
+				t.Errorf("expected %q in %q", tt.text, msg)
+			}
+		})
+	}
+}
+
+func TestPushIgnoresCanceledRequests(t *testing.T) {
+	var n Notifications
+	if cmd := n.Push(NotifyMsg{Source: "Loading"}); cmd != nil || n.HasCurrent() {
+		t.Error("a notification without error should be ignored")
+	}
+	if cmd := n.Push(NotifyMsg{Source: "Loading", Err: fmt.Errorf("get: %w", context.Canceled)}); cmd != nil || n.HasCurrent() {
+		t.Error("a cancelled request should not be notified")
+	}
+	if len(n.log) != 0 {
+		t.Errorf("expected an empty log, got %d", len(n.log))
+	}
+}
+
+func TestRetryRunsTheCommandAgain(t *testing.T) {
+	srv := newTimerServer(t)
+	client := srv.Client()
+	type loadedMsg struct {
+		task clients.Task
+		err  error
+	}
+	load := func() tea.Msg {
+		task, err := client.GetTask(context.Background(), "t1")
+		return loadedMsg{task, err}
+	}
+
+	srv.FailNext("GET /api/v2/task/t1", http.StatusBadRequest, 1)
+	failed := load().(loadedMsg)
+	var n Notifications
+	if cmd := n.Push(NotifyMsg{Source: "Loading task", Err: failed.err, Retry: load}); cmd != nil {
+		t.Error("a notification that can be retried should not expire")
+	}
+	if !strings.Contains(n.StatusBar(200), "[ctrl+r] Retry") {
+		t.Errorf("expected the retry action in %q", n.StatusBar(200))
+	}
+
+	requests := len(srv.Requests())
+	cmd := n.Retry()
+	if n.HasCurrent() {
+		t.Error("retrying should dismiss the notification")
+	}
+	if cmd == nil {
+		t.Fatal("expected the retry command")
+	}
+	loaded := cmd().(loadedMsg)
+	if loaded.err != nil || loaded.task.Name != "First" {
+		t.Errorf("expected the task to be loaded again, got %+v", loaded)
+	}
+	if got := len(srv.Requests()) - requests; got != 1 {
+		t.Errorf("expected the request to be sent again, got %d requests", got)
+	}
+	if n.Retry() != nil {
+		t.Error("there is nothing left to retry")
+	}
+}
+
+func TestDismissKeepsTheLog(t *testing.T) {
+	var n Notifications
+	n.Push(NotifyMsg{Source: "Loading", Err: errors.New("broken")})
+	n.Dismiss()
+	if n.HasCurrent() {
+		t.Error("expected the notification to be dismissed")
+	}
+	if n.StatusBar(80) != strings.Repeat(" ", 80) {
+		t.Errorf("expected an empty status bar, got %q", n.StatusBar(80))
+	}
+	if len(n.log) != 1 {
+		t.Errorf("expected the error to stay in the log, got %d", len(n.log))
+	}
+}
+
+func TestNotificationsExpire(t *testing.T) {
+	var n Notifications
+	first := n.Push(NotifyMsg{Source: "First", Err: errors.New("broken")})
+	second := n.Push(NotifyMsg{Source: "Second", Err: errors.New("broken")})
+	if first == nil || second == nil {
+		t.Fatal("expected the notifications to expire")
+	}
+
+	// The first notification was replaced: its expiry leaves the second on screen.
+	if _, ok := n.Update(notificationExpiredMsg{id: 1}); !ok {
+		t.Fatal("expected the expiry to be handled")
+	}
+	if !n.HasCurrent() || n.current.Source != "Second" {
+		t.Fatalf("expected the second notification to stay, got %+v", n.current)
+	}
+	if _, ok := n.Update(notificationExpiredMsg{id: 2}); !ok || n.HasCurrent() {
+		t.Error("expected the second notification to expire")
+	}
+	if len(n.log) != 2 {
+		t.Errorf("expected both errors in the log, got %d", len(n.log))
+	}
+	if _, ok := n.Update(tea.KeyMsg{}); ok {
+		t.Error("other messages should not be handled")
+	}
+}
+
+func TestLogKeepsTheRecentErrors(t *testing.T) {
+	var n Notifications
+	for i := range maxNotifications + 5 {
+		n.Push(NotifyMsg{Source: fmt.Sprintf("Request %d", i), Err: errors.New("broken")})
+	}
+	if len(n.log) != maxNotifications {
+		t.Fatalf("expected the log to keep %d errors, got %d", maxNotifications, len(n.log))
+	}
+	if n.log[0].Source != fmt.Sprintf("Request %d", maxNotifications+4) || n.log[maxNotifications-1].Source != "Request 5" {
+		t.Errorf("expected the most recent errors first, got %q ... %q", n.log[0].Source, n.log[maxNotifications-1].Source)
+	}
+
+	key := func(k string) tea.KeyMsg {
+		switch k {
+		case "up":
+			return tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			return tea.KeyMsg{Type: tea.KeyDown}
+		case "esc":
+			return tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+	}
+	n.ToggleLog()
+	if !n.LogVisible() {
+		t.Fatal("expected the log to be open")
+	}
+	n.HandleLogKey(key("up"))
+	if n.logOffset != 0 {
+		t.Errorf("expected the log to stay at the top, got %d", n.logOffset)
+	}
+	n.HandleLogKey(key("down"))
+	n.HandleLogKey(key("j"))
+	if n.logOffset != 2 {
+		t.Errorf("expected the log to scroll down by 2, got %d", n.logOffset)
+	}
+	view := n.LogView(120, 20)
+	if strings.Contains(view, fmt.Sprintf("Request %d:", maxNotifications+4)) || !strings.Contains(view, fmt.Sprintf("Request %d:", maxNotifications+2)) {
+		t.Errorf("expected the log to start from the third error:\n%s", view)
+	}
+	n.HandleLogKey(key("k"))
+	if n.logOffset != 1 {
+		t.Errorf("expected the log to scroll up, got %d", n.logOffset)
+	}
+	for range maxNotifications + 5 {
+		n.HandleLogKey(key("down"))
+	}
+	if n.logOffset != maxNotifications-1 {
+		t.Errorf("expected the log to stop at the last error, got %d", n.logOffset)
+	}
+	n.HandleLogKey(key("esc"))
+	if n.LogVisible() {
+		t.Error("expected esc to close the log")
+	}
+	n.ToggleLog()
+	if n.logOffset != 0 {
+		t.Errorf("expected the log to open at the top, got %d", n.logOffset)
+	}
+}
@@ -0,0 +1,459 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type PickerNodeKind int
+
+const (
+	PickerTeam PickerNodeKind = iota
+	PickerSpace
+	PickerFolder
+	PickerList
+	PickerView
+)
+
+func (k PickerNodeKind) icon() string {
+	switch k {
+	case PickerTeam:
+		return "◆"
+	case PickerSpace:
+		return "■"
+	case PickerFolder:
+		return "▸"
+	case PickerList:
+		return "≡"
+	}
+	return "◉"
+}
+
+// PickerChoice is the view chosen in the picker, with the team it belongs to.
+type PickerChoice struct {
+	TeamId   string
+	TeamName string
+	ViewId   string
+	ViewName string
+}
+
+// PickedMsg is sent when the user chooses a view in a picker.
+type PickedMsg struct {
+	PickerId int
+	Choice   PickerChoice
+}
+
+// PickerCanceledMsg is sent when the user closes a picker without choosing.
+type PickerCanceledMsg struct {
+	PickerId int
+}
+
+type pickerLoadedMsg struct {
+	pickerId  int
+	node      int // -1 for the teams
+	children  []pickerNode
+	expandAll bool
+	err       error
+}
+
+type pickerNode struct {
+	kind     PickerNodeKind
+	id       string
+	name     string
+	detail   string
+	parent   int
+	depth    int
+	children []int
+	loaded   bool
+	loading  bool
+	expanded bool
+}
+
+var lastPickerId int64
+
+// Picker browses the ClickUp hierarchy (teams, spaces, folders, lists and their views)
+// as a tree loaded on demand, and lets the user choose a view. Typing filters the
+// loaded nodes with a fuzzy match.
+type Picker struct {
+	id      int
+	client  clients.ClickupAPI
+	token   string
+	nodes   []pickerNode
+	roots   []int
+	loading bool
+	cursor  int
+	filter  string
+	width   int
+	height  int
+}
+
+// NewPicker creates a picker that uses the given token, which may not be saved in the config yet.
+func NewPicker(client clients.ClickupAPI, token string) Picker {
+	return Picker{
+		id:      int(atomic.AddInt64(&lastPickerId, 1)),
+		client:  client,
+		token:   token,
+		loading: true,
+		width:   80,
+		height:  20,
+	}
+}
+
+func (p Picker) Id() int {
+	return p.id
+}
+
+// Init loads the teams.
+func (p Picker) Init() tea.Cmd {
+	return p.load(-1, false)
+}
+
+func (p *Picker) SetSize(width, height int) {
+	p.width, p.height = width, height
+}
+
+func (p Picker) load(node int, expandAll bool) tea.Cmd {
+	client, token, id := p.client, p.token, p.id
+	if node < 0 {
+		return func() tea.Msg {
+			teams, err := client.GetTeams(context.Background(), token)
+			var children []pickerNode
+			for _, team := range teams {
+				children = append(children, pickerNode{kind: PickerTeam, id: team.Id, name: team.Name})
+			}
+			return pickerLoadedMsg{pickerId: id, node: node, children: children, err: err}
+		}
+	}
+	parent := p.nodes[node]
+	return func() tea.Msg {
+		children, err := loadPickerChildren(client, token, parent)
+		return pickerLoadedMsg{pickerId: id, node: node, children: children, expandAll: expandAll, err: err}
+	}
+}
+
+// loadPickerChildren fetches the views of a node first, then the containers below it.
+func loadPickerChildren(client clients.ClickupAPI, token string, parent pickerNode) ([]pickerNode, error) {
+	ctx := context.Background()
+	var children []pickerNode
+	viewParent := map[PickerNodeKind]clients.ViewParent{
+		PickerTeam:   clients.ViewParentTeam,
+		PickerSpace:  clients.ViewParentSpace,
+		PickerFolder: clients.ViewParentFolder,
+		PickerList:   clients.ViewParentList,
+	}[parent.kind]
+	views, err := client.GetViews(ctx, token, viewParent, parent.id)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		children = append(children, pickerNode{kind: PickerView, id: view.Id, name: view.Name, detail: view.Type})
+	}
+
+	switch parent.kind {
+	case PickerTeam:
+		spaces, err := client.GetSpaces(ctx, token, parent.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, space := range spaces {
+			children = append(children, pickerNode{kind: PickerSpace, id: space.Id, name: space.Name})
+		}
+	case PickerSpace:
+		folders, err := client.GetFolders(ctx, token, parent.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, folder := range folders {
+			children = append(children, pickerNode{kind: PickerFolder, id: folder.Id, name: folder.Name})
+		}
+		lists, err := client.GetFolderlessLists(ctx, token, parent.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, list := range lists {
+			children = append(children, pickerNode{kind: PickerList, id: list.Id, name: list.Name})
+		}
+	case PickerFolder:
+		lists, err := client.GetLists(ctx, token, parent.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, list := range lists {
+			children = append(children, pickerNode{kind: PickerList, id: list.Id, name: list.Name})
+		}
+	}
+	return children, nil
+}
+
+func (p Picker) Update(msg tea.Msg) (Picker, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pickerLoadedMsg:
+		if msg.pickerId != p.id {
+			return p, nil
+		}
+		return p.handleLoaded(msg)
+	case tea.KeyMsg:
+		return p.handleKey(msg)
+	}
+	return p, nil
+}
+
+func (p Picker) handleLoaded(msg pickerLoadedMsg) (Picker, tea.Cmd) {
+	if msg.node < 0 {
+		p.loading = false
+	} else {
+		p.nodes[msg.node].loading = false
+	}
+	if msg.err != nil {
+		var retry tea.Cmd
+		if msg.node < 0 {
+			retry = p.load(msg.node, msg.expandAll)
+		}
+		return p, Notify("Loading workspace", msg.err, retry)
+	}
+
+	depth := 0
+	if msg.node >= 0 {
+		depth = p.nodes[msg.node].depth + 1
+	}
+	var ids []int
+	for _, child := range msg.children {
+		child.parent = msg.node
+		child.depth = depth
+		p.nodes = append(p.nodes, child)
+		ids = append(ids, len(p.nodes)-1)
+	}
+	if msg.node < 0 {
+		p.roots = ids
+		// With a single team there is nothing to choose, open it right away.
+		if len(ids) == 1 {
+			return p, p.expand(ids[0], false)
+		}
+		return p, nil
+	}
+	p.nodes[msg.node].children = ids
+	p.nodes[msg.node].loaded = true
+	p.nodes[msg.node].expanded = true
+	if !msg.expandAll {
+		return p, nil
+	}
+	var cmds []tea.Cmd
+	for _, id := range ids {
+		cmds = append(cmds, p.expand(id, true))
+	}
+	return p, tea.Batch(cmds...)
+}
+
+// expand opens a node, loading its children the first time.
+func (p *Picker) expand(node int, expandAll bool) tea.Cmd {
+	n := &p.nodes[node]
+	if n.kind == PickerView {
+		return nil
+	}
+	if n.loaded {
+		n.expanded = true
+		if expandAll {
+			var cmds []tea.Cmd
+			for _, id := range n.children {
+				cmds = append(cmds, p.expand(id, true))
+			}
+			return tea.Batch(cmds...)
+		}
+		return nil
+	}
+	if n.loading {
+		return nil
+	}
+	n.loading = true
+	return p.load(node, expandAll)
+}
+
+// visible returns the nodes shown in the tree, in order. Without a filter these are the
+// expanded branches; with a filter, the loaded nodes that match and their ancestors.
+func (p Picker) visible() []int {
+	var out []int
+	if p.filter == "" {
+		var walk func(ids []int)
+		walk = func(ids []int) {
+			for _, id := range ids {
+				out = append(out, id)
+				if p.nodes[id].expanded {
+					walk(p.nodes[id].children)
+				}
+			}
+		}
+		walk(p.roots)
+		return out
+	}
+	keep := make([]bool, len(p.nodes))
+	for i, n := range p.nodes {
+		if _, ok := shared.FuzzyMatch(p.filter, n.name); ok {
+			for id := i; id >= 0 && !keep[id]; id = p.nodes[id].parent {
+				keep[id] = true
+			}
+		}
+	}
+	var walk func(ids []int)
+	walk = func(ids []int) {
+		for _, id := range ids {
+			if keep[id] {
+				out = append(out, id)
+				walk(p.nodes[id].children)
+			}
+		}
+	}
+	walk(p.roots)
+	return out
+}
+
+// bestMatch moves the cursor on the node that matches the filter best.
+func (p *Picker) bestMatch() {
+	best, bestScore := -1, 0
+	for i, id := range p.visible() {
+		if score, ok := shared.FuzzyMatch(p.filter, p.nodes[id].name); ok && (best < 0 || score > bestScore) {
+			best, bestScore = i, score
+		}
+	}
+	p.cursor = max(best, 0)
+}
+
+func (p Picker) selected() int {
+	visible := p.visible()
+	if p.cursor < 0 || p.cursor >= len(visible) {
+		return -1
+	}
+	return visible[p.cursor]
+}
+
+func (p Picker) choice(node int) PickerChoice {
+	choice := PickerChoice{ViewId: p.nodes[node].id, ViewName: p.nodes[node].name}
+	for id := node; id >= 0; id = p.nodes[id].parent {
+		if p.nodes[id].kind == PickerTeam {
+			choice.TeamId = p.nodes[id].id
+			choice.TeamName = p.nodes[id].name
+		}
+	}
+	return choice
+}
+
+func (p Picker) handleKey(msg tea.KeyMsg) (Picker, tea.Cmd) {
+	id := p.id
+	switch msg.String() {
+	case "esc":
+		if p.filter != "" {
+			p.filter = ""
+			p.cursor = 0
+			return p, nil
+		}
+		return p, func() tea.Msg { return PickerCanceledMsg{PickerId: id} }
+	case "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down":
+		if p.cursor < len(p.visible())-1 {
+			p.cursor++
+		}
+	case "right":
+		if node := p.selected(); node >= 0 {
+			return p, p.expand(node, false)
+		}
+	case "left":
+		if node := p.selected(); node >= 0 {
+			if p.nodes[node].expanded {
+				p.nodes[node].expanded = false
+			} else if parent := p.nodes[node].parent; parent >= 0 {
+				p.cursor = max(slices.Index(p.visible(), parent), 0)
+			}
+		}
+	case "ctrl+a":
+		if node := p.selected(); node >= 0 {
+			return p, p.expand(node, true)
+		}
+	case "enter":
+		node := p.selected()
+		if node < 0 {
+			return p, nil
+		}
+		if p.nodes[node].kind == PickerView {
+			choice := p.choice(node)
+			return p, func() tea.Msg { return PickedMsg{PickerId: id, Choice: choice} }
+		}
+		if p.nodes[node].expanded {
+			p.nodes[node].expanded = false
+			return p, nil
+		}
+		return p, p.expand(node, false)
+	case "backspace":
+		if p.filter != "" {
+			p.filter = string([]rune(p.filter)[:len([]rune(p.filter))-1])
+			p.bestMatch()
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			p.filter += string(msg.Runes)
+			p.bestMatch()
+		}
+	}
+	return p, nil
+}
+
+func (p Picker) View() string {
+	title := ui.TitleStyle.Render("Choose a view")
+	filter := lipgloss.NewStyle().Foreground(ui.Subtle).Render("Type to filter")
+	if p.filter != "" {
+		filter = "Filter: " + lipgloss.NewStyle().Foreground(ui.Highlight).Render(p.filter)
+	}
+
+	visible := p.visible()
+	rowsHeight := max(p.height-8, 1)
+	offset := 0
+	if p.cursor >= rowsHeight {
+		offset = p.cursor - rowsHeight + 1
+	}
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	var rows []string
+	if p.loading {
+		rows = append(rows, dimStyle.Render("Loading teams..."))
+	} else if len(visible) == 0 {
+		rows = append(rows, dimStyle.Render("Nothing found"))
+	}
+	for i := offset; i < len(visible) && i < offset+rowsHeight; i++ {
+		n := p.nodes[visible[i]]
+		marker := " "
+		switch {
+		case n.loading:
+			marker = "…"
+		case n.kind == PickerView:
+		case n.expanded:
+			marker = "▾"
+		default:
+			marker = "▸"
+		}
+		line := fmt.Sprintf("%s%s %s %s", strings.Repeat("  ", n.depth), marker, n.kind.icon(), n.name)
+		if n.detail != "" {
+			line += dimStyle.Render(" (" + n.detail + ")")
+		}
+		if i == p.cursor {
+			line = cursorStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(p.width-6).Render(line))
+	}
+
+	help := dimStyle.Render("[↑ ↓] Move   [→/←] Open/close   [ctrl+a] Open all below   [enter] Choose view   [esc] Clear filter/cancel")
+	content := lipgloss.JoinVertical(lipgloss.Left, title, filter, "", lipgloss.JoinVertical(lipgloss.Left, rows...))
+	panel := ui.PanelStyle.Width(p.width - 4).Height(max(p.height-4, 1)).Render(content)
+	return lipgloss.JoinVertical(lipgloss.Left, panel, help)
+}
@@ -0,0 +1,167 @@
+package components
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+// SwitchProfileMsg asks the application to reload everything with another profile.
+type SwitchProfileMsg struct {
+	Name string
+}
+
+// ConfigChangedMsg asks the application to rebuild the client and the views,
+// for example after the user chose a view in another team.
+type ConfigChangedMsg struct{}
+
+// ProfileSwitcher is the overlay used to switch, create and delete profiles.
+type ProfileSwitcher struct {
+	visible       bool
+	names         []string
+	current       string
+	cursor        int
+	creating      bool
+	input         textinput.Model
+	confirmDelete bool
+}
+
+func (p *ProfileSwitcher) Visible() bool {
+	return p.visible
+}
+
+// Open shows the overlay with the profiles currently saved in the config file.
+func (p *ProfileSwitcher) Open() {
+	p.visible = true
+	p.creating = false
+	p.confirmDelete = false
+	p.reload()
+}
+
+func (p *ProfileSwitcher) Close() {
+	p.visible = false
+	p.creating = false
+	p.input.Blur()
+}
+
+func (p *ProfileSwitcher) reload() {
+	p.names = clients.Profiles()
+	p.current = clients.GetConfig().Profile
+	p.cursor = 0
+	for i, name := range p.names {
+		if name == p.current {
+			p.cursor = i
+		}
+	}
+}
+
+func (p *ProfileSwitcher) selectName(name string) {
+	for i, n := range p.names {
+		if n == name {
+			p.cursor = i
+		}
+	}
+}
+
+// HandleKey handles the keys pressed while the overlay is visible.
+func (p *ProfileSwitcher) HandleKey(msg tea.KeyMsg) tea.Cmd {
+	if p.creating {
+		return p.handleCreateKey(msg)
+	}
+	key := msg.String()
+	if key != "d" {
+		p.confirmDelete = false
+	}
+	switch key {
+	case "esc", "q", "ctrl+p":
+		p.Close()
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.names)-1 {
+			p.cursor++
+		}
+	case "n":
+		p.creating = true
+		p.input = textinput.New()
+		p.input.Placeholder = "profile name"
+		p.input.CharLimit = 40
+		p.input.Focus()
+	case "d":
+		name := p.names[p.cursor]
+		if !p.confirmDelete {
+			p.confirmDelete = true
+			return nil
+		}
+		p.confirmDelete = false
+		if err := clients.DeleteProfile(name); err != nil {
+			return Notify("Deleting profile", err, nil)
+		}
+		p.reload()
+	case "enter":
+		name := p.names[p.cursor]
+		p.Close()
+		if name == p.current {
+			return nil
+		}
+		return func() tea.Msg { return SwitchProfileMsg{Name: name} }
+	}
+	return nil
+}
+
+func (p *ProfileSwitcher) handleCreateKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEscape:
+		p.creating = false
+		return nil
+	case tea.KeyEnter:
+		name := p.input.Value()
+		if err := clients.CreateProfile(name); err != nil {
+			return Notify("Creating profile", err, nil)
+		}
+		p.creating = false
+		p.reload()
+		p.selectName(name)
+		return nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+// View renders the list of profiles, marking the active one.
+func (p *ProfileSwitcher) View(width, height int) string {
+	title := ui.TitleStyle.Render("Profiles")
+	activeStyle := lipgloss.NewStyle().Foreground(ui.Special)
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+
+	var rows []string
+	for i, name := range p.names {
+		line := "  " + name
+		if name == p.current {
+			line += activeStyle.Render("  (active)")
+		}
+		if i == p.cursor {
+			line = cursorStyle.Render("> ") + line[2:]
+		}
+		rows = append(rows, line)
+	}
+	content := []string{title, "", lipgloss.JoinVertical(lipgloss.Left, rows...)}
+	if p.creating {
+		content = append(content, "", "New profile: "+p.input.View())
+	}
+	if p.confirmDelete {
+		content = append(content, "", lipgloss.NewStyle().Foreground(ui.Error).Bold(true).Render("Press [d] again to delete the profile and its cache"))
+	}
+
+	help := "[↑ ↓] Select    [enter] Switch    [n] New    [d d] Delete    [esc] Close"
+	if p.creating {
+		help = "[enter] Create    [esc] Cancel"
+	}
+	panel := ui.PanelStyle.Width(width - 4).Height(height - 4).Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+	return lipgloss.JoinVertical(lipgloss.Left, panel, lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(help))
+}
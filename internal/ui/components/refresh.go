@@ -0,0 +1,57 @@
+package components
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RefreshMsg is sent to the current view every refresh interval, so that it fetches its
+// data again in the background.
+type RefreshMsg struct{}
+
+type refreshTickMsg struct {
+	id int
+}
+
+// refreshTicks numbers the tick loops, so that the loop of an application that has been
+// rebuilt, after a profile switch, stops.
+var refreshTicks int
+
+// AutoRefresh sends RefreshMsg at a fixed interval.
+type AutoRefresh struct {
+	interval time.Duration
+	tickId   int
+}
+
+// NewAutoRefresh creates a refresh every given number of seconds, 0 disables it.
+func NewAutoRefresh(seconds int) *AutoRefresh {
+	return &AutoRefresh{interval: time.Duration(seconds) * time.Second}
+}
+
+// Init starts the tick loop.
+func (r *AutoRefresh) Init() tea.Cmd {
+	if r.interval <= 0 {
+		return nil
+	}
+	refreshTicks++
+	r.tickId = refreshTicks
+	return r.tick()
+}
+
+func (r *AutoRefresh) tick() tea.Cmd {
+	id := r.tickId
+	return tea.Tick(r.interval, func(time.Time) tea.Msg { return refreshTickMsg{id: id} })
+}
+
+// Update handles the ticks. It reports false for messages that belong to someone else.
+func (r *AutoRefresh) Update(msg tea.Msg) (tea.Cmd, bool) {
+	tick, ok := msg.(refreshTickMsg)
+	if !ok {
+		return nil, false
+	}
+	if tick.id != r.tickId {
+		return nil, true
+	}
+	return tea.Batch(func() tea.Msg { return RefreshMsg{} }, r.tick()), true
+}
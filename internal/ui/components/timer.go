@@ -0,0 +1,254 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+// ToggleTimerMsg asks the application to start a timer on a task,
+// or to stop it when the timer is already running on that task.
+type ToggleTimerMsg struct {
+	TaskId   string
+	TaskName string
+}
+
+// ToggleTimer returns a command that toggles the timer on a task.
+func ToggleTimer(taskId string, taskName string) tea.Cmd {
+	return func() tea.Msg {
+		return ToggleTimerMsg{TaskId: taskId, TaskName: taskName}
+	}
+}
+
+// TimerStoppedMsg is sent to every view after a timer has been stopped,
+// so that they can refresh the tracked time.
+type TimerStoppedMsg struct {
+	TaskId string
+}
+
+type runningTimerMsg struct {
+	entry *clients.TimeEntry
+	poll  bool // the timer was polled in the background
+	err   error
+}
+
+type timerStartedMsg struct {
+	entry    clients.TimeEntry
+	previous string
+	err      error
+}
+
+type timerStoppedMsg struct {
+	taskId string
+	err    error
+}
+
+type timerTickMsg struct {
+	id int
+}
+
+type timerPollMsg struct {
+	id int
+}
+
+// timerPollInterval is how often the running timer is read again from ClickUp, to follow
+// the timers started and stopped in the ClickUp app or from the command line.
+const timerPollInterval = 30 * time.Second
+
+// timerTicks and timerPolls number the tick and poll loops of every timer, so that the
+// loops of a timer replaced when the application is rebuilt, after a profile switch, stop.
+var timerTicks, timerPolls int
+
+// Timer tracks the ClickUp timer of the current user and renders it as a header.
+type Timer struct {
+	client   clients.ClickupAPI
+	taskId   string
+	taskName string
+	start    time.Time
+	now      time.Time
+	pending  bool
+	tickId   int
+	pollId   int
+}
+
+func NewTimer(client clients.ClickupAPI) *Timer {
+	return &Timer{client: client}
+}
+
+func (t *Timer) Running() bool {
+	return t.taskId != ""
+}
+
+// Init loads the timer that may already be running on ClickUp.
+func (t *Timer) Init() tea.Cmd {
+	return t.load(false)
+}
+
+func (t *Timer) load(poll bool) tea.Cmd {
+	client := t.client
+	return func() tea.Msg {
+		entry, err := client.GetRunningTimer(context.Background())
+		return runningTimerMsg{entry: entry, poll: poll, err: err}
+	}
+}
+
+// Update handles the timer messages. It reports false for messages that belong to someone else.
+func (t *Timer) Update(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case ToggleTimerMsg:
+		return t.toggle(msg), true
+	case runningTimerMsg:
+		return tea.Batch(t.handleRunningTimer(msg), t.poll()), true
+	case timerStartedMsg:
+		t.pending = false
+		if msg.err != nil {
+			return Notify("Starting timer", msg.err, nil), true
+		}
+		t.set(msg.entry)
+		if msg.previous != "" {
+			return tea.Batch(t.tick(), func() tea.Msg { return TimerStoppedMsg{TaskId: msg.previous} }), true
+		}
+		return t.tick(), true
+	case timerStoppedMsg:
+		t.pending = false
+		if msg.err != nil {
+			return Notify("Stopping timer", msg.err, nil), true
+		}
+		t.clear()
+		return func() tea.Msg { return TimerStoppedMsg{TaskId: msg.taskId} }, true
+	case timerTickMsg:
+		if msg.id != t.tickId || !t.Running() {
+			return nil, true
+		}
+		t.now = time.Now()
+		return t.scheduleTick(), true
+	case timerPollMsg:
+		if msg.id != t.pollId {
+			return nil, true
+		}
+		return t.load(true), true
+	}
+	return nil, false
+}
+
+// handleRunningTimer follows the timer running on ClickUp. A timer stopped or replaced
+// elsewhere is reported to the views like one stopped here. Errors of the background
+// polls are not shown: the next poll tries again.
+func (t *Timer) handleRunningTimer(msg runningTimerMsg) tea.Cmd {
+	if msg.err != nil {
+		if msg.poll {
+			return nil
+		}
+		return Notify("Loading running timer", msg.err, t.Init())
+	}
+	if t.pending {
+		// A start or a stop is on its way, its answer is more recent.
+		return nil
+	}
+	previous := t.taskId
+	if msg.entry == nil {
+		t.clear()
+	} else {
+		t.set(*msg.entry)
+	}
+	var cmds []tea.Cmd
+	if previous != "" && previous != t.taskId {
+		cmds = append(cmds, func() tea.Msg {
+			// The stopped timer is now a regular time entry.
+			clients.ClearTimeentriesCache()
+			return TimerStoppedMsg{TaskId: previous}
+		})
+	}
+	if t.Running() && previous != t.taskId {
+		cmds = append(cmds, t.tick())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (t *Timer) toggle(msg ToggleTimerMsg) tea.Cmd {
+	if t.pending {
+		return nil
+	}
+	t.pending = true
+	client := t.client
+	if t.taskId == msg.TaskId {
+		taskId := t.taskId
+		return func() tea.Msg {
+			_, err := client.StopTimer(context.Background())
+			return timerStoppedMsg{taskId: taskId, err: err}
+		}
+	}
+	previous := t.taskId
+	return func() tea.Msg {
+		entry, err := client.StartTimer(context.Background(), msg.TaskId)
+		if err == nil && entry.TaskName() == "" {
+			entry.Task = map[string]interface{}{"id": msg.TaskId, "name": msg.TaskName}
+		}
+		if err == nil && previous != "" {
+			// Starting a timer stops the previous one, which is now a regular time entry.
+			clients.ClearTimeentriesCache()
+		}
+		return timerStartedMsg{entry: entry, previous: previous, err: err}
+	}
+}
+
+func (t *Timer) set(entry clients.TimeEntry) {
+	t.taskId = entry.TaskId()
+	t.taskName = entry.TaskName()
+	t.start = shared.ToDate(entry.Start)
+	t.now = time.Now()
+}
+
+func (t *Timer) clear() {
+	t.taskId = ""
+	t.taskName = ""
+}
+
+// tick starts a new tick loop, invalidating the previous one.
+func (t *Timer) tick() tea.Cmd {
+	timerTicks++
+	t.tickId = timerTicks
+	return t.scheduleTick()
+}
+
+// poll schedules the next read of the running timer, invalidating the previous loop.
+func (t *Timer) poll() tea.Cmd {
+	timerPolls++
+	t.pollId = timerPolls
+	id := t.pollId
+	return tea.Tick(timerPollInterval, func(time.Time) tea.Msg {
+		return timerPollMsg{id: id}
+	})
+}
+
+func (t *Timer) scheduleTick() tea.Cmd {
+	id := t.tickId
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return timerTickMsg{id: id}
+	})
+}
+
+func formatElapsed(d time.Duration) string {
+	d = max(d, 0).Truncate(time.Second)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
+
+// View renders the one line header with the running timer.
+func (t *Timer) View(width int) string {
+	style := lipgloss.NewStyle().Width(width).MaxWidth(width).MaxHeight(1).Padding(0, 1)
+	if !t.Running() {
+		return style.Foreground(lipgloss.Color("#666666")).Render("⏱  No timer running")
+	}
+	elapsed := lipgloss.NewStyle().Bold(true).Foreground(ui.Special).Render(formatElapsed(t.now.Sub(t.start)))
+	task := lipgloss.NewStyle().Foreground(ui.Highlight).Render(t.taskName)
+	return style.Render(lipgloss.NewStyle().Foreground(ui.Error).Render("●") + " " + elapsed + "  " + task)
+}
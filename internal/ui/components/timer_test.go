@@ -0,0 +1,150 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/fakeclickup"
+)
+
+func newTimerServer(t *testing.T) *fakeclickup.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	srv := fakeclickup.New(fakeclickup.Fixtures{Tasks: []clients.Task{{Id: "t1", Name: "First"}, {Id: "t2", Name: "Second"}}})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// messages runs a command and its batched commands, and returns the messages they produce
+// right away. Ticks and polls, which wait, are left out.
+func messages(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			var out []tea.Msg
+			for _, cmd := range batch {
+				out = append(out, messages(cmd)...)
+			}
+			return out
+		}
+		return []tea.Msg{msg}
+	case <-time.After(200 * time.Millisecond):
+		return nil
+	}
+}
+
+// deliver sends a message to the timer, then the messages of its commands, and returns
+// the messages that belong to someone else.
+func deliver(timer *Timer, msg tea.Msg) []tea.Msg {
+	var others []tea.Msg
+	queue := []tea.Msg{msg}
+	for len(queue) > 0 {
+		msg, queue = queue[0], queue[1:]
+		cmd, ok := timer.Update(msg)
+		if !ok {
+			others = append(others, msg)
+			continue
+		}
+		queue = append(queue, messages(cmd)...)
+	}
+	return others
+}
+
+func TestTimerStartsAndStops(t *testing.T) {
+	srv := newTimerServer(t)
+	timer := NewTimer(srv.Client())
+	deliver(timer, timer.Init()())
+	if timer.Running() {
+		t.Fatal("timer running before it was started")
+	}
+
+	deliver(timer, ToggleTimerMsg{TaskId: "t1", TaskName: "First"})
+	if !timer.Running() || timer.taskId != "t1" || timer.taskName != "First" {
+		t.Fatalf("timer not started: %+v", timer)
+	}
+	// Starting another task stops the first one.
+	if others := deliver(timer, ToggleTimerMsg{TaskId: "t2", TaskName: "Second"}); len(others) != 1 || others[0] != (TimerStoppedMsg{TaskId: "t1"}) {
+		t.Errorf("messages after switching task %v", others)
+	}
+	if others := deliver(timer, ToggleTimerMsg{TaskId: "t2"}); len(others) != 1 || others[0] != (TimerStoppedMsg{TaskId: "t2"}) {
+		t.Errorf("messages after stopping %v", others)
+	}
+	if timer.Running() || len(srv.TimeEntries()) != 2 {
+		t.Errorf("timer not stopped: %+v, entries %+v", timer, srv.TimeEntries())
+	}
+}
+
+func TestTimerFollowsTheTimersStartedElsewhere(t *testing.T) {
+	srv := newTimerServer(t)
+	timer := NewTimer(srv.Client())
+	deliver(timer, timer.Init()())
+	elsewhere := srv.Client()
+	ctx := context.Background()
+
+	if _, err := elsewhere.StartTimer(ctx, "t1"); err != nil {
+		t.Fatal(err)
+	}
+	deliver(timer, timerPollMsg{id: timer.pollId})
+	if timer.taskId != "t1" {
+		t.Fatalf("timer started in ClickUp not seen: %+v", timer)
+	}
+
+	if _, err := elsewhere.StopTimer(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stale := timer.pollId
+	if others := deliver(timer, timerPollMsg{id: timer.pollId}); timer.Running() || len(others) != 1 || others[0] != (TimerStoppedMsg{TaskId: "t1"}) {
+		t.Errorf("timer stopped in ClickUp: running %v, messages %v", timer.Running(), others)
+	}
+
+	// The poll loop that was replaced stops.
+	before := len(srv.Requests())
+	deliver(timer, timerPollMsg{id: stale})
+	if len(srv.Requests()) != before {
+		t.Errorf("stale poll read the timer: %v", srv.Requests()[before:])
+	}
+
+	// A failing poll is not reported, the next one tries again.
+	srv.SetOffline(true)
+	if others := deliver(timer, timerPollMsg{id: timer.pollId}); len(others) != 0 {
+		t.Errorf("failing poll reported: %v", others)
+	}
+	if timer.pollId == stale {
+		t.Error("no poll after the failure")
+	}
+}
+
+func TestTimerTicksAndIgnoresStaleTicks(t *testing.T) {
+	srv := newTimerServer(t)
+	timer := NewTimer(srv.Client())
+	deliver(timer, ToggleTimerMsg{TaskId: "t1", TaskName: "First"})
+	timer.now = timer.start
+
+	cmd, ok := timer.Update(timerTickMsg{id: timer.tickId})
+	if !ok || cmd == nil || !timer.now.After(timer.start) {
+		t.Errorf("tick not handled: now %s, start %s", timer.now, timer.start)
+	}
+
+	// After a rebuild, the ticks of the old timer do not drive the new one.
+	old := timer.tickId
+	rebuilt := NewTimer(srv.Client())
+	deliver(rebuilt, rebuilt.Init()())
+	if rebuilt.tickId == old {
+		t.Fatalf("the new timer reuses tick %d", old)
+	}
+	if cmd, _ := rebuilt.Update(timerTickMsg{id: old}); cmd != nil {
+		t.Error("stale tick scheduled another one")
+	}
+	if cmd, _ := timer.Update(timerTickMsg{id: rebuilt.tickId}); cmd != nil {
+		t.Error("tick of the new timer drove the old one")
+	}
+}
@@ -0,0 +1,286 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+// replayInterval is how often the queued writes are tried again while ClickUp cannot be reached.
+const replayInterval = 30 * time.Second
+
+// QueueChangedMsg is sent to every view after queued writes were sent to ClickUp or
+// discarded, so that they can load the data again.
+type QueueChangedMsg struct{}
+
+type replayTickMsg struct{}
+
+type writesReplayedMsg struct {
+	sent int
+	err  error
+}
+
+// WriteQueue sends the changes made offline when ClickUp can be reached again, shows
+// them in the header and lets the user review the ones in conflict in an overlay.
+type WriteQueue struct {
+	client         clients.ClickupAPI
+	writes         []clients.QueuedWrite
+	offline        bool
+	replaying      bool
+	scheduled      bool
+	visible        bool
+	cursor         int
+	confirmDiscard bool
+}
+
+func NewWriteQueue(client clients.ClickupAPI) *WriteQueue {
+	return &WriteQueue{client: client}
+}
+
+func (q *WriteQueue) count(state clients.WriteState) int {
+	n := 0
+	for _, w := range q.writes {
+		if w.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Watch is called after every update: it schedules a replay when writes are waiting,
+// right away when ClickUp answers and every replayInterval while it does not.
+func (q *WriteQueue) Watch() tea.Cmd {
+	q.writes = q.client.QueuedWrites()
+	q.offline = q.client.Offline()
+	q.cursor = min(q.cursor, max(len(q.writes)-1, 0))
+	if q.replaying || q.scheduled || q.count(clients.WritePending) == 0 {
+		return nil
+	}
+	if !q.offline {
+		return q.replay()
+	}
+	return q.tick()
+}
+
+func (q *WriteQueue) tick() tea.Cmd {
+	q.scheduled = true
+	return tea.Tick(replayInterval, func(time.Time) tea.Msg { return replayTickMsg{} })
+}
+
+func (q *WriteQueue) replay() tea.Cmd {
+	q.replaying = true
+	client := q.client
+	return func() tea.Msg {
+		sent, err := client.ReplayWrites(context.Background())
+		return writesReplayedMsg{sent: sent, err: err}
+	}
+}
+
+// Update handles the queue messages. It reports false for messages that belong to someone else.
+func (q *WriteQueue) Update(msg tea.Msg) (tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case replayTickMsg:
+		q.scheduled = false
+		if q.replaying || q.count(clients.WritePending) == 0 {
+			return nil, true
+		}
+		return q.replay(), true
+	case writesReplayedMsg:
+		q.replaying = false
+		before := q.count(clients.WriteConflict) + q.count(clients.WriteFailed)
+		q.writes = q.client.QueuedWrites()
+		var cmds []tea.Cmd
+		if msg.sent > 0 {
+			cmds = append(cmds, func() tea.Msg { return QueueChangedMsg{} })
+		}
+		if after := q.count(clients.WriteConflict) + q.count(clients.WriteFailed); after > before {
+			err := fmt.Errorf("%d offline changes could not be applied, press ctrl+q to review them", after-before)
+			cmds = append(cmds, Notify("Sending offline changes", err, nil))
+		}
+		if msg.err != nil {
+			// Try again later rather than right away, even if the error is not about the connection.
+			cmds = append(cmds, q.tick())
+			if !clients.IsOffline(msg.err) {
+				cmds = append(cmds, Notify("Sending offline changes", msg.err, nil))
+			}
+		}
+		return tea.Batch(cmds...), true
+	}
+	return nil, false
+}
+
+// HeaderView renders the state of the connection and of the queue, or nothing when all is sent.
+func (q *WriteQueue) HeaderView() string {
+	var parts []string
+	if q.offline {
+		parts = append(parts, "offline")
+	}
+	if n := q.count(clients.WritePending); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d queued", n))
+	}
+	style := lipgloss.NewStyle().Foreground(ui.Warning).Padding(0, 1)
+	if n := q.count(clients.WriteConflict) + q.count(clients.WriteFailed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d to review", n))
+		style = style.Foreground(ui.Error)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return style.Render("⇅ " + strings.Join(parts, " · "))
+}
+
+func (q *WriteQueue) Visible() bool {
+	return q.visible
+}
+
+func (q *WriteQueue) Open() {
+	q.visible = true
+	q.confirmDiscard = false
+	q.writes = q.client.QueuedWrites()
+}
+
+func (q *WriteQueue) Close() {
+	q.visible = false
+}
+
+// HandleKey handles the keys pressed while the overlay is visible.
+func (q *WriteQueue) HandleKey(msg tea.KeyMsg) tea.Cmd {
+	key := msg.String()
+	if key != "d" {
+		q.confirmDiscard = false
+	}
+	var selected clients.QueuedWrite
+	ok := q.cursor < len(q.writes)
+	if ok {
+		selected = q.writes[q.cursor]
+	}
+	switch key {
+	case "esc", "q", "ctrl+q":
+		q.Close()
+	case "up", "k":
+		if q.cursor > 0 {
+			q.cursor--
+		}
+	case "down", "j":
+		if q.cursor < len(q.writes)-1 {
+			q.cursor++
+		}
+	case "s":
+		if !q.replaying {
+			return q.replay()
+		}
+	case "r", "o":
+		if !ok || selected.State == clients.WritePending {
+			return nil
+		}
+		if err := q.client.RetryWrite(selected.Id, key == "o"); err != nil {
+			return Notify("Retrying offline change", err, nil)
+		}
+		q.writes = q.client.QueuedWrites()
+		if !q.replaying {
+			return q.replay()
+		}
+	case "d":
+		if !ok {
+			return nil
+		}
+		if !q.confirmDiscard {
+			q.confirmDiscard = true
+			return nil
+		}
+		q.confirmDiscard = false
+		if err := q.client.DiscardWrite(selected.Id); err != nil {
+			return Notify("Discarding offline change", err, nil)
+		}
+		q.writes = q.client.QueuedWrites()
+		q.cursor = min(q.cursor, max(len(q.writes)-1, 0))
+		// The views still show the discarded change.
+		return func() tea.Msg { return QueueChangedMsg{} }
+	}
+	return nil
+}
+
+func formatDuration(ms int) string {
+	d := (time.Duration(ms) * time.Millisecond).Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh %dm", h, m)
+}
+
+// describeWrite tells what a queued write changes, in a line.
+func describeWrite(w clients.QueuedWrite) string {
+	day, _ := time.Parse("2006-01-02", w.Date())
+	on := w.TaskName + " on " + day.Format("Mon 2 Jan")
+	switch w.Kind {
+	case clients.WriteTracking:
+		return fmt.Sprintf("Track %s on %s", formatDuration(int(w.Hours*60*60*1000)), on)
+	case clients.WriteCreateEntry:
+		return fmt.Sprintf("Add %s on %s", formatDuration(w.Duration), on)
+	case clients.WriteUpdateEntry:
+		description := ""
+		if w.Entry.Description != "" {
+			description = fmt.Sprintf(" %q", w.Entry.Description)
+		}
+		return fmt.Sprintf("Set entry to %s%s on %s", formatDuration(shared.ToInt(w.Entry.Duration)), description, on)
+	case clients.WriteDeleteEntry:
+		return "Delete an entry of " + on
+	}
+	return string(w.Kind) + " " + on
+}
+
+// View renders the queued writes in the order they are sent, with the reason of the conflicts.
+func (q *WriteQueue) View(width, height int) string {
+	title := ui.TitleStyle.Render("Offline changes")
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	stateStyles := map[clients.WriteState]lipgloss.Style{
+		clients.WritePending:  lipgloss.NewStyle().Foreground(ui.Warning),
+		clients.WriteConflict: lipgloss.NewStyle().Foreground(ui.Error).Bold(true),
+		clients.WriteFailed:   lipgloss.NewStyle().Foreground(ui.Error),
+	}
+
+	status := "Connected to ClickUp"
+	if q.offline {
+		status = "ClickUp cannot be reached, the changes are sent when the connection comes back"
+	}
+	if q.replaying {
+		status = "Sending the changes..."
+	}
+	var rows []string
+	if len(q.writes) == 0 {
+		rows = append(rows, dimStyle.Render("Every change has been sent to ClickUp"))
+	}
+	for i, w := range q.writes {
+		queuedAt := time.UnixMilli(w.QueuedAt).Format("Mon 15:04")
+		line := fmt.Sprintf("%s  %s  %s", stateStyles[w.State].Width(8).Render(string(w.State)), describeWrite(w), dimStyle.Render(queuedAt))
+		if i == q.cursor {
+			line = cursorStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(width-8).Render(line))
+		if w.Error != "" {
+			rows = append(rows, lipgloss.NewStyle().MaxWidth(width-8).Render("            "+dimStyle.Render(w.Error)))
+		}
+	}
+	content := []string{title, dimStyle.Render(status), "", lipgloss.JoinVertical(lipgloss.Left, rows...)}
+	if q.confirmDiscard {
+		content = append(content, "", lipgloss.NewStyle().Foreground(ui.Error).Bold(true).Render("Press [d] again to discard the change"))
+	}
+
+	help := "[↑ ↓] Select    [s] Send now    [r] Retry    [o] Overwrite ClickUp    [d d] Discard    [esc] Close"
+	panel := ui.PanelStyle.Width(width - 4).Height(height - 4).Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+	return lipgloss.JoinVertical(lipgloss.Left, panel, lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(help))
+}
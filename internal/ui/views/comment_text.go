@@ -0,0 +1,285 @@
+package views
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+var (
+	commentLinkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Underline(true)
+	commentDimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	commentCodeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#E6DB74"))
+	commentMentionStyle = lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	commentBadgeStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#fff")).Background(lipgloss.Color("#FF5F87")).Padding(0, 1)
+)
+
+// commentLine is a line of a comment, with the block attributes set on the newline ending it.
+type commentLine struct {
+	raw   string // the text without styles, for code blocks
+	text  string
+	block map[string]interface{}
+}
+
+// RenderCommentText renders the rich text of a comment: text styles, mentions, emoji,
+// task links and attachments inline, then lists, checklists, quotes and code blocks line by line.
+func RenderCommentText(comment []clients.CommentText) string {
+	var lines []commentLine
+	var raw, text strings.Builder
+	endLine := func(block map[string]interface{}) {
+		lines = append(lines, commentLine{raw: raw.String(), text: text.String(), block: block})
+		raw.Reset()
+		text.Reset()
+	}
+	for _, part := range comment {
+		switch part.Type {
+		case "bookmark":
+			if text.Len() > 0 {
+				endLine(nil)
+			}
+			bookmark := renderBookmark(part)
+			raw.WriteString(bookmark)
+			text.WriteString(bookmark)
+		case "tag":
+			name := strings.TrimPrefix(part.Text, "@")
+			if part.User != nil && part.User.Username != "" {
+				name = part.User.Username
+			}
+			raw.WriteString("@" + name)
+			text.WriteString(commentMentionStyle.Render("@" + name))
+		case "emoticon":
+			emoji := renderEmoticon(part)
+			raw.WriteString(emoji)
+			text.WriteString(emoji)
+		case "task_mention":
+			id := attrString(part.TaskMention, "task_id")
+			raw.WriteString("#" + id)
+			text.WriteString(commentLinkStyle.Render("#" + id))
+		case "attachment", "image":
+			file := renderFile(part)
+			raw.WriteString(file)
+			text.WriteString(file)
+		default:
+			// A part may hold several lines: every newline ends a line with the block
+			// attributes of the part.
+			pieces := strings.Split(part.Text, "\n")
+			for i, piece := range pieces {
+				raw.WriteString(piece)
+				text.WriteString(renderInline(piece, part.Attributes))
+				if i < len(pieces)-1 {
+					endLine(part.Attributes)
+				}
+			}
+		}
+	}
+	if text.Len() > 0 {
+		endLine(nil)
+	}
+	return renderCommentLines(lines)
+}
+
+// renderInline applies the inline attributes of a part to its text.
+func renderInline(text string, attrs map[string]interface{}) string {
+	if text == "" {
+		return ""
+	}
+	if badge, ok := attrs["badge-class"].(string); ok && badge != "" {
+		return commentBadgeStyle.Render(text) + " "
+	}
+	style := lipgloss.NewStyle()
+	if attrs["bold"] == true {
+		style = style.Bold(true)
+	}
+	if attrs["italic"] == true {
+		style = style.Italic(true)
+	}
+	if attrs["underline"] == true {
+		style = style.Underline(true)
+	}
+	if attrs["strike"] == true {
+		style = style.Strikethrough(true)
+	}
+	if attrs["code"] == true {
+		style = style.Inherit(commentCodeStyle).Background(ui.Subtle)
+	}
+	if link, ok := attrs["link"].(string); ok && link != "" {
+		rendered := style.Inherit(commentLinkStyle).Render(text)
+		if link != text {
+			rendered += commentDimStyle.Render(" (" + link + ")")
+		}
+		return rendered
+	}
+	return style.Render(text)
+}
+
+func renderBookmark(part clients.CommentText) string {
+	url, _ := part.Bookmark["url"].(string)
+	title := url
+	if raw, ok := part.Attributes["raw"].(string); ok && raw != "" {
+		if decoded, err := decodeBookmarkRaw(raw); err == nil && decoded.Title != "" {
+			title = decoded.Title
+		}
+	}
+	if title == url {
+		return commentLinkStyle.Render(title)
+	}
+	return commentLinkStyle.Render(title) + " → " + url
+}
+
+// renderEmoticon returns the emoji of an emoticon part, from its text or its unicode
+// code points such as "1f44d" or "1f468-200d-1f4bb".
+func renderEmoticon(part clients.CommentText) string {
+	if part.Text != "" {
+		return part.Text
+	}
+	if code := attrString(part.Emoticon, "code"); code != "" {
+		var sb strings.Builder
+		for _, point := range strings.Split(code, "-") {
+			r, err := strconv.ParseInt(point, 16, 32)
+			if err != nil {
+				sb.Reset()
+				break
+			}
+			sb.WriteRune(rune(r))
+		}
+		if sb.Len() > 0 {
+			return sb.String()
+		}
+	}
+	return ":" + attrString(part.Emoticon, "name") + ":"
+}
+
+func renderFile(part clients.CommentText) string {
+	file, icon := part.Attachment, "📎"
+	if part.Type == "image" {
+		file, icon = part.Image, "📷"
+	}
+	title := attrString(file, "title")
+	if title == "" {
+		title = attrString(file, "name")
+	}
+	if title == "" {
+		title = part.Text
+	}
+	url := attrString(file, "url")
+	if url == "" {
+		return icon + " " + title
+	}
+	return icon + " " + commentLinkStyle.Render(title) + " → " + url
+}
+
+// renderCommentLines renders the lines of a comment according to their block attributes.
+func renderCommentLines(lines []commentLine) string {
+	var out []string
+	numbers := map[int]int{} // last number of the ordered lists, by indent level
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		level := attrInt(line.block, "indent")
+		indent := strings.Repeat("  ", level)
+
+		if lang := attrString(line.block, "code-block"); lang != "" {
+			var code []string
+			for ; i < len(lines) && attrString(lines[i].block, "code-block") != ""; i++ {
+				code = append(code, lines[i].raw)
+			}
+			i--
+			out = append(out, renderCodeBlock(lang, code))
+			clear(numbers)
+			continue
+		}
+
+		// A numbered list goes on after the items nested in it, and restarts after anything else.
+		list := attrString(line.block, "list")
+		for l := range numbers {
+			if list == "" || l > level || (l == level && list != "ordered") {
+				delete(numbers, l)
+			}
+		}
+		switch {
+		case list == "bullet":
+			out = append(out, indent+"• "+line.text)
+		case list == "ordered":
+			numbers[level]++
+			out = append(out, indent+fmt.Sprintf("%d. ", numbers[level])+line.text)
+		case list == "checked":
+			out = append(out, indent+lipgloss.NewStyle().Foreground(ui.Special).Render("☑")+" "+commentDimStyle.Strikethrough(true).Render(line.raw))
+		case list == "unchecked":
+			out = append(out, indent+"☐ "+line.text)
+		case attrInt(line.block, "header") > 0:
+			out = append(out, indent+lipgloss.NewStyle().Bold(true).Underline(true).Render(line.raw))
+		case line.block["blockquote"] != nil:
+			out = append(out, indent+commentDimStyle.Render("┃ ")+line.text)
+		default:
+			out = append(out, indent+line.text)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderCodeBlock renders the lines of a code block behind a gutter, with its language
+// unless it is plain text.
+func renderCodeBlock(lang string, code []string) string {
+	gutter := commentDimStyle.Render("│ ")
+	var out []string
+	if lang != "plain" && lang != "code-block" {
+		out = append(out, commentDimStyle.Render("╭ "+lang))
+	}
+	for _, line := range code {
+		out = append(out, gutter+commentCodeStyle.Render(strings.ReplaceAll(line, "\t", "    ")))
+	}
+	return strings.Join(out, "\n")
+}
+
+// attrString reads an attribute that ClickUp sends either as a string or nested in an
+// object under its own name, as in {"list": {"list": "bullet"}}.
+func attrString(attrs map[string]interface{}, key string) string {
+	switch v := attrs[key].(type) {
+	case string:
+		return v
+	case bool:
+		if v {
+			return key
+		}
+	case map[string]interface{}:
+		return attrString(v, key)
+	}
+	return ""
+}
+
+func attrInt(attrs map[string]interface{}, key string) int {
+	switch v := attrs[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+type bookmarkRaw struct {
+	Title string `json:"title"`
+}
+
+func decodeBookmarkRaw(raw string) (bookmarkRaw, error) {
+	decoded := bookmarkRaw{}
+	data, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return decoded, err
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(data, &m) == nil {
+		if preview, ok := m["preview"].(map[string]interface{}); ok {
+			if title, ok := preview["title"].(string); ok {
+				decoded.Title = title
+			}
+		}
+	}
+	return decoded, nil
+}
@@ -0,0 +1,61 @@
+package views
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/mceck/clickup-tui/internal/clients"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the comment renderer")
+
+// TestRenderCommentText renders the comment payloads in testdata/comments and compares
+// them with their .golden files. Run with -update after changing the renderer. The styles
+// are rendered with the 16 ANSI colors on a dark background, whatever the terminal.
+func TestRenderCommentText(t *testing.T) {
+	profile, dark := lipgloss.ColorProfile(), lipgloss.HasDarkBackground()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	lipgloss.SetHasDarkBackground(true)
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		lipgloss.SetHasDarkBackground(dark)
+	})
+	payloads, err := filepath.Glob(filepath.Join("testdata", "comments", "*.json"))
+	if err != nil || len(payloads) == 0 {
+		t.Fatalf("no comment payloads: %v", err)
+	}
+	for _, payload := range payloads {
+		name := strings.TrimSuffix(filepath.Base(payload), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var comment []clients.CommentText
+			if err := json.Unmarshal(data, &comment); err != nil {
+				t.Fatal(err)
+			}
+			got := RenderCommentText(comment) + "\n"
+			golden := strings.TrimSuffix(payload, ".json") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("RenderCommentText() mismatch\n--- got\n%s--- want\n%s", got, want)
+			}
+		})
+	}
+}
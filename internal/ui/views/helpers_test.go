@@ -0,0 +1,84 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/fakeclickup"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+)
+
+// newTestServer starts a fake ClickUp server and saves a config pointing to it
+// in a temporary home directory.
+func newTestServer(t *testing.T, fixtures fakeclickup.Fixtures, config clients.Config) *fakeclickup.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	srv := fakeclickup.New(fixtures)
+	t.Cleanup(srv.Close)
+	config.BaseURL = srv.URL
+	if config.ClickupToken == "" {
+		config.ClickupToken = fakeclickup.DefaultToken
+	}
+	if config.TeamId == "" {
+		config.TeamId = fakeclickup.DefaultTeam
+	}
+	if err := clients.SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := clients.LoadLocalState(); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+// runCmd executes a command and its batched sub-commands and returns the produced messages.
+// Spinner ticks are dropped so that loading animations do not loop forever.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case nil, spinner.TickMsg:
+		return nil
+	case tea.BatchMsg:
+		var msgs []tea.Msg
+		for _, c := range msg {
+			msgs = append(msgs, runCmd(c)...)
+		}
+		return msgs
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+// drive feeds a message to the model, then every message produced by the resulting
+// commands, until nothing is left. Notifications are returned instead of being fed back.
+func drive(m tea.Model, msg tea.Msg) (tea.Model, []components.NotifyMsg) {
+	var notifications []components.NotifyMsg
+	queue := []tea.Msg{msg}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if n, ok := next.(components.NotifyMsg); ok {
+			notifications = append(notifications, n)
+			continue
+		}
+		var cmd tea.Cmd
+		m, cmd = m.Update(next)
+		queue = append(queue, runCmd(cmd)...)
+	}
+	return m, notifications
+}
+
+// start runs the model Init command and feeds back its results.
+func start(m tea.Model) (tea.Model, []components.NotifyMsg) {
+	var notifications []components.NotifyMsg
+	for _, msg := range runCmd(m.Init()) {
+		var n []components.NotifyMsg
+		m, n = drive(m, msg)
+		notifications = append(notifications, n...)
+	}
+	return m, notifications
+}
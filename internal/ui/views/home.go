@@ -1,21 +1,23 @@
 package views
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"context"
 	"fmt"
+	"math"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
-	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mceck/clickup-tui/internal/clients"
 	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
 	"golang.org/x/term"
 )
 
@@ -28,33 +30,75 @@ const (
 )
 
 type KColumn struct {
-	offsetY int
-	tasks   []clients.Task
+	tasks []clients.Task
 }
 
 type LoadMsg struct{}
 
+// LeaveMsg is sent to a view when the user navigates away from it.
+type LeaveMsg struct{}
+
+// EnterMsg is sent to an existing view when the user navigates back to it.
+type EnterMsg struct{}
+
+// TextEditor is implemented by views that can be typing text. While they are,
+// the application leaves shortcuts such as tab and ? to the view.
+type TextEditor interface {
+	EditingText() bool
+}
+
 type taskLoadedMsg struct {
 	tasks []clients.Task
 	err   error
 }
 
+type taskStatusUpdatedMsg struct {
+	taskId string
+	from   clients.Status
+	to     clients.Status
+	err    error
+}
+
 type HomeModel struct {
+	client           clients.ClickupAPI
 	width            int
 	height           int
 	wndX             int
 	wndY             int
 	offsetX          int
+	offsetLane       int
+	offsets          map[string]int // first card shown in each cell, by cellKey
+	selectedLane     int
 	selectedColumn   int
 	selectedTask     int
+	layout           clients.BoardLayout
 	states           []string
+	statuses         map[string]clients.Status
 	columns          map[string]KColumn
+	dragging         bool
+	dragColumn       int
 	loading          bool
+	interrupted      bool
+	refreshing       bool
+	changes          map[string]taskChange // cards changed since they were last seen, by id
+	showChanges      string                // id of the card whose changes are shown
+	ctx              context.Context
+	cancel           context.CancelFunc
 	spinner          spinner.Model
-	inputActive      bool
-	viewInput        textinput.Model
+	picking          bool
+	picker           components.Picker
+	form             taskForm
+	editor           taskEditor
+	external         externalEdit
+	comments         commentsPane
+	subtasks         subtasksPane
+	fields           fieldsPane
+	attachments      attachmentsPane
+	filter           taskFilter
+	filterBar        filterBar
 	showModal        bool
 	modalTask        *clients.Task
+	modalStack       []modalEntry // tasks opened before the modal task, esc goes back to them
 	contentViewport  viewport.Model
 	commentsViewport viewport.Model
 }
@@ -71,14 +115,24 @@ func calculateWindowDimensions(width, height int) (wndX, wndY int) {
 	return wndX, wndY
 }
 
-func fetchTasks() tea.Msg {
-	config := clients.GetConfig()
-	client := clients.NewClickupClient(config.ClickupToken, config.TeamId)
-	tasks, err := client.GetViewTasks(config.ViewId)
-	return taskLoadedMsg{tasks: tasks, err: err}
+func fetchTasks(ctx context.Context, client clients.ClickupAPI) tea.Cmd {
+	return func() tea.Msg {
+		config := clients.GetConfig()
+		tasks, err := client.GetViewTasks(ctx, config.ViewId)
+		return taskLoadedMsg{tasks: tasks, err: err}
+	}
 }
 
-func NewHomeModel() tea.Model {
+// updateTaskStatus persists a status change. Writes are not bound to the view
+// context: cancelling them halfway would leave the board out of sync with ClickUp.
+func updateTaskStatus(client clients.ClickupAPI, taskId string, from clients.Status, to clients.Status) tea.Cmd {
+	return func() tea.Msg {
+		_, err := client.UpdateTaskStatus(context.Background(), taskId, to.Status)
+		return taskStatusUpdatedMsg{taskId: taskId, from: from, to: to, err: err}
+	}
+}
+
+func NewHomeModel(client clients.ClickupAPI) tea.Model {
 	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
 	if width == 0 || height == 0 {
 		width, height = 80, 24
@@ -86,37 +140,53 @@ func NewHomeModel() tea.Model {
 
 	config := clients.GetConfig()
 	if config.ViewId == "" {
-		input := textinput.New()
-		input.Placeholder = "Inserisci il View ID..."
-		input.Focus()
-		input.CharLimit = 50
-		input.Width = 40
-		return HomeModel{width: width, height: height, viewInput: input, inputActive: true}
+		m := HomeModel{client: client, width: width, height: height}
+		m.openPicker()
+		return m
 	}
 
 	wndX, wndY := calculateWindowDimensions(width, height)
+	ctx, cancel := context.WithCancel(context.Background())
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	return HomeModel{
-		width:   width,
-		height:  height,
-		wndX:    wndX,
-		wndY:    wndY,
-		loading: true,
-		spinner: s,
-		columns: make(map[string]KColumn),
+		client:   client,
+		width:    width,
+		height:   height,
+		wndX:     wndX,
+		wndY:     wndY,
+		loading:  true,
+		ctx:      ctx,
+		cancel:   cancel,
+		spinner:  s,
+		columns:  make(map[string]KColumn),
+		statuses: make(map[string]clients.Status),
+		offsets:  make(map[string]int),
+		layout:   defaultLayout(config.ViewId),
 	}
 }
 
 func (m HomeModel) Init() tea.Cmd {
+	if m.picking {
+		return m.picker.Init()
+	}
 	if m.loading {
-		return tea.Batch(fetchTasks, m.spinner.Tick)
+		return tea.Batch(fetchTasks(m.ctx, m.client), m.spinner.Tick)
 	}
 	return nil
 }
 
+// resetRequests cancels the requests still running for the view
+// and prepares a fresh context for the next ones.
+func (m *HomeModel) resetRequests() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+}
+
 func (m *HomeModel) processTasks(tasks []clients.Task) {
 
 	stateMap := make(map[string]int)
@@ -143,6 +213,7 @@ func (m *HomeModel) processTasks(tasks []clients.Task) {
 	// 	return 1
 	// })
 	m.states = states
+	m.statuses = make(map[string]clients.Status)
 	m.columns = make(map[string]KColumn)
 	for _, state := range states {
 		m.columns[state] = KColumn{tasks: []clients.Task{}}
@@ -151,29 +222,121 @@ func (m *HomeModel) processTasks(tasks []clients.Task) {
 		col := m.columns[task.Status.Status]
 		col.tasks = append(col.tasks, task)
 		m.columns[task.Status.Status] = col
+		if _, ok := m.statuses[task.Status.Status]; !ok {
+			m.statuses[task.Status.Status] = task.Status
+		}
 	}
+	m.clampSelection()
 }
 
-func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if m.inputActive {
-		switch kmsg := msg.(type) {
-		case tea.KeyMsg:
-			return m.handleInputActiveEvent(kmsg)
-		default:
-			// While input is active, we only care about KeyMsgs.
-			return m, nil
+// moveTask moves a task from one status column to the top of another one.
+func (m *HomeModel) moveTask(taskId string, from string, to clients.Status) bool {
+	src, ok := m.columns[from]
+	if !ok {
+		return false
+	}
+	idx := -1
+	for i, t := range src.tasks {
+		if t.Id == taskId {
+			idx = i
+			break
 		}
 	}
+	if idx < 0 {
+		return false
+	}
+	task := src.tasks[idx]
+	task.Status = to
+	src.tasks = append(src.tasks[:idx:idx], src.tasks[idx+1:]...)
+	m.columns[from] = src
+
+	dst := m.columns[to.Status]
+	dst.tasks = append([]clients.Task{task}, dst.tasks...)
+	m.columns[to.Status] = dst
+	return true
+}
+
+// moveSelectedTask optimistically moves the selected task to the target column
+// and returns the command that persists the new status on ClickUp.
+// Only status columns can be moved to.
+func (m *HomeModel) moveSelectedTask(targetColumn int) tea.Cmd {
+	if targetColumn < 0 || targetColumn >= len(m.states) || targetColumn == m.selectedColumn {
+		return nil
+	}
+	task, ok := m.selectedCard()
+	if !ok {
+		return nil
+	}
+	if m.layout.Columns != groupStatus {
+		return components.Notify("Moving task "+task.Name, errMoveNotByStatus, nil)
+	}
+	from := task.Status
+	to, ok := m.statuses[m.states[targetColumn]]
+	if !ok {
+		return nil
+	}
+	if !m.moveTask(task.Id, from.Status, to) {
+		return nil
+	}
+	m.selectTask(task.Id)
+	return updateTaskStatus(m.client, task.Id, from, to)
+}
+
+func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.picking {
+		return m.handlePickerEvent(msg)
+	}
 
 	switch msg := msg.(type) {
 	case LoadMsg:
 		return m.handleLoadTasksEvent()
+	case LeaveMsg:
+		return m.handleLeaveEvent()
+	case EnterMsg:
+		if m.interrupted {
+			return m.handleLoadTasksEvent()
+		}
+		return m, nil
 	case tea.WindowSizeMsg:
 		return m.handleWindowSizeEvent(msg)
 	case spinner.TickMsg:
 		return m.handleSpinnerTickEvent(msg)
 	case taskLoadedMsg:
 		return m.handleTasksLoadedEvent(msg)
+	case taskStatusUpdatedMsg:
+		return m.handleTaskStatusUpdatedEvent(msg)
+	case taskCreatedMsg:
+		return m.handleTaskCreatedEvent(msg)
+	case taskUpdatedMsg:
+		return m.handleTaskUpdatedEvent(msg)
+	case editorClosedMsg:
+		return m.handleEditorClosedEvent(msg)
+	case descriptionCheckedMsg:
+		return m.handleDescriptionCheckedEvent(msg)
+	case descriptionSavedMsg:
+		return m.handleDescriptionSavedEvent(msg)
+	case commentsLoadedMsg:
+		return m.handleCommentsLoadedEvent(msg)
+	case commentActionFailedMsg:
+		return m, components.Notify(msg.label, msg.err, msg.retry)
+	case relatedTasksLoadedMsg:
+		return m.handleRelatedTasksLoadedEvent(msg)
+	case modalTaskLoadedMsg:
+		return m.handleModalTaskLoadedEvent(msg)
+	case checklistItemResolvedMsg:
+		return m.handleChecklistItemResolvedEvent(msg)
+	case customFieldSetMsg:
+		return m.handleCustomFieldSetEvent(msg)
+	case attachmentDownloadedMsg:
+		return m.handleAttachmentDownloadedEvent(msg)
+	case attachmentPreviewMsg:
+		return m.handleAttachmentPreviewEvent(msg)
+	case attachmentUploadedMsg:
+		return m.handleAttachmentUploadedEvent(msg)
+	case components.RefreshMsg:
+		return m.handleRefreshEvent()
+	case tasksRefreshedMsg:
+		return m.handleTasksRefreshedEvent(msg)
 	case tea.KeyMsg:
 		return m.handleKeyEvent(msg)
 	case tea.MouseMsg:
@@ -183,19 +346,27 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m HomeModel) EditingText() bool {
+	return m.picking || m.form.open || m.filterBar.active || (m.showModal && (m.editor.editing || m.comments.assigning))
+}
+
 func (m HomeModel) View() string {
 	if m.width == 0 {
 		return "Initializing..."
 	}
-	if m.inputActive {
-		return m.viewInputScreen()
+	if m.picking {
+		return m.picker.View()
 	}
 	if m.loading {
 		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#874BFD")).MarginLeft(2).Render("Caricamento tasks... ") + m.spinner.View()
 	}
 
 	var mainView string
-	if m.showModal && m.modalTask != nil {
+	if m.showChanges != "" {
+		mainView = m.renderChanges(m.width, m.height-2)
+	} else if m.form.open {
+		mainView = m.viewTaskForm()
+	} else if m.showModal && m.modalTask != nil {
 		mainView = m.viewModal()
 	} else {
 		mainView = m.viewBoard()
@@ -203,10 +374,40 @@ func (m HomeModel) View() string {
 
 	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Height(1)
 	var helpText string
-	if m.showModal {
-		helpText = helpStyle.Render("\n[↑ ↓] Scroll content    [j/k] Scroll comments    [enter/esc] Close")
+	if m.showChanges != "" {
+		helpText = helpStyle.Render("\n[any key] Close")
+	} else if m.filterBar.active && m.filterBar.saving {
+		helpText = helpStyle.Render("\n[enter] Save    [esc] Cancel")
+	} else if m.filterBar.active {
+		helpText = helpStyle.Render("\n[enter] Apply    [esc] Clear    [↑ ↓] Saved filters    [ctrl+s] Save filter    @me  @user  tag:  list:  status:  due:<7d  field:Name=value  -negates")
+	} else if m.form.open {
+		helpText = helpStyle.Render("\n[tab] Next field    [← →] Change choice    [enter/ctrl+s] Create    [esc] Cancel")
+	} else if m.showModal && m.external.conflict {
+		helpText = helpStyle.Render("\n[m] Merge    [o] Overwrite    [esc] Discard")
+	} else if m.showModal && m.editor.editing {
+		helpText = helpStyle.Render("\n[← →] Change choice    [enter] Save    [esc] Cancel")
+	} else if m.showModal && m.editor.active {
+		helpText = helpStyle.Render("\n[↑ ↓] Select field    [enter] Edit    [esc/e] Back to description")
+	} else if m.showModal && m.subtasks.active {
+		helpText = helpStyle.Render("\n[↑ ↓] Select    [enter] Open task    [space/x] Tick checklist item    [esc/s] Back to description")
+	} else if m.showModal && m.fields.editing {
+		helpText = helpStyle.Render("\n[← →] Change choice    [space] Toggle label    [enter] Save    [esc] Cancel")
+	} else if m.showModal && m.fields.active {
+		helpText = helpStyle.Render("\n[↑ ↓] Select field    [enter] Edit    [p] Show on cards    [esc/f] Back to description")
+	} else if m.showModal && m.attachments.preview != nil {
+		helpText = helpStyle.Render("\n[↑ ↓] Scroll    [enter/esc] Close preview")
+	} else if m.showModal && m.attachments.prompting {
+		helpText = helpStyle.Render("\n[enter] Upload    [esc] Cancel")
+	} else if m.showModal && m.attachments.active {
+		helpText = helpStyle.Render("\n[↑ ↓] Select    [enter/v] Preview    [d] Download    [u] Upload a file    [esc/A] Back to description")
+	} else if m.showModal {
+		closeHelp := "[enter/esc] Close"
+		if len(m.modalStack) > 0 {
+			closeHelp = "[enter/esc] Back"
+		}
+		helpText = helpStyle.Render("\n[↑ ↓] Scroll content    [j/k] Scroll comments    [s] Subtasks & checklists    [e] Edit fields    [f] Custom fields    [A] Attachments    [o] Description in $EDITOR    [c] Comment    [n/p] Select comment    [r] Reply    [+] React    [a] Assign    [x] Resolve    " + closeHelp)
 	} else {
-		helpText = helpStyle.Render("\n[← → ↑ ↓] Navigate    [enter] Task Details    [tab] Timesheet    [y] Copy customId    [r] Refresh    [?] Settings    [q] Quit")
+		helpText = helpStyle.Render("\n[← → ↑ ↓] Navigate    [shift+← →] Move task    [enter] Task Details    [w/W] What changed/Mark all seen    [/] Filter    [g/G] Group/Lanes    [n] New task    [tab] Timesheet    [v] Change view    [y] Copy customId    [t] Start/stop timer    [r] Refresh    [?] Settings    [q] Quit")
 	}
 
 	paddingHeight := m.height - lipgloss.Height(mainView)
@@ -222,58 +423,56 @@ func (m HomeModel) View() string {
 	return mainView + strings.Repeat("\n", repeatCount) + helpText
 }
 
-func (m HomeModel) viewInputScreen() string {
-	formStyle := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#874BFD")).Padding(1, 2)
-	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#874BFD")).MarginBottom(1)
-	content := lipgloss.JoinVertical(lipgloss.Center,
-		titleStyle.Render("Configurazione ClickUp"),
-		"Inserisci il View ID:",
-		m.viewInput.View(),
-		"",
-		"Premi Enter per salvare, Ctrl+C per uscire",
-	)
-	form := formStyle.Render(content)
-	return lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Center, form)
-}
-
 func (m HomeModel) viewBoard() string {
-	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#874BFD")).MarginBottom(1)
-	titleView := lipgloss.PlaceHorizontal(m.width, lipgloss.Center, title.Render("ClickUp View"))
-
-	renderedColumns := make([]string, 0)
-	visibleStates := m.states[m.offsetX:min(m.offsetX+m.wndX, len(m.states))]
-
-	for i, state := range visibleStates {
-		isSelectedCol := m.selectedColumn == i+m.offsetX
-		renderedColumns = append(renderedColumns, m.renderColumn(state, isSelectedCol))
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#874BFD"))
+	titleText := title.Render("ClickUp View")
+	if layout := m.layoutTitle(); layout != "" {
+		titleText += lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("  " + layout)
+	}
+	titleText += m.changesCount()
+	// The filter takes the line below the title, so the board does not move.
+	titleView := lipgloss.JoinVertical(lipgloss.Left, lipgloss.PlaceHorizontal(m.width, lipgloss.Center, titleText), m.renderFilterLine())
+
+	columns := m.boardColumns()
+	lanes := m.boardLanes()
+	visibleLanes, _ := m.laneWindow()
+	var renderedLanes []string
+	for l := m.offsetLane; l < min(m.offsetLane+visibleLanes, len(lanes)); l++ {
+		renderedLanes = append(renderedLanes, m.renderLane(l, lanes[l], columns))
 	}
 
-	board := lipgloss.JoinHorizontal(lipgloss.Top, renderedColumns...)
+	board := lipgloss.JoinVertical(lipgloss.Left, renderedLanes...)
 	return lipgloss.JoinVertical(lipgloss.Left, titleView, board)
 }
 
-func (m HomeModel) renderColumn(state string, isSelected bool) string {
-	colData := m.columns[state]
+func (m HomeModel) renderColumn(lane boardGroup, column boardGroup, isSelected bool) string {
+	tasks := m.cellTasks(lane, column)
+	offset := m.offsets[cellKey(lane, column)]
+	_, rows := m.laneWindow()
 	color := "#874BFD"
-	if len(colData.tasks) > 0 {
-		color = colData.tasks[0].Status.Color
+	if column.color != "" {
+		color = column.color
 	}
 
 	headerStyle := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color(color)).Padding(0, 1).Width(columnWidth - 4).Align(lipgloss.Center)
-	headerText := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(color)).Render(fmt.Sprintf("%s (%d)", strings.ToUpper(state), len(colData.tasks)))
+	count := fmt.Sprintf("%d", len(tasks))
+	if m.filter.active() {
+		count = fmt.Sprintf("%d/%d", len(tasks), len(m.groupTasks(lane, column, taskFilter{})))
+	}
+	headerText := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(color)).MaxWidth(columnWidth - 8).Render(fmt.Sprintf("%s (%s)", strings.ToUpper(column.label), count))
 	header := headerStyle.Render(headerText)
 
 	// Determine scrollability for tasks
-	canScrollUp := colData.offsetY > 0
-	canScrollDown := (colData.offsetY + m.wndY) < len(colData.tasks)
+	canScrollUp := offset > 0
+	canScrollDown := (offset + rows) < len(tasks)
 
 	renderedTasksStrings := make([]string, 0)
-	startTaskIdx := colData.offsetY
-	endTaskIdx := min(colData.offsetY+m.wndY, len(colData.tasks))
-	visibleTasks := colData.tasks[startTaskIdx:endTaskIdx]
+	startTaskIdx := min(offset, len(tasks))
+	endTaskIdx := min(offset+rows, len(tasks))
+	visibleTasks := tasks[startTaskIdx:endTaskIdx]
 
 	for j, task := range visibleTasks {
-		isTaskSelected := isSelected && m.selectedTask == j+colData.offsetY
+		isTaskSelected := isSelected && m.selectedTask == j+offset
 		highlightTopBorder := (j == 0 && canScrollUp)
 		highlightBottomBorder := (j == len(visibleTasks)-1 && canScrollDown)
 		renderedTasksStrings = append(renderedTasksStrings, m.renderTask(task, isTaskSelected, color, highlightTopBorder, highlightBottomBorder))
@@ -304,9 +503,12 @@ func (m HomeModel) renderTask(task clients.Task, isSelected bool, statusColor st
 	style := lipgloss.NewStyle().Padding(0, 1).Width(columnWidth - 4)
 
 	// Apply selection styling first
+	_, changed := m.changes[task.Id]
 	if isSelected {
 		highlightColor := shared.LightenColor(statusColor, 0.8)
 		style = style.BorderForeground(lipgloss.Color(highlightColor)).BorderStyle(lipgloss.DoubleBorder())
+	} else if changed {
+		style = style.BorderForeground(ui.Warning).BorderStyle(lipgloss.RoundedBorder())
 	} else {
 		style = style.BorderForeground(defaultTaskBorderColor).BorderStyle(lipgloss.RoundedBorder())
 	}
@@ -320,6 +522,9 @@ func (m HomeModel) renderTask(task clients.Task, isSelected bool, statusColor st
 	}
 
 	var assignees []string
+	if changed {
+		assignees = append(assignees, lipgloss.NewStyle().Foreground(ui.Warning).Render("● "))
+	}
 	for _, a := range task.Assignees {
 		bgColor := a.Color
 		if bgColor == "" {
@@ -343,15 +548,45 @@ func (m HomeModel) renderTask(task clients.Task, isSelected bool, statusColor st
 		subtaskInfo = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).MarginRight(1).Render(fmt.Sprintf("📋 %d", task.SubTasksCount))
 	}
 
-	bottomRow := lipgloss.JoinHorizontal(lipgloss.Left, subtaskInfo, lipgloss.NewStyle().Width(columnWidth-8-lipgloss.Width(subtaskInfo)).Align(lipgloss.Right).Render(lipgloss.JoinHorizontal(lipgloss.Right, tags...)))
-	wrappedTaskName := lipgloss.NewStyle().Width(columnWidth - 8).Height(3).Render(task.Name)
+	bottomLeft := lipgloss.JoinHorizontal(lipgloss.Left, subtaskInfo, renderEstimateBar(task))
+	bottomRow := lipgloss.JoinHorizontal(lipgloss.Left, bottomLeft, lipgloss.NewStyle().Width(columnWidth-8-lipgloss.Width(bottomLeft)).Align(lipgloss.Right).Render(lipgloss.JoinHorizontal(lipgloss.Right, tags...)))
+	// The custom fields of the view take the last line of the name, the cards keep their height.
+	nameStyle := lipgloss.NewStyle().Width(columnWidth - 8).Height(3)
+	var rows []string
+	if len(m.layout.Fields) > 0 {
+		nameStyle = nameStyle.Height(2).MaxHeight(2)
+		rows = append(rows, m.renderCardFields(task))
+	}
+	wrappedTaskName := nameStyle.Render(task.Name)
 	taskNameStyle := lipgloss.NewStyle().Bold(true).MaxWidth(columnWidth - 5)
 	listNameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).MaxWidth(columnWidth - 5)
 
-	content := lipgloss.JoinVertical(lipgloss.Left, lipgloss.JoinHorizontal(lipgloss.Left, assignees...), listNameStyle.Render("📁 "+task.List.Name), taskNameStyle.Render(wrappedTaskName), bottomRow)
+	rows = append([]string{lipgloss.JoinHorizontal(lipgloss.Left, assignees...), listNameStyle.Render("📁 " + task.List.Name), taskNameStyle.Render(wrappedTaskName)}, append(rows, bottomRow)...)
+	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
 	return style.Render(content)
 }
 
+// estimateBarCells is the length of the bar of the time spent on a card.
+const estimateBarCells = 5
+
+// renderEstimateBar renders the time spent on a task against its estimate, as a small bar
+// that turns red when the estimate is exceeded. Tasks without an estimate have no bar.
+func renderEstimateBar(task clients.Task) string {
+	if task.TimeEstimate <= 0 {
+		return ""
+	}
+	filled := int(math.Round(float64(task.TimeSpent) / float64(task.TimeEstimate) * estimateBarCells))
+	filled = min(max(filled, 0), estimateBarCells)
+	barStyle, textStyle := lipgloss.NewStyle().Foreground(ui.Special), lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if task.TimeSpent > task.TimeEstimate {
+		barStyle, textStyle = barStyle.Foreground(ui.Error), textStyle.Foreground(ui.Error)
+	}
+	bar := strings.Repeat("▰", filled) + strings.Repeat("▱", estimateBarCells-filled)
+	msPerHour := float64(time.Hour / time.Millisecond)
+	text := formatHoursShort(float64(task.TimeSpent)/msPerHour) + "/" + formatHoursShort(float64(task.TimeEstimate)/msPerHour)
+	return lipgloss.NewStyle().MarginRight(1).Render(barStyle.Render(bar) + " " + textStyle.Render(text))
+}
+
 func (m HomeModel) viewModal() string {
 	task := m.modalTask
 	statusColor := task.Status.Color
@@ -362,7 +597,11 @@ func (m HomeModel) viewModal() string {
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(statusColor)).Width(m.width - 6).Align(lipgloss.Left)
 	listStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
 	customIdStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500"))
-	header := lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render(task.Name+"   "+customIdStyle.Render("📋 "+task.CustomId)), listStyle.Render("📁 "+task.List.Name)+"   "+listStyle.Render("🔗 "+task.Url))
+	location := listStyle.Render("📁 "+task.List.Name) + "   " + listStyle.Render("🔗 "+task.Url)
+	if len(m.modalStack) > 0 {
+		location += listStyle.Render("   ↰ " + m.modalStack[len(m.modalStack)-1].task.Name)
+	}
+	header := lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render(task.Name+"   "+customIdStyle.Render("📋 "+task.CustomId)), location)
 
 	var metaInfo []string
 	metaInfo = append(metaInfo, lipgloss.NewStyle().Bold(true).Background(lipgloss.Color(statusColor)).Foreground(lipgloss.Color("#000000")).Padding(0, 1).MarginRight(2).Render(strings.ToUpper(task.Status.Status)))
@@ -395,7 +634,20 @@ func (m HomeModel) viewModal() string {
 	if !m.contentViewport.AtBottom() {
 		contentViewStyle = contentViewStyle.BorderBottomForeground(scrollHighlightColor)
 	}
-	styledContentView := contentViewStyle.Render(m.contentViewport.View())
+	var styledContentView string
+	if m.external.conflict {
+		styledContentView = contentViewStyle.Render(m.renderConflict(m.contentViewport.Width, m.contentViewport.Height))
+	} else if m.editor.active {
+		styledContentView = contentViewStyle.Render(m.renderEditor(m.contentViewport.Width, m.contentViewport.Height))
+	} else if m.subtasks.active {
+		styledContentView = contentViewStyle.Render(m.renderSubtasks(m.contentViewport.Width, m.contentViewport.Height))
+	} else if m.fields.active {
+		styledContentView = contentViewStyle.Render(m.renderFields(m.contentViewport.Width, m.contentViewport.Height))
+	} else if m.attachments.active {
+		styledContentView = contentViewStyle.Render(m.renderAttachments(m.contentViewport.Width, m.contentViewport.Height))
+	} else {
+		styledContentView = contentViewStyle.Render(m.contentViewport.View())
+	}
 
 	// Comments Viewport with scroll indication
 	commentsSectionStyle := lipgloss.NewStyle().Width(m.width-9).Border(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("#666666")).Padding(0, 1)
@@ -406,92 +658,91 @@ func (m HomeModel) viewModal() string {
 		commentsSectionStyle = commentsSectionStyle.BorderBottomForeground(scrollHighlightColor)
 	}
 	commentHeaderStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#888888")).MarginBottom(1)
-	comments := commentsSectionStyle.Render(lipgloss.JoinVertical(lipgloss.Left, commentHeaderStyle.Render("Comments"), m.commentsViewport.View()))
+	comments := commentsSectionStyle.Render(lipgloss.JoinVertical(lipgloss.Left, commentHeaderStyle.Render(m.renderCommentsHeader()), m.commentsViewport.View()))
 
-	modalContent := lipgloss.JoinVertical(lipgloss.Left, header, metaRow, styledContentView, comments)
+	modalContent := lipgloss.JoinVertical(lipgloss.Left, header, metaRow, lipgloss.NewStyle().MaxWidth(m.width-6).Render(renderTaskDetails(*task)+renderTaskStructure(*task)), styledContentView, comments)
 	return modalStyle.Render(modalContent)
 }
 
-func RenderCommentText(comment []clients.CommentText) string {
-	var sb strings.Builder
-	for _, part := range comment {
-		switch part.Type {
-		case "bookmark":
-			url, _ := part.Bookmark["url"].(string)
-			title := url
-			if raw, ok := part.Attributes["raw"].(string); ok && raw != "" {
-				if decoded, err := decodeBookmarkRaw(raw); err == nil && decoded.Title != "" {
-					title = decoded.Title
-				}
-			}
-			linkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("33")).Underline(true)
-			if title == url {
-				sb.WriteString(linkStyle.Render(title) + " \n")
-			} else {
-				sb.WriteString(linkStyle.Render(title) + " → " + url + " \n")
-			}
-		case "":
-			if badge, ok := part.Attributes["badge-class"].(string); ok && badge != "" {
-				badgeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#fff")).Background(lipgloss.Color("#FF5F87")).Padding(0, 1)
-				sb.WriteString(badgeStyle.Render(part.Text) + " ")
-			} else {
-				sb.WriteString(lipgloss.NewStyle().Render(part.Text))
-			}
-		default:
-			sb.WriteString(lipgloss.NewStyle().Render(part.Text))
-		}
-	}
-	return sb.String()
+func (m *HomeModel) openPicker() {
+	m.picking = true
+	m.picker = components.NewPicker(m.client, clients.GetConfig().ClickupToken)
+	m.picker.SetSize(m.width, m.height)
 }
 
-type bookmarkRaw struct {
-	Title string `json:"title"`
-}
-
-func decodeBookmarkRaw(raw string) (bookmarkRaw, error) {
-	decoded := bookmarkRaw{}
-	data, err := base64.StdEncoding.DecodeString(raw)
-	if err != nil {
-		return decoded, err
-	}
-	var m map[string]interface{}
-	if json.Unmarshal(data, &m) == nil {
-		if preview, ok := m["preview"].(map[string]interface{}); ok {
-			if title, ok := preview["title"].(string); ok {
-				decoded.Title = title
-			}
+// handlePickerEvent routes the messages to the view picker while it is open.
+func (m HomeModel) handlePickerEvent(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.wndX, m.wndY = calculateWindowDimensions(m.width, m.height)
+		m.picker.SetSize(m.width, m.height)
+		return m, nil
+	case LoadMsg:
+		return m, m.picker.Init()
+	case components.PickedMsg:
+		if msg.PickerId == m.picker.Id() {
+			return m.handleViewPickedEvent(msg.Choice)
 		}
-	}
-	return decoded, nil
-}
-
-func (m HomeModel) handleInputActiveEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var updatedViewInput textinput.Model
-	var cmd tea.Cmd
-	if msg.Type == tea.KeyEnter && m.viewInput.Value() != "" {
-		config := clients.GetConfig()
-		config.ViewId = m.viewInput.Value()
-		if err := clients.SaveConfig(config); err != nil {
+		return m, nil
+	case components.PickerCanceledMsg:
+		if msg.PickerId == m.picker.Id() && clients.GetConfig().ViewId != "" {
+			m.picking = false
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
-		clients.ClearCache()
-		return NewHomeModel(), nil
 	}
-	updatedViewInput, cmd = m.viewInput.Update(msg)
-	m.viewInput = updatedViewInput
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
 	return m, cmd
 }
+
+// handleViewPickedEvent saves the chosen view. A different team needs a new client,
+// so in that case the whole application is rebuilt.
+func (m HomeModel) handleViewPickedEvent(choice components.PickerChoice) (tea.Model, tea.Cmd) {
+	config := clients.GetConfig()
+	teamChanged := config.TeamId != choice.TeamId
+	config.TeamId = choice.TeamId
+	config.ViewId = choice.ViewId
+	if err := clients.SaveConfig(config); err != nil {
+		return m, components.Notify("Saving config", err, nil)
+	}
+	if teamChanged {
+		return m, func() tea.Msg { return components.ConfigChangedMsg{} }
+	}
+	home := NewHomeModel(m.client).(HomeModel)
+	next, _ := home.handleWindowSizeEvent(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	return next, next.Init()
+}
+
 func (m HomeModel) handleLoadTasksEvent() (tea.Model, tea.Cmd) {
+	m.resetRequests()
 	m.loading = true
-	return m, tea.Batch(fetchTasks, m.spinner.Tick)
+	m.interrupted = false
+	return m, tea.Batch(fetchTasks(m.ctx, m.client), m.spinner.Tick)
+}
+
+func (m HomeModel) handleLeaveEvent() (tea.Model, tea.Cmd) {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	if m.loading {
+		m.loading = false
+		m.interrupted = true
+	}
+	return m, nil
 }
 
 func (m HomeModel) handleWindowSizeEvent(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	m.width, m.height = msg.Width, msg.Height
 	m.wndX, m.wndY = calculateWindowDimensions(m.width, m.height)
+	m.scrollToSelection()
 	if m.showModal {
 		m.contentViewport.Width = m.width - 9
-		m.contentViewport.Height = m.height - 19
+		m.contentViewport.Height = m.height - 20
 		m.commentsViewport.Width = m.width - 11
 		m.commentsViewport.Height = 6
 	}
@@ -510,15 +761,45 @@ func (m HomeModel) handleSpinnerTickEvent(msg spinner.TickMsg) (tea.Model, tea.C
 }
 
 func (m HomeModel) handleTasksLoadedEvent(msg taskLoadedMsg) (tea.Model, tea.Cmd) {
-	if msg.err != nil {
-		return m, tea.Quit
+	if clients.IsCanceled(msg.err) {
+		return m, nil
 	}
 	m.loading = false
+	if msg.err != nil {
+		return m, components.Notify("Loading tasks", msg.err, fetchTasks(m.ctx, m.client))
+	}
+	if len(m.states) > 0 {
+		m.trackChanges(m.boardTasks(), msg.tasks)
+	}
 	m.processTasks(msg.tasks)
 	return m, nil
 }
 
+func (m HomeModel) handleTaskStatusUpdatedEvent(msg taskStatusUpdatedMsg) (tea.Model, tea.Cmd) {
+	if msg.err == nil {
+		// A successful retry after a rollback moves the task again.
+		m.moveTask(msg.taskId, msg.from.Status, msg.to)
+		return m, nil
+	}
+	retry := updateTaskStatus(m.client, msg.taskId, msg.from, msg.to)
+	notify := components.Notify("Moving task to "+msg.to.Status, msg.err, retry)
+	// Roll back the optimistic move, keeping the selection on the task.
+	if m.moveTask(msg.taskId, msg.to.Status, msg.from) {
+		m.selectTask(msg.taskId)
+	}
+	return m, notify
+}
+
 func (m HomeModel) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.showChanges != "" {
+		return m.handleKeyChangesEvent(msg)
+	}
+	if m.form.open {
+		return m.handleKeyFormEvent(msg)
+	}
+	if m.filterBar.active {
+		return m.handleKeyFilterEvent(msg)
+	}
 	if m.showModal {
 		return m.handleKeyModalEvent(msg)
 	}
@@ -526,13 +807,79 @@ func (m HomeModel) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m HomeModel) handleMouseInput(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.form.open {
+		return m, nil
+	}
 	if m.showModal {
 		return m.handleMouseModalEvent(msg)
 	}
 	return m.handleMouseMainEvent(msg)
 }
 
+// boardPosition maps screen coordinates to a lane, a column and a task index in that cell.
+// The task index is -1 when the point is not over a card.
+func (m HomeModel) boardPosition(x, y int) (int, int, int, bool) {
+	columns := m.boardColumns()
+	if len(columns) == 0 || x < 0 {
+		return 0, 0, 0, false
+	}
+	column := m.offsetX + x/(columnWidth+2)
+	if column >= len(columns) || column >= m.offsetX+m.wndX {
+		return 0, 0, 0, false
+	}
+	titleHeight := lipgloss.Height(lipgloss.NewStyle().MarginBottom(1).Render("ClickUp View"))
+	columnHeaderHeight := 4
+	laneHeaderHeight := 0
+	if m.layout.Lanes != "" {
+		laneHeaderHeight = 1
+	}
+	lanes := m.boardLanes()
+	visibleLanes, rows := m.laneWindow()
+	top := titleHeight
+	for l := m.offsetLane; l < min(m.offsetLane+visibleLanes, len(lanes)); l++ {
+		laneHeight := lipgloss.Height(m.renderLane(l, lanes[l], columns))
+		if y >= top+laneHeight {
+			top += laneHeight
+			continue
+		}
+		tasks := m.cellTasks(lanes[l], columns[column])
+		cardsTop := top + laneHeaderHeight + columnHeaderHeight
+		if len(tasks) == 0 || y < cardsTop {
+			return l, column, -1, true
+		}
+		offset := m.offsets[cellKey(lanes[l], columns[column])]
+		cardHeight := lipgloss.Height(m.renderTask(tasks[0], false, "#874BFD", false, false))
+		task := offset + (y-cardsTop)/cardHeight
+		if task >= len(tasks) || task >= offset+rows {
+			return l, column, -1, true
+		}
+		return l, column, task, true
+	}
+	return 0, 0, 0, false
+}
+
 func (m HomeModel) handleMouseMainEvent(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+	lane, column, task, ok := m.boardPosition(msg.X, msg.Y)
+	switch msg.Action {
+	case tea.MouseActionPress:
+		if !ok || task < 0 {
+			return m, nil
+		}
+		m.selectedLane, m.selectedColumn, m.selectedTask = lane, column, task
+		m.dragging, m.dragColumn = true, column
+	case tea.MouseActionRelease:
+		if !m.dragging {
+			return m, nil
+		}
+		m.dragging = false
+		if !ok || column == m.dragColumn {
+			return m, nil
+		}
+		return m, m.moveSelectedTask(column)
+	}
 	return m, nil
 }
 
@@ -548,10 +895,39 @@ func (m HomeModel) handleMouseModalEvent(msg tea.MouseMsg) (tea.Model, tea.Cmd)
 }
 
 func (m HomeModel) handleKeyModalEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.external.conflict {
+		return m.handleKeyConflictEvent(msg)
+	}
+	if m.editor.active {
+		return m.handleKeyEditorEvent(msg)
+	}
+	if m.subtasks.active {
+		return m.handleKeySubtasksEvent(msg)
+	}
+	if m.fields.active {
+		return m.handleKeyFieldsEvent(msg)
+	}
+	if m.attachments.active {
+		return m.handleKeyAttachmentsEvent(msg)
+	}
+	if next, cmd, handled := m.handleKeyCommentsEvent(msg); handled {
+		return next, cmd
+	}
 	switch msg.String() {
+	case "o":
+		return m.editDescription()
+	case "c":
+		return m.writeComment()
+	case "e":
+		m.editor.active = true
+	case "s":
+		m.subtasks.active = true
+	case "f":
+		return m.openFieldsPane()
+	case "A":
+		return m.openAttachmentsPane()
 	case "q", "esc", "enter":
-		m.showModal = false
-		m.modalTask = nil
+		return m.closeModal()
 	case "up":
 		m.contentViewport.ScrollUp(1)
 	case "down":
@@ -568,112 +944,123 @@ func (m HomeModel) handleKeyModalEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// setModalDescription renders the markdown description of the modal task.
+func (m *HomeModel) setModalDescription() {
+	var renderedMarkdown string
+	if m.modalTask.Description != "" {
+		rendered, err := glamour.Render(m.modalTask.Description, "dark")
+		if err == nil {
+			renderedMarkdown = rendered
+		} else {
+			renderedMarkdown = m.modalTask.Description
+		}
+	}
+	m.contentViewport.SetContent(lipgloss.NewStyle().Width(m.width - 9).Render(renderedMarkdown))
+}
+
+// setModalComments renders the comments of the modal task, keeping the selected one in view.
+func (m *HomeModel) setModalComments() {
+	content, selectedLine := m.renderComments()
+	m.commentsViewport.SetContent(content)
+	if selectedLine < m.commentsViewport.YOffset || selectedLine >= m.commentsViewport.YOffset+m.commentsViewport.Height {
+		m.commentsViewport.SetYOffset(selectedLine)
+	}
+}
+
 func (m HomeModel) handleKeyMainEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
 		return m, tea.Quit
 	case "r":
 		clients.ClearViewTasksCache()
-		m.loading = true
-		return m, tea.Batch(fetchTasks, m.spinner.Tick)
+		return m.handleLoadTasksEvent()
+	case "v":
+		m.openPicker()
+		return m, m.picker.Init()
+	}
+	if len(m.states) == 0 {
+		return m, nil
+	}
+	switch msg.String() {
+	case "/":
+		return m.openFilterBar()
+	case "g":
+		return m.cycleColumns()
+	case "G":
+		return m.cycleLanes()
+	case "n":
+		return m.openTaskForm()
 	case "y":
-		if col, ok := m.columns[m.states[m.selectedColumn]]; ok && m.selectedTask < len(col.tasks) {
-			task := col.tasks[m.selectedTask]
+		if task, ok := m.selectedCard(); ok {
 			if task.CustomId != "" {
 				clipboard.WriteAll(task.CustomId)
 			}
 		}
-	case "enter":
-		if col, ok := m.columns[m.states[m.selectedColumn]]; ok && m.selectedTask < len(col.tasks) {
-			task := col.tasks[m.selectedTask]
-			config := clients.GetConfig()
-			client := clients.NewClickupClient(config.ClickupToken, config.TeamId)
-			t, err := client.GetTask(task.Id)
-			if err != nil {
-				return m, nil
+	case "t":
+		if task, ok := m.selectedCard(); ok {
+			return m, components.ToggleTimer(task.Id, task.Name)
+		}
+	case "w":
+		if task, ok := m.selectedCard(); ok {
+			if _, changed := m.changes[task.Id]; changed {
+				m.showChanges = task.Id
 			}
-			comments, err := client.GetTaskComments(task.Id)
+		}
+	case "W":
+		m.changes = nil
+	case "enter":
+		if task, ok := m.selectedCard(); ok {
+			delete(m.changes, task.Id)
+			m.modalStack = nil
+			cmd, err := m.openModal(task.Id)
 			if err != nil {
-				comments = []clients.Comment{}
-			}
-			t.Comments = comments
-			m.modalTask = &t
-			m.showModal = true
-
-			m.contentViewport = viewport.New(m.width-9, m.height-19)
-			m.commentsViewport = viewport.New(m.width-11, 6)
-
-			var renderedMarkdown string
-			if m.modalTask.Description != "" {
-				rendered, err := glamour.Render(m.modalTask.Description, "dark")
-				if err == nil {
-					renderedMarkdown = rendered
-				} else {
-					renderedMarkdown = m.modalTask.Description
-				}
-			}
-			m.contentViewport.SetContent(lipgloss.NewStyle().Width(m.width - 9).Render(renderedMarkdown))
-
-			var commentsContent []string
-			for _, comment := range m.modalTask.Comments {
-				color := comment.User.Color
-				if color == "" {
-					color = "#888888"
-				}
-				commentHeader := lipgloss.JoinHorizontal(lipgloss.Left,
-					lipgloss.NewStyle().Bold(true).Background(lipgloss.Color(color)).Foreground(lipgloss.Color("#FFFFFF")).Padding(0, 1).MarginRight(1).Render(comment.User.Initials),
-					lipgloss.NewStyle().Width(m.width-23).Foreground(lipgloss.Color("#666666")).Render(comment.User.Username),
-					lipgloss.NewStyle().Foreground(lipgloss.Color("#666666")).Render(shared.ToElapsedTime(comment.Date)),
-				)
-				commentLine := lipgloss.NewStyle().Width(m.width - 16).Render(RenderCommentText(comment.Comment))
-				commentsContent = append(commentsContent, commentHeader, commentLine)
+				return m, components.Notify("Opening task "+task.Name, err, nil)
 			}
-			m.commentsViewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, commentsContent...))
+			return m, cmd
 		}
+	case "shift+left":
+		return m, m.moveSelectedTask(m.selectedColumn - 1)
+	case "shift+right":
+		return m, m.moveSelectedTask(m.selectedColumn + 1)
 	case "left":
 		if m.selectedColumn > 0 {
 			m.selectedColumn--
-			if m.selectedColumn < m.offsetX {
-				m.offsetX--
-			}
-			if _, ok := m.columns[m.states[m.selectedColumn]]; ok {
-				m.selectedTask = m.columns[m.states[m.selectedColumn]].offsetY
-			} else {
-				m.selectedTask = 0
+			if lane, column, ok := m.selectedCell(); ok {
+				m.selectedTask = m.offsets[cellKey(lane, column)]
 			}
+			m.scrollToSelection()
 		}
 	case "right":
-		if m.selectedColumn < len(m.states)-1 {
+		if m.selectedColumn < len(m.boardColumns())-1 {
 			m.selectedColumn++
-			if m.selectedColumn >= m.offsetX+m.wndX {
-				m.offsetX++
-			}
-			if _, ok := m.columns[m.states[m.selectedColumn]]; ok {
-				m.selectedTask = m.columns[m.states[m.selectedColumn]].offsetY
-			} else {
-				m.selectedTask = 0
+			if lane, column, ok := m.selectedCell(); ok {
+				m.selectedTask = m.offsets[cellKey(lane, column)]
 			}
+			m.scrollToSelection()
 		}
 	case "up":
+		// Past the first card, the selection goes to the last card of the lane above.
 		if m.selectedTask > 0 {
 			m.selectedTask--
-			if col, ok := m.columns[m.states[m.selectedColumn]]; ok {
-				if m.selectedTask < col.offsetY {
-					col.offsetY--
-					m.columns[m.states[m.selectedColumn]] = col
-				}
+		} else if m.selectedLane > 0 {
+			m.selectedLane--
+			if lane, column, ok := m.selectedCell(); ok {
+				m.selectedTask = max(len(m.cellTasks(lane, column))-1, 0)
 			}
 		}
+		m.scrollToSelection()
 	case "down":
-		if col, ok := m.columns[m.states[m.selectedColumn]]; ok {
-			if m.selectedTask < len(col.tasks)-1 {
-				m.selectedTask++
-				if m.selectedTask >= col.offsetY+m.wndY {
-					col.offsetY++
-					m.columns[m.states[m.selectedColumn]] = col
-				}
-			}
+		lane, column, ok := m.selectedCell()
+		if !ok {
+			break
+		}
+		if m.selectedTask < len(m.cellTasks(lane, column))-1 {
+			m.selectedTask++
+		} else if m.selectedLane < len(m.boardLanes())-1 {
+			m.selectedLane++
+			m.selectedTask = 0
 		}
+		m.scrollToSelection()
 	}
 	return m, nil
 }
@@ -0,0 +1,396 @@
+package views
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+// maxPreviewSize is the largest attachment fetched for a preview.
+const maxPreviewSize = 5 << 20
+
+type attachmentDownloadedMsg struct {
+	attachment clients.Attachment
+	path       string
+	err        error
+}
+
+type attachmentPreviewMsg struct {
+	attachment clients.Attachment
+	content    []byte
+	err        error
+}
+
+type attachmentUploadedMsg struct {
+	taskId     string
+	path       string
+	attachment clients.Attachment
+	err        error
+}
+
+// attachmentsPane replaces the description of the task modal with its attachments.
+// They can be downloaded, previewed, and new files uploaded from a path.
+type attachmentsPane struct {
+	active    bool
+	cursor    int
+	prompting bool // the path of a file to upload is being typed
+	input     textinput.Model
+	upload    fieldState
+	states    map[string]fieldState // downloads, by attachment id
+	saved     map[string]string     // where the attachments were downloaded, by id
+	preview   *attachmentPreview
+}
+
+// attachmentPreview is an attachment shown in the pane: text is scrolled with ↑/↓,
+// images are drawn with half blocks.
+type attachmentPreview struct {
+	attachment clients.Attachment
+	loading    bool
+	text       []string
+	img        image.Image
+	err        error
+	offset     int
+}
+
+func downloadAttachment(client clients.ClickupAPI, attachment clients.Attachment, dir string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := saveAttachment(client, attachment, dir)
+		return attachmentDownloadedMsg{attachment: attachment, path: path, err: err}
+	}
+}
+
+// saveAttachment downloads an attachment in dir, next to the files with the same name.
+func saveAttachment(client clients.ClickupAPI, attachment clients.Attachment, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := freePath(dir, attachment.Title)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	err = client.DownloadAttachment(context.Background(), attachment, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// freePath returns a path for a file in dir that does not overwrite another one:
+// "notes.txt", then "notes (1).txt" and so on.
+func freePath(dir string, name string) string {
+	name = filepath.Base(name)
+	if name == "." || name == "/" || name == "" {
+		name = "attachment"
+	}
+	ext := filepath.Ext(name)
+	path := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext))
+	}
+}
+
+func previewAttachment(ctx context.Context, client clients.ClickupAPI, attachment clients.Attachment) tea.Cmd {
+	return func() tea.Msg {
+		if attachment.Size > maxPreviewSize {
+			return attachmentPreviewMsg{attachment: attachment, err: fmt.Errorf("%s is too large to preview, download it with d", formatSize(attachment.Size))}
+		}
+		var content bytes.Buffer
+		err := client.DownloadAttachment(ctx, attachment, &content)
+		return attachmentPreviewMsg{attachment: attachment, content: content.Bytes(), err: err}
+	}
+}
+
+func uploadAttachment(client clients.ClickupAPI, taskId string, path string) tea.Cmd {
+	return func() tea.Msg {
+		file, err := os.Open(path)
+		if err != nil {
+			return attachmentUploadedMsg{taskId: taskId, path: path, err: err}
+		}
+		defer file.Close()
+		attachment, err := client.UploadAttachment(context.Background(), taskId, filepath.Base(path), file)
+		return attachmentUploadedMsg{taskId: taskId, path: path, attachment: attachment, err: err}
+	}
+}
+
+// expandPath resolves a path typed by the user: ~ is the home directory.
+func expandPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = home + path[1:]
+	}
+	return path
+}
+
+// formatSize renders a size in bytes with the unit that fits it.
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// newAttachmentPreview decodes the content of an attachment: an image, some text,
+// or an error for the other types.
+func newAttachmentPreview(attachment clients.Attachment, content []byte) *attachmentPreview {
+	p := &attachmentPreview{attachment: attachment}
+	mimetype := attachment.Mimetype
+	if mimetype == "" {
+		mimetype = http.DetectContentType(content)
+	}
+	if strings.HasPrefix(mimetype, "image/") {
+		img, _, err := image.Decode(bytes.NewReader(content))
+		if err != nil {
+			p.err = fmt.Errorf("cannot preview this image: %w", err)
+		}
+		p.img = img
+		return p
+	}
+	if !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0 {
+		p.err = fmt.Errorf("no preview for %s files, download it with d", mimetype)
+		return p
+	}
+	text := strings.ReplaceAll(strings.ReplaceAll(string(content), "\r\n", "\n"), "\t", "    ")
+	p.text = strings.Split(strings.TrimRight(text, "\n"), "\n")
+	return p
+}
+
+func (m HomeModel) openAttachmentsPane() (tea.Model, tea.Cmd) {
+	m.attachments.active = true
+	if m.attachments.states == nil {
+		m.attachments.states = make(map[string]fieldState)
+		m.attachments.saved = make(map[string]string)
+	}
+	return m, nil
+}
+
+func (m HomeModel) handleKeyAttachmentsEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.attachments
+	if p.preview != nil {
+		switch msg.String() {
+		case "esc", "enter", "q":
+			p.preview = nil
+		case "up":
+			p.preview.offset = max(p.preview.offset-1, 0)
+		case "down":
+			p.preview.offset = min(p.preview.offset+1, max(len(p.preview.text)-1, 0))
+		}
+		return m, nil
+	}
+	if p.prompting {
+		switch msg.String() {
+		case "esc":
+			p.prompting = false
+		case "enter":
+			path := expandPath(p.input.Value())
+			if path == "" {
+				return m, nil
+			}
+			p.prompting = false
+			p.upload = fieldState{saving: true}
+			return m, uploadAttachment(m.client, m.modalTask.Id, path)
+		default:
+			var cmd tea.Cmd
+			p.input, cmd = p.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	attachments := m.modalTask.Attachments
+	switch msg.String() {
+	case "esc", "A":
+		p.active = false
+	case "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down":
+		if p.cursor < len(attachments)-1 {
+			p.cursor++
+		}
+	case "u":
+		if p.upload.saving {
+			return m, nil
+		}
+		p.prompting = true
+		p.upload = fieldState{}
+		p.input = newFormInput("path of the file to upload", 500)
+		p.input.Focus()
+	case "d":
+		if p.cursor >= len(attachments) || p.states[attachments[p.cursor].Id].saving {
+			return m, nil
+		}
+		attachment := attachments[p.cursor]
+		p.states[attachment.Id] = fieldState{saving: true}
+		return m, downloadAttachment(m.client, attachment, clients.GetConfig().Downloads())
+	case "enter", "v":
+		if p.cursor >= len(attachments) {
+			return m, nil
+		}
+		attachment := attachments[p.cursor]
+		p.preview = &attachmentPreview{attachment: attachment, loading: true}
+		return m, previewAttachment(m.ctx, m.client, attachment)
+	}
+	return m, nil
+}
+
+func (m HomeModel) handleAttachmentDownloadedEvent(msg attachmentDownloadedMsg) (tea.Model, tea.Cmd) {
+	p := &m.attachments
+	if p.states == nil {
+		return m, nil
+	}
+	if msg.err != nil {
+		p.states[msg.attachment.Id] = fieldState{err: msg.err}
+		return m, components.Notify("Downloading "+msg.attachment.Title, msg.err, downloadAttachment(m.client, msg.attachment, clients.GetConfig().Downloads()))
+	}
+	p.states[msg.attachment.Id] = fieldState{}
+	p.saved[msg.attachment.Id] = msg.path
+	return m, nil
+}
+
+func (m HomeModel) handleAttachmentPreviewEvent(msg attachmentPreviewMsg) (tea.Model, tea.Cmd) {
+	p := &m.attachments
+	if p.preview == nil || p.preview.attachment.Id != msg.attachment.Id {
+		return m, nil
+	}
+	if msg.err != nil {
+		p.preview = &attachmentPreview{attachment: msg.attachment, err: msg.err}
+		return m, nil
+	}
+	p.preview = newAttachmentPreview(msg.attachment, msg.content)
+	return m, nil
+}
+
+// handleAttachmentUploadedEvent adds the uploaded file to the modal task and selects it.
+func (m HomeModel) handleAttachmentUploadedEvent(msg attachmentUploadedMsg) (tea.Model, tea.Cmd) {
+	current := m.modalTask != nil && m.modalTask.Id == msg.taskId
+	if msg.err != nil {
+		if current {
+			m.attachments.upload = fieldState{err: msg.err}
+		}
+		return m, components.Notify("Uploading "+filepath.Base(msg.path), msg.err, uploadAttachment(m.client, msg.taskId, msg.path))
+	}
+	if !current {
+		return m, nil
+	}
+	m.attachments.upload = fieldState{}
+	task := *m.modalTask
+	task.Attachments = append(slices.Clone(task.Attachments), msg.attachment)
+	m.modalTask = &task
+	m.attachments.cursor = len(task.Attachments) - 1
+	return m, nil
+}
+
+// renderAttachments renders the attachments of the modal task in a box of the given size,
+// or the attachment being previewed.
+func (m HomeModel) renderAttachments(width int, height int) string {
+	p := m.attachments
+	if p.preview != nil {
+		return p.preview.render(width, height)
+	}
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	savingStyle := lipgloss.NewStyle().Foreground(ui.Warning)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.Error)
+	nameWidth := max(width/2, 20)
+
+	var rows []string
+	for i, a := range m.modalTask.Attachments {
+		prefix := "  "
+		name := lipgloss.NewStyle().Width(nameWidth).MaxWidth(nameWidth).Render(a.Title)
+		if i == p.cursor {
+			prefix = cursorStyle.Render("> ")
+			name = cursorStyle.Width(nameWidth).MaxWidth(nameWidth).Render(a.Title)
+		}
+		info := dimStyle.Render(fmt.Sprintf("%9s  %s  %s", formatSize(a.Size), a.User.Username, shared.ToDateString(a.Date.String())))
+		state := ""
+		if p.states[a.Id].saving {
+			state = savingStyle.Render("  downloading...")
+		} else if err := p.states[a.Id].err; err != nil {
+			state = errorStyle.Render("  ✗ " + err.Error())
+		} else if path, ok := p.saved[a.Id]; ok {
+			state = dimStyle.Render("  ✓ " + path)
+		}
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(width).Render(prefix+name+info+state))
+	}
+	if len(rows) == 0 {
+		rows = append(rows, dimStyle.Render("No attachments"))
+	}
+
+	var footer string
+	switch {
+	case p.prompting:
+		footer = "Upload: " + p.input.View()
+	case p.upload.saving:
+		footer = savingStyle.Render("uploading...")
+	case p.upload.err != nil:
+		footer = errorStyle.Render("✗ " + p.upload.err.Error())
+	}
+	if footer != "" {
+		height--
+	}
+	start := 0
+	if len(rows) > height {
+		start = min(max(p.cursor-height/2, 0), len(rows)-height)
+	}
+	rows = rows[start:min(start+max(height, 0), len(rows))]
+	list := lipgloss.NewStyle().Width(width).Height(max(height, 0)).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	if footer == "" {
+		return list
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, list, lipgloss.NewStyle().MaxWidth(width).Render(footer))
+}
+
+func (p attachmentPreview) render(width int, height int) string {
+	title := lipgloss.NewStyle().Bold(true).MaxWidth(width).Render(p.attachment.Title)
+	body := ""
+	switch {
+	case p.loading:
+		body = lipgloss.NewStyle().Foreground(ui.Warning).Render("loading...")
+	case p.err != nil:
+		body = lipgloss.NewStyle().Foreground(ui.Error).Render("✗ " + p.err.Error())
+	case p.img != nil:
+		body = shared.RenderHalfBlocks(p.img, width, height-1)
+	default:
+		text := p.text[min(p.offset, len(p.text)):]
+		lines := make([]string, 0, height)
+		for _, line := range text[:min(len(text), max(height-1, 0))] {
+			lines = append(lines, lipgloss.NewStyle().MaxWidth(width).Render(line))
+		}
+		body = strings.Join(lines, "\n")
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(lipgloss.JoinVertical(lipgloss.Left, title, body))
+}
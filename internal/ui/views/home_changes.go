@@ -0,0 +1,170 @@
+package views
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+// taskChange is a card changed on ClickUp since the user last looked at it.
+type taskChange struct {
+	before *clients.Task // nil for a card new on the board
+	after  clients.Task
+}
+
+// fieldChange is a field of a task with its old and new value.
+type fieldChange struct {
+	label string
+	from  string
+	to    string
+}
+
+type tasksRefreshedMsg struct {
+	tasks []clients.Task
+	err   error
+}
+
+func refreshTasks(ctx context.Context, client clients.ClickupAPI) tea.Cmd {
+	return func() tea.Msg {
+		tasks, err := client.SyncViewTasks(ctx, clients.GetConfig().ViewId)
+		return tasksRefreshedMsg{tasks: tasks, err: err}
+	}
+}
+
+// diffTasks lists the fields that differ between two versions of a task.
+func diffTasks(before clients.Task, after clients.Task) []fieldChange {
+	var changes []fieldChange
+	for field := 0; field < editFieldCount; field++ {
+		if from, to := fieldValue(before, field), fieldValue(after, field); from != to {
+			changes = append(changes, fieldChange{label: editLabels[field], from: from, to: to})
+		}
+	}
+	if before.List.Id != after.List.Id {
+		changes = append(changes, fieldChange{label: "List", from: before.List.Name, to: after.List.Name})
+	}
+	for _, field := range after.CustomFields {
+		previous, _ := findCustomField(before, field.Name)
+		if from, to := customFieldDisplay(previous), customFieldDisplay(field); from != to {
+			changes = append(changes, fieldChange{label: field.Name, from: from, to: to})
+		}
+	}
+	if before.Description != after.Description {
+		changes = append(changes, fieldChange{label: "Description", to: "edited"})
+	}
+	return changes
+}
+
+// trackChanges compares the cards of the board with the ones just fetched. A card keeps
+// the version the user last looked at until the change is seen.
+func (m *HomeModel) trackChanges(before []clients.Task, after []clients.Task) {
+	if m.changes == nil {
+		m.changes = make(map[string]taskChange)
+	}
+	old := make(map[string]clients.Task, len(before))
+	for _, task := range before {
+		old[task.Id] = task
+	}
+	onBoard := make(map[string]bool, len(after))
+	for _, task := range after {
+		onBoard[task.Id] = true
+		change, ok := m.changes[task.Id]
+		if !ok {
+			if prev, found := old[task.Id]; found {
+				change.before = &prev
+			}
+		}
+		change.after = task
+		if change.before != nil && len(diffTasks(*change.before, task)) == 0 {
+			delete(m.changes, task.Id)
+			continue
+		}
+		m.changes[task.Id] = change
+	}
+	for id := range m.changes {
+		if !onBoard[id] {
+			delete(m.changes, id)
+		}
+	}
+}
+
+// handleRefreshEvent fetches the board again in the background, with the comments of the
+// open task. Nothing moves on screen until the new data is there.
+func (m HomeModel) handleRefreshEvent() (tea.Model, tea.Cmd) {
+	if m.picking || m.loading || m.refreshing || m.interrupted {
+		return m, nil
+	}
+	m.refreshing = true
+	cmds := []tea.Cmd{refreshTasks(m.ctx, m.client)}
+	if m.showModal && m.modalTask != nil {
+		clients.ClearCommentsCache(m.modalTask.Id)
+		cmds = append(cmds, loadComments(m.client, m.modalTask.Id))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleTasksRefreshedEvent replaces the cards, keeping the selection on the same card
+// and the columns scrolled where they were. Losing the connection is shown in the header.
+func (m HomeModel) handleTasksRefreshedEvent(msg tasksRefreshedMsg) (tea.Model, tea.Cmd) {
+	m.refreshing = false
+	if clients.IsCanceled(msg.err) || clients.IsOffline(msg.err) {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m, components.Notify("Refreshing tasks", msg.err, nil)
+	}
+	selected, hasSelection := m.selectedCard()
+	m.trackChanges(m.boardTasks(), msg.tasks)
+	m.processTasks(msg.tasks)
+	if hasSelection {
+		m.selectTask(selected.Id)
+	}
+	return m, nil
+}
+
+// handleKeyChangesEvent closes the "what changed" popover. The change is then seen.
+func (m HomeModel) handleKeyChangesEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	delete(m.changes, m.showChanges)
+	m.showChanges = ""
+	return m, nil
+}
+
+// renderChanges renders the popover listing what changed on a card.
+func (m HomeModel) renderChanges(width int, height int) string {
+	change := m.changes[m.showChanges]
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Width(14)
+	rows := []string{ui.TitleStyle.Render("What changed"), lipgloss.NewStyle().Bold(true).Render(change.after.Name), ""}
+	if change.before == nil {
+		rows = append(rows, "New on the board")
+	} else {
+		for _, c := range diffTasks(*change.before, change.after) {
+			from := c.from
+			if from == "" {
+				from = "—"
+			}
+			line := labelStyle.Render(c.label) + dimStyle.Render(from) + " → " + lipgloss.NewStyle().Foreground(ui.Highlight).Render(c.to)
+			if c.label == "Description" {
+				line = labelStyle.Render(c.label) + c.to
+			}
+			rows = append(rows, line)
+		}
+	}
+	popover := ui.PanelStyle.Width(min(width-4, 70)).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, popover)
+}
+
+// changesCount renders how many cards changed since they were last seen, for the title.
+func (m HomeModel) changesCount() string {
+	if len(m.changes) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(ui.Warning).Render(fmt.Sprintf("  ● %d changed", len(m.changes)))
+}
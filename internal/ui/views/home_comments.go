@@ -0,0 +1,368 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type commentsLoadedMsg struct {
+	taskId   string
+	comments []clients.Comment
+	err      error
+}
+
+type commentActionFailedMsg struct {
+	label string
+	err   error
+	retry tea.Cmd
+}
+
+// reactions are the emoji offered in the reactions bar, by ClickUp name.
+var reactions = []struct {
+	name  string
+	emoji string
+}{
+	{"thumbsup", "👍"},
+	{"heart", "❤️"},
+	{"tada", "🎉"},
+	{"laughing", "😆"},
+	{"eyes", "👀"},
+	{"white_check_mark", "✅"},
+}
+
+func reactionEmoji(name string) string {
+	for _, r := range reactions {
+		if r.name == name {
+			return r.emoji
+		}
+	}
+	return ":" + name + ":"
+}
+
+// commentItem is a comment or a reply, in the order they are shown.
+type commentItem struct {
+	comment  clients.Comment
+	threadId string // id of the comment that started the thread
+	reply    bool
+}
+
+// commentsPane keeps the selected comment of the modal and the prompts acting on it.
+type commentsPane struct {
+	cursor    int
+	assigning bool
+	input     textinput.Model
+	reacting  bool
+	reaction  int
+}
+
+// loadComments fetches the comments of a task with the replies of each thread.
+func loadComments(client clients.ClickupAPI, taskId string) tea.Cmd {
+	return func() tea.Msg {
+		comments, err := client.GetTaskComments(context.Background(), taskId)
+		if err != nil {
+			return commentsLoadedMsg{taskId: taskId, err: err}
+		}
+		comments = append([]clients.Comment(nil), comments...)
+		for i, comment := range comments {
+			if comment.ReplyCount == 0 {
+				continue
+			}
+			replies, err := client.GetCommentReplies(context.Background(), comment.Id)
+			if err != nil {
+				return commentsLoadedMsg{taskId: taskId, err: err}
+			}
+			comments[i].Replies = replies
+		}
+		return commentsLoadedMsg{taskId: taskId, comments: comments}
+	}
+}
+
+// commentAction runs a change on the comments of a task and reloads them.
+// When it fails, the notification retries the same change.
+func commentAction(client clients.ClickupAPI, taskId string, label string, action func(ctx context.Context) error) tea.Cmd {
+	var cmd tea.Cmd
+	cmd = func() tea.Msg {
+		if err := action(context.Background()); err != nil {
+			return commentActionFailedMsg{label: label, err: err, retry: cmd}
+		}
+		return loadComments(client, taskId)()
+	}
+	return cmd
+}
+
+func postComment(client clients.ClickupAPI, taskId string, replyTo string, text string) tea.Cmd {
+	if replyTo != "" {
+		return commentAction(client, taskId, "Replying to comment", func(ctx context.Context) error {
+			_, err := client.CreateCommentReply(ctx, replyTo, text)
+			return err
+		})
+	}
+	return commentAction(client, taskId, "Posting comment", func(ctx context.Context) error {
+		_, err := client.CreateTaskComment(ctx, taskId, text)
+		return err
+	})
+}
+
+func (m *HomeModel) commentItems() []commentItem {
+	var items []commentItem
+	if m.modalTask == nil {
+		return items
+	}
+	for _, comment := range m.modalTask.Comments {
+		items = append(items, commentItem{comment: comment, threadId: comment.Id})
+		for _, reply := range comment.Replies {
+			items = append(items, commentItem{comment: reply, threadId: comment.Id, reply: true})
+		}
+	}
+	return items
+}
+
+func (m *HomeModel) selectedComment() (commentItem, bool) {
+	items := m.commentItems()
+	if m.comments.cursor < 0 || m.comments.cursor >= len(items) {
+		return commentItem{}, false
+	}
+	return items[m.comments.cursor], true
+}
+
+func (m HomeModel) handleCommentsLoadedEvent(msg commentsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m, components.Notify("Loading comments", msg.err, loadComments(m.client, msg.taskId))
+	}
+	if m.modalTask == nil || m.modalTask.Id != msg.taskId {
+		return m, nil
+	}
+	m.modalTask.Comments = msg.comments
+	m.comments.cursor = min(m.comments.cursor, max(len(m.commentItems())-1, 0))
+	m.setModalComments()
+	return m, nil
+}
+
+// handleKeyCommentsEvent handles the keys acting on the selected comment.
+// It returns false when the key is not about comments.
+func (m HomeModel) handleKeyCommentsEvent(msg tea.KeyMsg) (HomeModel, tea.Cmd, bool) {
+	p := &m.comments
+	if p.assigning {
+		return m, m.handleAssignInput(msg), true
+	}
+	if p.reacting {
+		return m, m.handleReactionInput(msg), true
+	}
+	item, ok := m.selectedComment()
+	switch msg.String() {
+	case "n":
+		if p.cursor < len(m.commentItems())-1 {
+			p.cursor++
+			m.setModalComments()
+		}
+	case "p":
+		if p.cursor > 0 {
+			p.cursor--
+			m.setModalComments()
+		}
+	case "r":
+		if !ok {
+			return m, nil, true
+		}
+		var cmd tea.Cmd
+		m.external, cmd = openEditor(externalEdit{taskId: m.modalTask.Id, comment: true, replyTo: item.threadId}, "")
+		return m, cmd, true
+	case "+":
+		if ok {
+			p.reacting = true
+			p.reaction = 0
+		}
+	case "a":
+		if ok {
+			p.assigning = true
+			p.input = textinput.New()
+			p.input.Prompt = "Assign to: "
+			p.input.Placeholder = "me, username or id"
+			p.input.CharLimit = 100
+			p.input.Focus()
+		}
+	case "x":
+		if !ok {
+			return m, nil, true
+		}
+		if item.comment.Assignee == nil {
+			return m, components.Notify("Resolving comment", errors.New("only assigned comments can be resolved"), nil), true
+		}
+		resolved := !item.comment.Resolved
+		label := "Resolving comment"
+		if !resolved {
+			label = "Reopening comment"
+		}
+		return m, commentAction(m.client, m.modalTask.Id, label, func(ctx context.Context) error {
+			return m.client.UpdateComment(ctx, item.comment.Id, clients.CommentUpdate{Resolved: &resolved})
+		}), true
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+func (m *HomeModel) handleAssignInput(msg tea.KeyMsg) tea.Cmd {
+	p := &m.comments
+	switch msg.Type {
+	case tea.KeyEscape:
+		p.assigning = false
+		return nil
+	case tea.KeyEnter:
+		item, ok := m.selectedComment()
+		p.assigning = false
+		if !ok {
+			return nil
+		}
+		userId, err := resolveAssignee(m.boardUsers(), strings.TrimSpace(p.input.Value()))
+		if err != nil {
+			return components.Notify("Assigning comment", err, nil)
+		}
+		return commentAction(m.client, m.modalTask.Id, "Assigning comment", func(ctx context.Context) error {
+			return m.client.UpdateComment(ctx, item.comment.Id, clients.CommentUpdate{Assignee: &userId})
+		})
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return cmd
+}
+
+// handleReactionInput adds the chosen reaction, or removes it when the user already left it.
+func (m *HomeModel) handleReactionInput(msg tea.KeyMsg) tea.Cmd {
+	p := &m.comments
+	switch msg.String() {
+	case "esc", "+":
+		p.reacting = false
+	case "left":
+		p.reaction = (p.reaction - 1 + len(reactions)) % len(reactions)
+	case "right":
+		p.reaction = (p.reaction + 1) % len(reactions)
+	case "enter":
+		p.reacting = false
+		item, ok := m.selectedComment()
+		if !ok {
+			return nil
+		}
+		name := reactions[p.reaction].name
+		if hasReacted(item.comment, name) {
+			return commentAction(m.client, m.modalTask.Id, "Removing reaction", func(ctx context.Context) error {
+				return m.client.RemoveCommentReaction(ctx, item.comment.Id, name)
+			})
+		}
+		return commentAction(m.client, m.modalTask.Id, "Adding reaction", func(ctx context.Context) error {
+			return m.client.AddCommentReaction(ctx, item.comment.Id, name)
+		})
+	}
+	return nil
+}
+
+func hasReacted(comment clients.Comment, name string) bool {
+	userId := clients.GetConfig().UserId
+	for _, r := range comment.Reactions {
+		if r.Reaction == name && r.User.IdString() == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCommentMeta renders the reactions, the assignee and the replies of a comment.
+func renderCommentMeta(comment clients.Comment) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	var parts []string
+	var names []string
+	counts := map[string]int{}
+	for _, r := range comment.Reactions {
+		if counts[r.Reaction] == 0 {
+			names = append(names, r.Reaction)
+		}
+		counts[r.Reaction]++
+	}
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %d", reactionEmoji(name), counts[name]))
+	}
+	if comment.Assignee != nil {
+		assigned := "→ " + comment.Assignee.Username
+		if comment.Resolved {
+			parts = append(parts, lipgloss.NewStyle().Foreground(ui.Special).Render(assigned+" ✓ resolved"))
+		} else {
+			parts = append(parts, lipgloss.NewStyle().Foreground(ui.Warning).Render(assigned))
+		}
+	}
+	if comment.ReplyCount > 0 && len(comment.Replies) == 0 {
+		parts = append(parts, fmt.Sprintf("💬 %d replies", comment.ReplyCount))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return dimStyle.Render(strings.Join(parts, "   "))
+}
+
+// renderComments renders the threads of the modal task, replies indented under their
+// comment, and returns the line where the selected comment starts.
+func (m HomeModel) renderComments() (string, int) {
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	var blocks []string
+	selectedLine, line := 0, 0
+	for i, item := range m.commentItems() {
+		comment := item.comment
+		indent := 0
+		if item.reply {
+			indent = 4
+		}
+		color := comment.User.Color
+		if color == "" {
+			color = "#888888"
+		}
+		commentHeader := lipgloss.JoinHorizontal(lipgloss.Left,
+			lipgloss.NewStyle().Bold(true).Background(lipgloss.Color(color)).Foreground(lipgloss.Color("#FFFFFF")).Padding(0, 1).MarginRight(1).Render(comment.User.Initials),
+			lipgloss.NewStyle().Width(m.width-23-indent).Foreground(lipgloss.Color("#666666")).Render(comment.User.Username),
+			lipgloss.NewStyle().Foreground(lipgloss.Color("#666666")).Render(shared.ToElapsedTime(comment.Date)),
+		)
+		rows := []string{commentHeader, lipgloss.NewStyle().Width(m.width - 16 - indent).Render(RenderCommentText(comment.Comment))}
+		if meta := renderCommentMeta(comment); meta != "" {
+			rows = append(rows, meta)
+		}
+		block := lipgloss.JoinVertical(lipgloss.Left, rows...)
+		marker := " "
+		if i == m.comments.cursor {
+			marker = cursorStyle.Render("▌")
+			selectedLine = line
+		}
+		gutter := strings.TrimSuffix(strings.Repeat(marker+"\n", lipgloss.Height(block)), "\n")
+		block = lipgloss.JoinHorizontal(lipgloss.Top, gutter, strings.Repeat(" ", indent+1), block)
+		blocks = append(blocks, block)
+		line += lipgloss.Height(block)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, blocks...), selectedLine
+}
+
+// renderCommentsHeader shows the prompt of the action in progress on the selected comment.
+func (m HomeModel) renderCommentsHeader() string {
+	p := m.comments
+	if p.assigning {
+		return p.input.View()
+	}
+	if p.reacting {
+		var choices []string
+		for i, r := range reactions {
+			if i == p.reaction {
+				choices = append(choices, lipgloss.NewStyle().Background(ui.Highlight).Padding(0, 1).Render(r.emoji))
+			} else {
+				choices = append(choices, " "+r.emoji+" ")
+			}
+		}
+		return "React: " + strings.Join(choices, " ")
+	}
+	return "Comments"
+}
@@ -0,0 +1,360 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type taskCreatedMsg struct {
+	listId string
+	req    clients.NewTask
+	task   clients.Task
+	err    error
+}
+
+const (
+	formName = iota
+	formList
+	formStatus
+	formAssignees
+	formTags
+	formPriority
+	formDueDate
+	formDescription
+	formFieldCount
+)
+
+// priorityNames are indexed by ClickUp priority, 0 meaning no priority.
+var priorityNames = []string{"none", "urgent", "high", "normal", "low"}
+
+// taskForm collects the fields of a new task. Lists, statuses and priorities are
+// chosen with ← →, the other fields are typed.
+type taskForm struct {
+	open        bool
+	saving      bool
+	focus       int
+	lists       []clients.List
+	list        int
+	states      []string
+	status      int
+	priority    int
+	users       []clients.User
+	name        textinput.Model
+	assignees   textinput.Model
+	tags        textinput.Model
+	dueDate     textinput.Model
+	description textarea.Model
+}
+
+func createTask(client clients.ClickupAPI, listId string, req clients.NewTask) tea.Cmd {
+	return func() tea.Msg {
+		task, err := client.CreateTask(context.Background(), listId, req)
+		return taskCreatedMsg{listId: listId, req: req, task: task, err: err}
+	}
+}
+
+func newFormInput(placeholder string, limit int) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.CharLimit = limit
+	input.Prompt = ""
+	return input
+}
+
+// newTaskForm prepares the form with the list of the selected card and the current column.
+// The lists and the users to choose from are the ones found on the board.
+func (m *HomeModel) newTaskForm() (taskForm, error) {
+	f := taskForm{
+		open:      true,
+		states:    m.states,
+		name:      newFormInput("Task name", 500),
+		assignees: newFormInput("me, username or id, comma separated", 200),
+		tags:      newFormInput("comma separated", 200),
+		dueDate:   newFormInput("YYYY-MM-DD", 10),
+		users:     m.boardUsers(),
+	}
+	if m.layout.Columns == groupStatus {
+		f.status = m.selectedColumn
+	}
+	var selectedList string
+	if task, ok := m.selectedCard(); ok {
+		selectedList = task.List.Id
+		f.status = max(slices.Index(m.states, task.Status.Status), 0)
+	}
+	seen := map[string]bool{}
+	for _, state := range m.states {
+		for _, task := range m.columns[state].tasks {
+			if task.List.Id != "" && !seen[task.List.Id] {
+				seen[task.List.Id] = true
+				if task.List.Id == selectedList {
+					f.list = len(f.lists)
+				}
+				f.lists = append(f.lists, task.List)
+			}
+		}
+	}
+	if len(f.lists) == 0 {
+		return taskForm{}, fmt.Errorf("no list found on the board")
+	}
+	f.description = textarea.New()
+	f.description.Placeholder = "Markdown description"
+	f.description.ShowLineNumbers = false
+	f.description.CharLimit = 0
+	f.description.SetWidth(max(m.width-12, 20))
+	f.description.SetHeight(max(m.height-26, 3))
+	f.name.Focus()
+	return f, nil
+}
+
+// boardUsers returns the users assigned to the tasks on the board, the ones that
+// can be picked by name as assignees.
+func (m *HomeModel) boardUsers() []clients.User {
+	var users []clients.User
+	seen := map[string]bool{}
+	for _, state := range m.states {
+		for _, task := range m.columns[state].tasks {
+			for _, user := range task.Assignees {
+				if id := user.IdString(); id != "" && !seen[id] {
+					seen[id] = true
+					users = append(users, user)
+				}
+			}
+		}
+	}
+	return users
+}
+
+func (f *taskForm) setFocus(field int) {
+	f.focus = (field + formFieldCount) % formFieldCount
+	f.name.Blur()
+	f.assignees.Blur()
+	f.tags.Blur()
+	f.dueDate.Blur()
+	f.description.Blur()
+	switch f.focus {
+	case formName:
+		f.name.Focus()
+	case formAssignees:
+		f.assignees.Focus()
+	case formTags:
+		f.tags.Focus()
+	case formDueDate:
+		f.dueDate.Focus()
+	case formDescription:
+		f.description.Focus()
+	}
+}
+
+// cycle changes the value of the focused choice field.
+func (f *taskForm) cycle(delta int) {
+	wrap := func(v, n int) int { return (v + delta + n) % n }
+	switch f.focus {
+	case formList:
+		f.list = wrap(f.list, len(f.lists))
+	case formStatus:
+		f.status = wrap(f.status, len(f.states))
+	case formPriority:
+		f.priority = wrap(f.priority, len(priorityNames))
+	}
+}
+
+func (f *taskForm) updateInput(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch f.focus {
+	case formName:
+		f.name, cmd = f.name.Update(msg)
+	case formAssignees:
+		f.assignees, cmd = f.assignees.Update(msg)
+	case formTags:
+		f.tags, cmd = f.tags.Update(msg)
+	case formDueDate:
+		f.dueDate, cmd = f.dueDate.Update(msg)
+	case formDescription:
+		f.description, cmd = f.description.Update(msg)
+	}
+	return cmd
+}
+
+// request validates the form and builds the task to create.
+func (f *taskForm) request() (clients.NewTask, error) {
+	req := clients.NewTask{
+		Name:        strings.TrimSpace(f.name.Value()),
+		Description: strings.TrimSpace(f.description.Value()),
+		Status:      f.states[f.status],
+		Priority:    f.priority,
+		Tags:        splitList(f.tags.Value()),
+	}
+	if req.Name == "" {
+		return req, fmt.Errorf("the name is required")
+	}
+	for _, name := range splitList(f.assignees.Value()) {
+		id, err := resolveAssignee(f.users, name)
+		if err != nil {
+			return req, err
+		}
+		req.Assignees = append(req.Assignees, id)
+	}
+	if due := strings.TrimSpace(f.dueDate.Value()); due != "" {
+		day, err := time.ParseInLocation("2006-01-02", due, time.Local)
+		if err != nil {
+			return req, fmt.Errorf("invalid due date %q, expected YYYY-MM-DD", due)
+		}
+		req.DueDate = day.UnixMilli()
+	}
+	return req, nil
+}
+
+// resolveAssignee accepts "me", a user id or the username or initials of one of the users.
+func resolveAssignee(users []clients.User, name string) (int, error) {
+	if strings.EqualFold(name, "me") {
+		name = clients.GetConfig().UserId
+	}
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	for _, user := range users {
+		if strings.EqualFold(user.Username, name) || strings.EqualFold(user.Initials, name) {
+			return strconv.Atoi(user.IdString())
+		}
+	}
+	return 0, fmt.Errorf("unknown assignee %q", name)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (m HomeModel) openTaskForm() (tea.Model, tea.Cmd) {
+	form, err := m.newTaskForm()
+	if err != nil {
+		return m, components.Notify("Creating task", err, nil)
+	}
+	m.form = form
+	return m, nil
+}
+
+func (m HomeModel) handleKeyFormEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &m.form
+	if f.saving {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc":
+		m.form = taskForm{}
+		return m, nil
+	case "tab":
+		f.setFocus(f.focus + 1)
+		return m, nil
+	case "shift+tab":
+		f.setFocus(f.focus - 1)
+		return m, nil
+	case "ctrl+s":
+		return m.submitTaskForm()
+	case "enter":
+		if f.focus != formDescription {
+			return m.submitTaskForm()
+		}
+	case "left", "right":
+		if f.focus == formList || f.focus == formStatus || f.focus == formPriority {
+			if msg.String() == "left" {
+				f.cycle(-1)
+			} else {
+				f.cycle(1)
+			}
+			return m, nil
+		}
+	}
+	return m, f.updateInput(msg)
+}
+
+func (m HomeModel) submitTaskForm() (tea.Model, tea.Cmd) {
+	req, err := m.form.request()
+	if err != nil {
+		return m, components.Notify("Invalid task", err, nil)
+	}
+	m.form.saving = true
+	return m, createTask(m.client, m.form.lists[m.form.list].Id, req)
+}
+
+// handleTaskCreatedEvent adds the new task on top of its column and selects it.
+// On errors the form stays open, so that nothing typed is lost.
+func (m HomeModel) handleTaskCreatedEvent(msg taskCreatedMsg) (tea.Model, tea.Cmd) {
+	m.form.saving = false
+	if msg.err != nil {
+		return m, components.Notify("Creating task "+msg.req.Name, msg.err, nil)
+	}
+	m.form = taskForm{}
+	m.insertTask(msg.task)
+	return m, nil
+}
+
+// insertTask puts a task on top of the column of its status, adding the column when the
+// board has none for it, and moves the selection on it.
+func (m *HomeModel) insertTask(task clients.Task) {
+	state := task.Status.Status
+	col, ok := m.columns[state]
+	if !ok {
+		m.states = append(m.states, state)
+		m.statuses[state] = task.Status
+	}
+	col.tasks = append([]clients.Task{task}, col.tasks...)
+	m.columns[state] = col
+	m.selectTask(task.Id)
+}
+
+func (m HomeModel) viewTaskForm() string {
+	f := m.form
+	labelStyle := lipgloss.NewStyle().Width(12).Foreground(lipgloss.Color("#888888"))
+	focusedStyle := labelStyle.Foreground(ui.Highlight).Bold(true)
+	choice := func(value string, focused bool) string {
+		if focused {
+			return lipgloss.NewStyle().Foreground(ui.Highlight).Render("‹ " + value + " ›")
+		}
+		return "  " + value
+	}
+
+	fields := []struct {
+		label string
+		value string
+	}{
+		{"Name", f.name.View()},
+		{"List", choice(f.lists[f.list].Name, f.focus == formList)},
+		{"Status", choice(f.states[f.status], f.focus == formStatus)},
+		{"Assignees", f.assignees.View()},
+		{"Tags", f.tags.View()},
+		{"Priority", choice(priorityNames[f.priority], f.focus == formPriority)},
+		{"Due date", f.dueDate.View()},
+		{"Description", ""},
+	}
+	rows := []string{ui.TitleStyle.Render("New task"), ""}
+	for i, field := range fields {
+		label := labelStyle.Render(field.label)
+		if i == f.focus {
+			label = focusedStyle.Render(field.label)
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, label, field.value))
+	}
+	rows = append(rows, f.description.View())
+	if f.saving {
+		rows = append(rows, "", lipgloss.NewStyle().Foreground(ui.Warning).Render("Saving..."))
+	}
+	return ui.PanelStyle.Width(m.width - 4).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
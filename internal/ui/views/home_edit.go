@@ -0,0 +1,389 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type taskUpdatedMsg struct {
+	taskId string
+	field  int
+	update clients.TaskUpdate
+	task   clients.Task
+	err    error
+}
+
+const (
+	editName = iota
+	editStatus
+	editAssignees
+	editTags
+	editPriority
+	editDueDate
+	editStartDate
+	editEstimate
+	editFieldCount
+)
+
+var editLabels = [editFieldCount]string{"Name", "Status", "Assignees", "Tags", "Priority", "Due date", "Start date", "Estimate"}
+
+// fieldState is the outcome of the last save of a field.
+type fieldState struct {
+	saving bool
+	err    error
+}
+
+// taskEditor replaces the description of the task modal with the list of editable fields.
+// Every field is saved on its own, so a failure only affects the field that caused it.
+type taskEditor struct {
+	active  bool
+	cursor  int
+	editing bool
+	input   textinput.Model
+	choice  int
+	fields  [editFieldCount]fieldState
+}
+
+func updateTask(client clients.ClickupAPI, taskId string, field int, update clients.TaskUpdate) tea.Cmd {
+	return func() tea.Msg {
+		task, err := client.UpdateTask(context.Background(), taskId, update)
+		return taskUpdatedMsg{taskId: taskId, field: field, update: update, task: task, err: err}
+	}
+}
+
+func isChoiceField(field int) bool {
+	return field == editStatus || field == editPriority
+}
+
+// fieldValue renders the current value of a field, the same way it is typed in the editor.
+func fieldValue(task clients.Task, field int) string {
+	switch field {
+	case editName:
+		return task.Name
+	case editStatus:
+		return task.Status.Status
+	case editAssignees:
+		names := make([]string, len(task.Assignees))
+		for i, user := range task.Assignees {
+			names[i] = user.Username
+		}
+		return strings.Join(names, ", ")
+	case editTags:
+		names := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			names[i] = tag.Name
+		}
+		return strings.Join(names, ", ")
+	case editPriority:
+		if task.Priority == nil {
+			return priorityNames[0]
+		}
+		return task.Priority.Priority
+	case editDueDate:
+		return formatTaskDate(task.DueDate)
+	case editStartDate:
+		return formatTaskDate(task.StartDate)
+	case editEstimate:
+		if task.TimeEstimate == 0 {
+			return ""
+		}
+		return formatHoursToHM(float64(task.TimeEstimate) / float64(time.Hour/time.Millisecond))
+	}
+	return ""
+}
+
+func formatTaskDate(millis string) string {
+	if millis == "" {
+		return ""
+	}
+	return shared.ToDate(millis).Format("2006-01-02")
+}
+
+// parseTaskDate parses a date typed as YYYY-MM-DD, an empty date removes it.
+func parseTaskDate(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return day.UnixMilli(), nil
+}
+
+func (e *taskEditor) startEditing(task clients.Task, states []string) {
+	e.editing = true
+	e.fields[e.cursor].err = nil
+	switch e.cursor {
+	case editStatus:
+		e.choice = max(slices.Index(states, task.Status.Status), 0)
+	case editPriority:
+		e.choice = max(slices.Index(priorityNames, fieldValue(task, editPriority)), 0)
+	default:
+		e.input = textinput.New()
+		e.input.Prompt = ""
+		e.input.CharLimit = 500
+		e.input.SetValue(fieldValue(task, e.cursor))
+		e.input.CursorEnd()
+		e.input.Focus()
+	}
+}
+
+// update validates the edited value and returns the changes to send, false when nothing changed.
+func (e *taskEditor) update(task clients.Task, states []string, users []clients.User) (clients.TaskUpdate, bool, error) {
+	var update clients.TaskUpdate
+	value := strings.TrimSpace(e.input.Value())
+	switch e.cursor {
+	case editName:
+		if value == "" {
+			return update, false, fmt.Errorf("the name is required")
+		}
+		if value == task.Name {
+			return update, false, nil
+		}
+		update.Name = &value
+	case editStatus:
+		status := states[e.choice]
+		if status == task.Status.Status {
+			return update, false, nil
+		}
+		update.Status = &status
+	case editAssignees:
+		var ids []int
+		for _, name := range splitList(value) {
+			id, err := resolveAssignee(users, name)
+			if err != nil {
+				return update, false, err
+			}
+			ids = append(ids, id)
+		}
+		var current []int
+		for _, user := range task.Assignees {
+			if id, err := strconv.Atoi(user.IdString()); err == nil {
+				current = append(current, id)
+				if !slices.Contains(ids, id) {
+					update.RemoveAssignees = append(update.RemoveAssignees, id)
+				}
+			}
+		}
+		for _, id := range ids {
+			if !slices.Contains(current, id) {
+				update.AddAssignees = append(update.AddAssignees, id)
+			}
+		}
+		if len(update.AddAssignees)+len(update.RemoveAssignees) == 0 {
+			return update, false, nil
+		}
+	case editTags:
+		tags := splitList(value)
+		current := splitList(fieldValue(task, editTags))
+		for _, tag := range tags {
+			if !slices.Contains(current, tag) {
+				update.AddTags = append(update.AddTags, tag)
+			}
+		}
+		for _, tag := range current {
+			if !slices.Contains(tags, tag) {
+				update.RemoveTags = append(update.RemoveTags, tag)
+			}
+		}
+		if len(update.AddTags)+len(update.RemoveTags) == 0 {
+			return update, false, nil
+		}
+	case editPriority:
+		if priorityNames[e.choice] == fieldValue(task, editPriority) {
+			return update, false, nil
+		}
+		update.Priority = &e.choice
+	case editDueDate, editStartDate:
+		if value == fieldValue(task, e.cursor) {
+			return update, false, nil
+		}
+		date, err := parseTaskDate(value)
+		if err != nil {
+			return update, false, err
+		}
+		if e.cursor == editDueDate {
+			update.DueDate = &date
+		} else {
+			update.StartDate = &date
+		}
+	case editEstimate:
+		var estimate int64
+		if value != "" {
+			hours, err := shared.ParseHoursInput(value)
+			if err != nil || hours <= 0 {
+				return update, false, fmt.Errorf("invalid estimate %q", value)
+			}
+			estimate = int64(math.Round(hours * float64(time.Hour/time.Millisecond)))
+		}
+		if estimate == task.TimeEstimate {
+			return update, false, nil
+		}
+		update.TimeEstimate = &estimate
+	}
+	return update, true, nil
+}
+
+func (m HomeModel) handleKeyEditorEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := &m.editor
+	if !e.editing {
+		switch msg.String() {
+		case "esc", "e", "q":
+			e.active = false
+		case "up":
+			if e.cursor > 0 {
+				e.cursor--
+			}
+		case "down":
+			if e.cursor < editFieldCount-1 {
+				e.cursor++
+			}
+		case "enter":
+			if !e.fields[e.cursor].saving {
+				e.startEditing(*m.modalTask, m.states)
+			}
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		e.editing = false
+		return m, nil
+	case "enter":
+		update, changed, err := e.update(*m.modalTask, m.states, m.boardUsers())
+		if err != nil {
+			e.fields[e.cursor].err = err
+			return m, nil
+		}
+		e.editing = false
+		if !changed {
+			return m, nil
+		}
+		e.fields[e.cursor] = fieldState{saving: true}
+		return m, updateTask(m.client, m.modalTask.Id, e.cursor, update)
+	case "left", "right":
+		if isChoiceField(e.cursor) {
+			n := len(m.states)
+			if e.cursor == editPriority {
+				n = len(priorityNames)
+			}
+			if msg.String() == "left" {
+				e.choice = (e.choice - 1 + n) % n
+			} else {
+				e.choice = (e.choice + 1) % n
+			}
+			return m, nil
+		}
+	}
+	if isChoiceField(e.cursor) {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return m, cmd
+}
+
+// handleTaskUpdatedEvent shows the saved task in the modal and on the board.
+func (m HomeModel) handleTaskUpdatedEvent(msg taskUpdatedMsg) (tea.Model, tea.Cmd) {
+	current := m.modalTask != nil && m.modalTask.Id == msg.taskId
+	if msg.err != nil {
+		if current {
+			m.editor.fields[msg.field] = fieldState{err: msg.err}
+		}
+		retry := updateTask(m.client, msg.taskId, msg.field, msg.update)
+		return m, components.Notify("Updating "+strings.ToLower(editLabels[msg.field]), msg.err, retry)
+	}
+	if current {
+		m.editor.fields[msg.field] = fieldState{}
+		msg.task.Comments = m.modalTask.Comments
+		m.modalTask = &msg.task
+	}
+	m.replaceTask(msg.task)
+	return m, nil
+}
+
+// replaceTask updates a card on the board, moving it on top of another column when its status changed.
+func (m *HomeModel) replaceTask(task clients.Task) {
+	for _, state := range m.states {
+		col := m.columns[state]
+		idx := slices.IndexFunc(col.tasks, func(t clients.Task) bool { return t.Id == task.Id })
+		if idx < 0 {
+			continue
+		}
+		task.Comments = nil
+		if state == task.Status.Status {
+			col.tasks[idx] = task
+			return
+		}
+		col.tasks = append(col.tasks[:idx:idx], col.tasks[idx+1:]...)
+		m.columns[state] = col
+		m.insertTask(task)
+		return
+	}
+}
+
+// renderTaskDetails renders the fields that have no place in the header of the modal.
+func renderTaskDetails(task clients.Task) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	value := func(field int) string {
+		if v := fieldValue(task, field); v != "" {
+			return v
+		}
+		return "-"
+	}
+	return dimStyle.Render(fmt.Sprintf("⚑ %s   Due %s   Start %s   Estimate %s",
+		value(editPriority), value(editDueDate), value(editStartDate), value(editEstimate)))
+}
+
+// renderEditor renders the editable fields of the task in a box of the given size.
+func (m HomeModel) renderEditor(width int, height int) string {
+	e := m.editor
+	labelStyle := lipgloss.NewStyle().Width(14).Foreground(lipgloss.Color("#888888"))
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	savingStyle := lipgloss.NewStyle().Foreground(ui.Warning)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.Error)
+
+	var rows []string
+	for field := 0; field < editFieldCount; field++ {
+		value := fieldValue(*m.modalTask, field)
+		if e.editing && field == e.cursor {
+			switch field {
+			case editStatus:
+				value = cursorStyle.Render("‹ " + m.states[e.choice] + " ›")
+			case editPriority:
+				value = cursorStyle.Render("‹ " + priorityNames[e.choice] + " ›")
+			default:
+				value = e.input.View()
+			}
+		}
+		prefix := "  "
+		label := labelStyle.Render(editLabels[field])
+		if field == e.cursor {
+			prefix = cursorStyle.Render("> ")
+			label = labelStyle.Foreground(ui.Highlight).Render(editLabels[field])
+		}
+		state := ""
+		if e.fields[field].saving {
+			state = savingStyle.Render("  saving...")
+		} else if err := e.fields[field].err; err != nil {
+			state = errorStyle.Render("  ✗ " + err.Error())
+		}
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(width).Render(prefix+label+value+state))
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
@@ -0,0 +1,193 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+// externalEdit is a description, a comment or a reply being written in $EDITOR.
+type externalEdit struct {
+	taskId  string
+	comment bool
+	replyTo string // id of the comment starting the thread, for replies
+	path    string
+	// base is the description the editor was opened with, mine the edited one and
+	// theirs the one found on ClickUp when it changed in the meantime.
+	base     string
+	mine     string
+	theirs   string
+	conflict bool
+}
+
+type editorClosedMsg struct {
+	edit externalEdit
+	err  error
+}
+
+type descriptionCheckedMsg struct {
+	edit externalEdit
+	task clients.Task
+	err  error
+}
+
+type descriptionSavedMsg struct {
+	taskId string
+	text   string
+	task   clients.Task
+	err    error
+}
+
+// editorCommand returns the command of the user's editor, $VISUAL or $EDITOR, falling back to vi.
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// openEditor writes the text to a temporary file and suspends the program while
+// the editor is open on it.
+func openEditor(edit externalEdit, text string) (externalEdit, tea.Cmd) {
+	file, err := os.CreateTemp("", "clickup-*.md")
+	if err != nil {
+		return edit, components.Notify("Opening editor", err, nil)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		return edit, components.Notify("Opening editor", err, nil)
+	}
+	edit.path = file.Name()
+	return edit, tea.ExecProcess(editorCommand(edit.path), editorFinished(edit))
+}
+
+func editorFinished(edit externalEdit) tea.ExecCallback {
+	return func(err error) tea.Msg {
+		return editorClosedMsg{edit: edit, err: err}
+	}
+}
+
+func checkDescription(client clients.ClickupAPI, edit externalEdit) tea.Cmd {
+	return func() tea.Msg {
+		task, err := client.RefreshTask(context.Background(), edit.taskId)
+		return descriptionCheckedMsg{edit: edit, task: task, err: err}
+	}
+}
+
+func saveDescription(client clients.ClickupAPI, taskId string, text string) tea.Cmd {
+	return func() tea.Msg {
+		task, err := client.UpdateTask(context.Background(), taskId, clients.TaskUpdate{Description: &text})
+		return descriptionSavedMsg{taskId: taskId, text: text, task: task, err: err}
+	}
+}
+
+func (m HomeModel) editDescription() (tea.Model, tea.Cmd) {
+	edit := externalEdit{taskId: m.modalTask.Id, base: m.modalTask.Description}
+	var cmd tea.Cmd
+	m.external, cmd = openEditor(edit, edit.base)
+	return m, cmd
+}
+
+func (m HomeModel) writeComment() (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.external, cmd = openEditor(externalEdit{taskId: m.modalTask.Id, comment: true}, "")
+	return m, cmd
+}
+
+// handleEditorClosedEvent sends a comment right away. A description is first compared
+// with the one on ClickUp, that may have changed while the editor was open.
+func (m HomeModel) handleEditorClosedEvent(msg editorClosedMsg) (tea.Model, tea.Cmd) {
+	data, readErr := os.ReadFile(msg.edit.path)
+	os.Remove(msg.edit.path)
+	if err := errors.Join(msg.err, readErr); err != nil {
+		return m, components.Notify("Running editor", err, nil)
+	}
+	text := strings.TrimRight(string(data), "\n")
+	if msg.edit.comment {
+		if strings.TrimSpace(text) == "" {
+			return m, nil
+		}
+		return m, postComment(m.client, msg.edit.taskId, msg.edit.replyTo, text)
+	}
+	if text == strings.TrimRight(msg.edit.base, "\n") {
+		return m, nil
+	}
+	msg.edit.mine = text
+	return m, checkDescription(m.client, msg.edit)
+}
+
+func (m HomeModel) handleDescriptionCheckedEvent(msg descriptionCheckedMsg) (tea.Model, tea.Cmd) {
+	edit := msg.edit
+	if msg.err != nil {
+		return m, components.Notify("Checking task", msg.err, checkDescription(m.client, edit))
+	}
+	theirs := strings.TrimRight(msg.task.Description, "\n")
+	if theirs == strings.TrimRight(edit.base, "\n") {
+		return m, saveDescription(m.client, edit.taskId, edit.mine)
+	}
+	if theirs == edit.mine {
+		return m.handleDescriptionSavedEvent(descriptionSavedMsg{taskId: edit.taskId, text: edit.mine, task: msg.task})
+	}
+	edit.theirs = theirs
+	edit.conflict = true
+	m.external = edit
+	return m, nil
+}
+
+// handleKeyConflictEvent lets the user choose how to solve a conflict on the description:
+// a clean merge is saved, a merge with conflicts goes back to the editor with the markers.
+func (m HomeModel) handleKeyConflictEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	edit := m.external
+	switch msg.String() {
+	case "m":
+		merged, ok := shared.Merge3(edit.base, edit.mine, edit.theirs)
+		m.external = externalEdit{}
+		if ok {
+			return m, saveDescription(m.client, edit.taskId, merged)
+		}
+		// The merged text becomes the new local version of the ClickUp one.
+		var cmd tea.Cmd
+		m.external, cmd = openEditor(externalEdit{taskId: edit.taskId, base: edit.theirs}, merged)
+		return m, cmd
+	case "o":
+		m.external = externalEdit{}
+		return m, saveDescription(m.client, edit.taskId, edit.mine)
+	case "esc", "c":
+		m.external = externalEdit{}
+	}
+	return m, nil
+}
+
+func (m HomeModel) handleDescriptionSavedEvent(msg descriptionSavedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m, components.Notify("Saving description", msg.err, saveDescription(m.client, msg.taskId, msg.text))
+	}
+	if m.modalTask != nil && m.modalTask.Id == msg.taskId {
+		msg.task.Comments = m.modalTask.Comments
+		m.modalTask = &msg.task
+		m.setModalDescription()
+	}
+	m.replaceTask(msg.task)
+	return m, nil
+}
+
+// renderConflict explains the conflict in place of the description.
+func (m HomeModel) renderConflict(width int, height int) string {
+	warning := lipgloss.NewStyle().Foreground(ui.Warning).Bold(true).Render("The description changed on ClickUp while you were editing it.")
+	help := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("[m] Merge both versions    [o] Overwrite with yours    [esc] Discard yours")
+	return lipgloss.NewStyle().Width(width).Height(height).Render(lipgloss.JoinVertical(lipgloss.Left, warning, "", help))
+}
@@ -0,0 +1,501 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type customFieldSetMsg struct {
+	taskId string
+	field  clients.CustomField
+	value  any
+	task   clients.Task
+	err    error
+}
+
+// fieldsPane replaces the description of the task modal with its custom fields.
+// Like the task editor, every field is saved on its own.
+type fieldsPane struct {
+	active  bool
+	cursor  int
+	editing bool
+	input   textinput.Model
+	choice  int    // drop_down: selected option, the last one is none; labels: option under the cursor
+	picked  []bool // labels: options selected while editing
+	fields  map[string]fieldState
+}
+
+func setCustomField(client clients.ClickupAPI, taskId string, field clients.CustomField, value any) tea.Cmd {
+	return func() tea.Msg {
+		task, err := client.SetCustomField(context.Background(), taskId, field, value)
+		return customFieldSetMsg{taskId: taskId, field: field, value: value, task: task, err: err}
+	}
+}
+
+// findCustomField returns a custom field of a task by name, ignoring the case.
+func findCustomField(task clients.Task, name string) (clients.CustomField, bool) {
+	i := slices.IndexFunc(task.CustomFields, func(f clients.CustomField) bool { return strings.EqualFold(f.Name, name) })
+	if i < 0 {
+		return clients.CustomField{}, false
+	}
+	return task.CustomFields[i], true
+}
+
+// formatNumber renders a number with the given decimals, or as few as needed when nil.
+func formatNumber(n float64, precision *int) string {
+	if precision != nil {
+		return strconv.FormatFloat(n, 'f', *precision, 64)
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// customFieldDisplay renders the value of a custom field, empty when it has none.
+func customFieldDisplay(f clients.CustomField) string {
+	v := f.Typed
+	if !v.Set && f.Type != clients.FieldCheckbox {
+		return ""
+	}
+	switch f.Type {
+	case clients.FieldDropDown, clients.FieldLabels:
+		names := make([]string, 0, len(v.Options))
+		for _, id := range v.Options {
+			if o, ok := f.Option(id); ok {
+				names = append(names, o.Title())
+			}
+		}
+		return strings.Join(names, ", ")
+	case clients.FieldNumber:
+		return formatNumber(v.Number, f.TypeConfig.Precision)
+	case clients.FieldCurrency:
+		precision := 2
+		if f.TypeConfig.Precision != nil {
+			precision = *f.TypeConfig.Precision
+		}
+		return strings.TrimSpace(formatNumber(v.Number, &precision) + " " + f.TypeConfig.CurrencyType)
+	case clients.FieldDate:
+		return time.UnixMilli(v.Date).Format("2006-01-02")
+	case clients.FieldCheckbox:
+		if v.Checked {
+			return "✓"
+		}
+		return "✗"
+	case clients.FieldUsers:
+		names := make([]string, len(v.Users))
+		for i, user := range v.Users {
+			names[i] = user.Username
+		}
+		return strings.Join(names, ", ")
+	case clients.FieldTasks:
+		names := make([]string, len(v.Tasks))
+		for i, task := range v.Tasks {
+			names[i] = task.Name
+			if names[i] == "" {
+				names[i] = task.Id
+			}
+		}
+		return strings.Join(names, ", ")
+	case clients.FieldEmoji:
+		emoji := "★"
+		if code, err := strconv.ParseInt(f.TypeConfig.CodePoint, 16, 32); err == nil {
+			emoji = string(rune(code))
+		}
+		rating := strings.Repeat(emoji, int(v.Number))
+		if f.TypeConfig.Count > 0 {
+			rating += fmt.Sprintf(" %d/%d", int(v.Number), f.TypeConfig.Count)
+		}
+		return rating
+	case clients.FieldManualProgress:
+		if start, end := f.TypeConfig.Start, f.TypeConfig.End; start != nil && end != nil && *end != *start {
+			return fmt.Sprintf("%s (%.0f%%)", formatNumber(v.Number, nil), (v.Number-*start)/(*end-*start)*100)
+		}
+		return formatNumber(v.Number, nil)
+	case clients.FieldAutomaticProgress:
+		return fmt.Sprintf("%.0f%%", v.Number)
+	}
+	// Long texts are shown on their first line.
+	text, _, _ := strings.Cut(v.Text, "\n")
+	return text
+}
+
+// customFieldInput renders the value of a field the way it is typed in the editor.
+func customFieldInput(f clients.CustomField) string {
+	v := f.Typed
+	if !v.Set {
+		return ""
+	}
+	switch f.Type {
+	case clients.FieldNumber, clients.FieldCurrency, clients.FieldEmoji, clients.FieldManualProgress:
+		return formatNumber(v.Number, nil)
+	case clients.FieldTasks:
+		ids := make([]string, len(v.Tasks))
+		for i, task := range v.Tasks {
+			ids[i] = task.Id
+		}
+		return strings.Join(ids, ", ")
+	case clients.FieldText:
+		return v.Text
+	}
+	return customFieldDisplay(f)
+}
+
+// addRemove returns the change from the current ids to the new ones, as ClickUp expects
+// it for users and tasks fields.
+func addRemove[T comparable](current []T, next []T) map[string][]T {
+	change := map[string][]T{"add": {}, "rem": {}}
+	for _, id := range next {
+		if !slices.Contains(current, id) {
+			change["add"] = append(change["add"], id)
+		}
+	}
+	for _, id := range current {
+		if !slices.Contains(next, id) {
+			change["rem"] = append(change["rem"], id)
+		}
+	}
+	return change
+}
+
+// parseCustomField parses the value typed for a field into the value sent to ClickUp.
+// An empty value removes it and is returned as nil.
+func parseCustomField(f clients.CustomField, input string, users []clients.User) (any, error) {
+	input = strings.TrimSpace(input)
+	switch f.Type {
+	case clients.FieldUsers:
+		var current, next []int
+		for _, user := range f.Typed.Users {
+			id, _ := strconv.Atoi(user.IdString())
+			current = append(current, id)
+		}
+		users = append(slices.Clone(users), f.Typed.Users...)
+		for _, name := range splitList(input) {
+			id, err := resolveAssignee(users, name)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, id)
+		}
+		return addRemove(current, next), nil
+	case clients.FieldTasks:
+		var current []string
+		for _, task := range f.Typed.Tasks {
+			current = append(current, task.Id)
+		}
+		return addRemove(current, splitList(input)), nil
+	}
+	if input == "" {
+		return nil, nil
+	}
+	switch f.Type {
+	case clients.FieldNumber, clients.FieldCurrency, clients.FieldEmoji, clients.FieldManualProgress:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(input, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", input)
+		}
+		switch f.Type {
+		case clients.FieldEmoji:
+			if n != float64(int(n)) || n < 0 || (f.TypeConfig.Count > 0 && int(n) > f.TypeConfig.Count) {
+				return nil, fmt.Errorf("invalid rating %q, expected a whole number up to %d", input, f.TypeConfig.Count)
+			}
+		case clients.FieldManualProgress:
+			return map[string]float64{"current": n}, nil
+		}
+		return n, nil
+	case clients.FieldDate:
+		return parseTaskDate(input)
+	case clients.FieldEmail:
+		if !strings.Contains(input, "@") {
+			return nil, fmt.Errorf("invalid email %q", input)
+		}
+	}
+	return input, nil
+}
+
+// fieldPlaceholder tells how the value of a field is typed.
+func fieldPlaceholder(f clients.CustomField) string {
+	switch f.Type {
+	case clients.FieldDate:
+		return "YYYY-MM-DD"
+	case clients.FieldUsers:
+		return "usernames or initials, comma separated"
+	case clients.FieldTasks:
+		return "task ids, comma separated"
+	case clients.FieldNumber, clients.FieldCurrency, clients.FieldEmoji, clients.FieldManualProgress:
+		return "number"
+	}
+	return "empty to clear"
+}
+
+// startEditing prepares the editor of the selected field with its current value.
+func (p *fieldsPane) startEditing(f clients.CustomField) {
+	p.editing = true
+	p.fields[f.Id] = fieldState{}
+	options := f.TypeConfig.Options
+	switch f.Type {
+	case clients.FieldDropDown:
+		p.choice = len(options)
+		if len(f.Typed.Options) > 0 {
+			if i := slices.IndexFunc(options, func(o clients.CustomFieldOption) bool { return o.Id == f.Typed.Options[0] }); i >= 0 {
+				p.choice = i
+			}
+		}
+	case clients.FieldLabels:
+		p.choice = 0
+		p.picked = make([]bool, len(options))
+		for i, o := range options {
+			p.picked[i] = slices.Contains(f.Typed.Options, o.Id)
+		}
+	default:
+		p.input = newFormInput(fieldPlaceholder(f), 500)
+		p.input.SetValue(customFieldInput(f))
+		p.input.CursorEnd()
+		p.input.Focus()
+	}
+}
+
+// value returns the value sent to ClickUp for the field being edited.
+func (p *fieldsPane) value(f clients.CustomField, users []clients.User) (any, error) {
+	options := f.TypeConfig.Options
+	switch f.Type {
+	case clients.FieldDropDown:
+		if p.choice >= len(options) {
+			return nil, nil
+		}
+		return options[p.choice].Id, nil
+	case clients.FieldLabels:
+		var ids []string
+		for i, picked := range p.picked {
+			if picked {
+				ids = append(ids, options[i].Id)
+			}
+		}
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		return ids, nil
+	}
+	return parseCustomField(f, p.input.Value(), users)
+}
+
+func (m HomeModel) openFieldsPane() (tea.Model, tea.Cmd) {
+	m.fields.active = true
+	if m.fields.fields == nil {
+		m.fields.fields = make(map[string]fieldState)
+	}
+	return m, nil
+}
+
+func (m HomeModel) handleKeyFieldsEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.fields
+	fields := m.modalTask.CustomFields
+	if len(fields) == 0 {
+		if msg.String() == "esc" || msg.String() == "f" {
+			p.active = false
+		}
+		return m, nil
+	}
+	p.cursor = min(p.cursor, len(fields)-1)
+	field := fields[p.cursor]
+	if !p.editing {
+		switch msg.String() {
+		case "esc", "f":
+			p.active = false
+		case "up":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+		case "down":
+			if p.cursor < len(fields)-1 {
+				p.cursor++
+			}
+		case "p":
+			return m.togglePinnedField(field.Name)
+		case "enter":
+			if field.ReadOnly() || p.fields[field.Id].saving {
+				return m, nil
+			}
+			if field.Type == clients.FieldCheckbox {
+				p.fields[field.Id] = fieldState{saving: true}
+				return m, setCustomField(m.client, m.modalTask.Id, field, !field.Typed.Checked)
+			}
+			p.startEditing(field)
+		}
+		return m, nil
+	}
+
+	options := field.TypeConfig.Options
+	switch msg.String() {
+	case "esc":
+		p.editing = false
+		return m, nil
+	case "enter":
+		value, err := p.value(field, m.boardUsers())
+		if err != nil {
+			p.fields[field.Id] = fieldState{err: err}
+			return m, nil
+		}
+		p.editing = false
+		p.fields[field.Id] = fieldState{saving: true}
+		return m, setCustomField(m.client, m.modalTask.Id, field, value)
+	case "left", "right":
+		n := len(options)
+		if field.Type == clients.FieldDropDown {
+			n++ // none
+		}
+		if field.Type != clients.FieldDropDown && field.Type != clients.FieldLabels || n == 0 {
+			break
+		}
+		if msg.String() == "left" {
+			p.choice = (p.choice - 1 + n) % n
+		} else {
+			p.choice = (p.choice + 1) % n
+		}
+		return m, nil
+	case " ":
+		if field.Type == clients.FieldLabels && p.choice < len(p.picked) {
+			p.picked[p.choice] = !p.picked[p.choice]
+			return m, nil
+		}
+	}
+	if field.Type == clients.FieldDropDown || field.Type == clients.FieldLabels {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+// handleCustomFieldSetEvent shows the saved task in the modal and on the board.
+func (m HomeModel) handleCustomFieldSetEvent(msg customFieldSetMsg) (tea.Model, tea.Cmd) {
+	current := m.modalTask != nil && m.modalTask.Id == msg.taskId && m.fields.fields != nil
+	if msg.err != nil {
+		if current {
+			m.fields.fields[msg.field.Id] = fieldState{err: msg.err}
+		}
+		retry := setCustomField(m.client, msg.taskId, msg.field, msg.value)
+		return m, components.Notify("Updating "+msg.field.Name, msg.err, retry)
+	}
+	if current {
+		m.fields.fields[msg.field.Id] = fieldState{}
+		msg.task.Comments = m.modalTask.Comments
+		m.modalTask = &msg.task
+	}
+	m.replaceTask(msg.task)
+	return m, nil
+}
+
+// togglePinnedField shows or hides a custom field on the cards of the view.
+func (m HomeModel) togglePinnedField(name string) (tea.Model, tea.Cmd) {
+	layout := m.layout
+	if i := slices.IndexFunc(layout.Fields, func(f string) bool { return strings.EqualFold(f, name) }); i >= 0 {
+		layout.Fields = slices.Delete(slices.Clone(layout.Fields), i, i+1)
+	} else {
+		layout.Fields = append(slices.Clone(layout.Fields), name)
+	}
+	m.layout = layout
+	return m, saveLayout(layout)
+}
+
+func (m HomeModel) isPinned(name string) bool {
+	return slices.ContainsFunc(m.layout.Fields, func(f string) bool { return strings.EqualFold(f, name) })
+}
+
+// renderCardFields renders the custom fields shown on the cards of the view, in a line.
+func (m HomeModel) renderCardFields(task clients.Task) string {
+	var parts []string
+	for _, name := range m.layout.Fields {
+		if f, ok := findCustomField(task, name); ok {
+			if value := customFieldDisplay(f); value != "" {
+				parts = append(parts, f.Name+": "+value)
+			}
+		}
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).MaxWidth(columnWidth - 5).Render(strings.Join(parts, " · "))
+}
+
+// renderFields renders the custom fields of the modal task in a box of the given size.
+func (m HomeModel) renderFields(width int, height int) string {
+	p := m.fields
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	labelStyle := lipgloss.NewStyle().Width(20).Foreground(lipgloss.Color("#888888"))
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	savingStyle := lipgloss.NewStyle().Foreground(ui.Warning)
+	errorStyle := lipgloss.NewStyle().Foreground(ui.Error)
+
+	fields := m.modalTask.CustomFields
+	if len(fields) == 0 {
+		return lipgloss.NewStyle().Width(width).Height(height).Render(dimStyle.Render("No custom fields"))
+	}
+	var rows []string
+	for i, f := range fields {
+		value := customFieldDisplay(f)
+		if p.editing && i == p.cursor {
+			value = p.renderEditing(f)
+		} else if f.ReadOnly() {
+			value += dimStyle.Render("  (computed)")
+		}
+		prefix := "  "
+		label := labelStyle.Render(f.Name)
+		if i == p.cursor {
+			prefix = cursorStyle.Render("> ")
+			label = labelStyle.Foreground(ui.Highlight).Render(f.Name)
+		}
+		if m.isPinned(f.Name) {
+			value += dimStyle.Render("  📌")
+		}
+		state := ""
+		if p.fields[f.Id].saving {
+			state = savingStyle.Render("  saving...")
+		} else if err := p.fields[f.Id].err; err != nil {
+			state = errorStyle.Render("  ✗ " + err.Error())
+		}
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(width).Render(prefix+label+value+state))
+	}
+	start := 0
+	if len(rows) > height {
+		start = min(max(p.cursor-height/2, 0), len(rows)-height)
+	}
+	rows = rows[start:min(start+height, len(rows))]
+	return lipgloss.NewStyle().Width(width).Height(height).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// renderEditing renders the value of the field being edited: the choices of a drop-down
+// or labels field, or the text input.
+func (p fieldsPane) renderEditing(f clients.CustomField) string {
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	options := f.TypeConfig.Options
+	switch f.Type {
+	case clients.FieldDropDown:
+		name := "none"
+		if p.choice < len(options) {
+			name = options[p.choice].Title()
+		}
+		return cursorStyle.Render("‹ " + name + " ›")
+	case clients.FieldLabels:
+		var labels []string
+		for i, o := range options {
+			label := o.Title()
+			if p.picked[i] {
+				label = "[x] " + label
+			} else {
+				label = "[ ] " + label
+			}
+			if i == p.choice {
+				label = cursorStyle.Render(label)
+			}
+			labels = append(labels, label)
+		}
+		return strings.Join(labels, "  ")
+	}
+	return p.input.View()
+}
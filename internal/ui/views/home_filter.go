@@ -0,0 +1,432 @@
+package views
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+// filterTerm is a word of a board filter. Words without a field are matched fuzzily.
+type filterTerm struct {
+	field  string // "", "@", "tag", "list", "status", "due" or "field"
+	value  string
+	negate bool
+	// due filters: op is '<' or '>' with a number of days, or the value is "overdue",
+	// "today" or "none".
+	op   byte
+	days int
+	// field filters: the name of the custom field, op is '=', '<', '>' or 0 for any value.
+	name string
+}
+
+// taskFilter hides the cards that do not match all its terms, such as
+// `@me tag:bug list:Backend -status:done due:<7d field:Sprint=12 login`.
+type taskFilter struct {
+	query string
+	terms []filterTerm
+}
+
+var filterFields = []string{"tag", "list", "status", "due", "field"}
+
+func (f taskFilter) active() bool {
+	return len(f.terms) > 0
+}
+
+// parseTaskFilter parses a filter query. Values containing spaces can be quoted: list:"Sprint 12".
+func parseTaskFilter(query string) (taskFilter, error) {
+	f := taskFilter{query: strings.TrimSpace(query)}
+	for _, word := range splitFilterWords(f.query) {
+		term := filterTerm{}
+		if strings.HasPrefix(word, "-") && len(word) > 1 {
+			term.negate = true
+			word = word[1:]
+		}
+		switch {
+		case strings.HasPrefix(word, "@") && len(word) > 1:
+			term.field, term.value = "@", word[1:]
+		case strings.Contains(word, ":"):
+			field, value, _ := strings.Cut(word, ":")
+			field = strings.ToLower(field)
+			if !slices.Contains(filterFields, field) {
+				return f, fmt.Errorf("unknown filter %q, use %s:", field, strings.Join(filterFields, ":, "))
+			}
+			if value == "" {
+				return f, fmt.Errorf("missing value for %s:", field)
+			}
+			term.field, term.value = field, strings.Trim(value, `"`)
+			switch field {
+			case "due":
+				if err := term.parseDue(); err != nil {
+					return f, err
+				}
+			case "field":
+				if err := term.parseField(value); err != nil {
+					return f, err
+				}
+			}
+		default:
+			term.value = strings.Trim(word, `"`)
+		}
+		f.terms = append(f.terms, term)
+	}
+	return f, nil
+}
+
+// splitFilterWords splits a query on spaces, keeping quoted values together.
+func splitFilterWords(query string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			word.WriteRune(r)
+		case r == ' ' && !quoted:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// parseDue accepts <7d, >2w, overdue, today and none.
+func (t *filterTerm) parseDue() error {
+	value := strings.ToLower(t.value)
+	switch value {
+	case "overdue", "today", "none":
+		t.value = value
+		return nil
+	}
+	if len(value) < 3 || (value[0] != '<' && value[0] != '>') {
+		return fmt.Errorf("invalid due filter %q, use due:<7d, due:>2w, due:today, due:overdue or due:none", t.value)
+	}
+	n, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid due filter %q, use due:<7d, due:>2w, due:today, due:overdue or due:none", t.value)
+	}
+	switch value[len(value)-1] {
+	case 'd':
+		t.days = n
+	case 'w':
+		t.days = n * 7
+	default:
+		return fmt.Errorf("invalid due filter %q, the unit is d (days) or w (weeks)", t.value)
+	}
+	t.op = value[0]
+	return nil
+}
+
+// parseField accepts field:Name, field:Name=value, field:Name>3 and field:Name<2025-01-01.
+// The name can be quoted: field:"Story points">3.
+func (t *filterTerm) parseField(spec string) error {
+	start := 0
+	if strings.HasPrefix(spec, `"`) {
+		start = strings.Index(spec[1:], `"`) + 2
+	}
+	t.name, t.value = strings.Trim(spec, `"`), ""
+	if i := strings.IndexAny(spec[start:], "=<>"); i >= 0 {
+		i += start
+		t.name, t.op, t.value = strings.Trim(spec[:i], `"`), spec[i], strings.Trim(spec[i+1:], `"`)
+		if t.value == "" {
+			return fmt.Errorf("missing value for field:%s%c", t.name, t.op)
+		}
+	}
+	if t.name == "" {
+		return fmt.Errorf("missing custom field name, use field:Name=value")
+	}
+	if t.op == '<' || t.op == '>' {
+		if _, err := strconv.ParseFloat(t.value, 64); err != nil {
+			if _, err := parseTaskDate(t.value); err != nil {
+				return fmt.Errorf("invalid field filter %q, compare with a number or a YYYY-MM-DD date", t.value)
+			}
+		}
+	}
+	return nil
+}
+
+// match reports whether a task matches all the terms. userId is the id of the user
+// for @me and now is the time due dates are compared with.
+func (f taskFilter) match(task clients.Task, userId string, now time.Time) bool {
+	for _, term := range f.terms {
+		if term.match(task, userId, now) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t filterTerm) match(task clients.Task, userId string, now time.Time) bool {
+	switch t.field {
+	case "@":
+		me := strings.EqualFold(t.value, "me")
+		return slices.ContainsFunc(task.Assignees, func(user clients.User) bool {
+			if me {
+				return user.IdString() == userId
+			}
+			return strings.EqualFold(user.Initials, t.value) || strings.Contains(strings.ToLower(user.Username), strings.ToLower(t.value))
+		})
+	case "tag":
+		return slices.ContainsFunc(task.Tags, func(tag clients.Tag) bool { return strings.EqualFold(tag.Name, t.value) })
+	case "list":
+		return strings.Contains(strings.ToLower(task.List.Name), strings.ToLower(t.value))
+	case "status":
+		return strings.EqualFold(task.Status.Status, t.value)
+	case "due":
+		return t.matchDue(task.DueDate, now)
+	case "field":
+		field, ok := findCustomField(task, t.name)
+		return ok && t.matchField(field)
+	}
+	fields := []string{task.Name, task.CustomId, task.List.Name}
+	for _, tag := range task.Tags {
+		fields = append(fields, tag.Name)
+	}
+	for _, user := range task.Assignees {
+		fields = append(fields, user.Initials)
+	}
+	return slices.ContainsFunc(fields, func(field string) bool {
+		_, ok := shared.FuzzyMatch(t.value, field)
+		return ok
+	})
+}
+
+func (t filterTerm) matchDue(dueDate string, now time.Time) bool {
+	if dueDate == "" {
+		return t.value == "none"
+	}
+	due := shared.ToDate(dueDate)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch t.value {
+	case "none":
+		return false
+	case "overdue":
+		return due.Before(now)
+	case "today":
+		return !due.Before(today) && due.Before(today.AddDate(0, 0, 1))
+	}
+	limit := today.AddDate(0, 0, t.days+1)
+	if t.op == '<' {
+		return due.Before(limit)
+	}
+	return !due.Before(limit)
+}
+
+// matchField compares the value of a custom field: options, users and tasks match by
+// name, numbers and dates can also be compared with < and >.
+func (t filterTerm) matchField(field clients.CustomField) bool {
+	v := field.Typed
+	if field.Type == clients.FieldCheckbox {
+		checked, err := strconv.ParseBool(t.value)
+		return v.Checked == (t.op == 0 || err == nil && checked)
+	}
+	if !v.Set {
+		return false
+	}
+	switch t.op {
+	case 0:
+		return true
+	case '<', '>':
+		var value, limit float64
+		switch field.Type {
+		case clients.FieldDate:
+			day, err := parseTaskDate(t.value)
+			if err != nil {
+				return false
+			}
+			value, limit = float64(v.Date), float64(day)
+		case clients.FieldNumber, clients.FieldCurrency, clients.FieldEmoji, clients.FieldManualProgress, clients.FieldAutomaticProgress:
+			n, err := strconv.ParseFloat(t.value, 64)
+			if err != nil {
+				return false
+			}
+			value, limit = v.Number, n
+		default:
+			return false
+		}
+		if t.op == '<' {
+			return value < limit
+		}
+		return value > limit
+	}
+	var values []string
+	switch field.Type {
+	case clients.FieldDropDown, clients.FieldLabels:
+		for _, id := range v.Options {
+			if o, ok := field.Option(id); ok {
+				values = append(values, o.Title())
+			}
+		}
+	case clients.FieldUsers:
+		for _, user := range v.Users {
+			values = append(values, user.Username, user.Initials)
+		}
+	case clients.FieldTasks:
+		for _, task := range v.Tasks {
+			values = append(values, task.Name, task.Id)
+		}
+	case clients.FieldNumber, clients.FieldCurrency, clients.FieldEmoji, clients.FieldManualProgress, clients.FieldAutomaticProgress:
+		n, err := strconv.ParseFloat(t.value, 64)
+		return err == nil && n == v.Number
+	case clients.FieldDate:
+		day, err := parseTaskDate(t.value)
+		return err == nil && time.UnixMilli(v.Date).Format("2006-01-02") == time.UnixMilli(day).Format("2006-01-02")
+	default:
+		values = append(values, v.Text)
+	}
+	return slices.ContainsFunc(values, func(value string) bool { return strings.EqualFold(value, t.value) })
+}
+
+// filterBar is the prompt where the board filter is typed, or saved with a name.
+type filterBar struct {
+	active bool
+	saving bool
+	input  textinput.Model
+	err    error
+	saved  int // position in the saved filters browsed with ↑/↓, -1 for none
+}
+
+func (m HomeModel) openFilterBar() (tea.Model, tea.Cmd) {
+	b := filterBar{active: true, saved: -1}
+	b.input = textinput.New()
+	b.input.Prompt = "Filter: "
+	b.input.Placeholder = "text @me tag:bug list:Backend -status:done due:<7d field:Sprint=12"
+	b.input.CharLimit = 200
+	b.input.SetValue(m.filter.query)
+	b.input.CursorEnd()
+	b.input.Focus()
+	m.filterBar = b
+	return m, nil
+}
+
+// setFilter applies a filter to the board, moving the selection back to the top of the columns.
+func (m *HomeModel) setFilter(f taskFilter) {
+	m.filter = f
+	m.selectedTask = 0
+	m.offsets = make(map[string]int)
+}
+
+func savedFilterNames() []string {
+	filters := clients.GetConfig().Filters
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// handleKeyFilterEvent filters the board while the query is typed. Enter keeps the
+// filter, esc removes it, ↑/↓ browse the saved filters and ctrl+s saves the query.
+func (m HomeModel) handleKeyFilterEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := &m.filterBar
+	if b.saving {
+		return m.handleKeySaveFilterEvent(msg)
+	}
+	switch msg.String() {
+	case "esc":
+		b.active = false
+		m.setFilter(taskFilter{})
+		return m, nil
+	case "enter":
+		if b.err == nil {
+			b.active = false
+		}
+		return m, nil
+	case "ctrl+s":
+		if b.err != nil || !m.filter.active() {
+			return m, nil
+		}
+		b.saving = true
+		b.input.Prompt = "Save filter as: "
+		b.input.Placeholder = "name"
+		b.input.SetValue("")
+		return m, nil
+	case "up", "down":
+		names := savedFilterNames()
+		if len(names) == 0 {
+			return m, nil
+		}
+		switch {
+		case msg.String() == "down":
+			b.saved = (b.saved + 1) % len(names)
+		case b.saved <= 0:
+			b.saved = len(names) - 1
+		default:
+			b.saved--
+		}
+		b.input.SetValue(clients.GetConfig().Filters[names[b.saved]])
+		b.input.CursorEnd()
+	default:
+		b.input, _ = b.input.Update(msg)
+	}
+	f, err := parseTaskFilter(b.input.Value())
+	b.err = err
+	if err == nil && f.query != m.filter.query {
+		m.setFilter(f)
+	}
+	return m, nil
+}
+
+// handleKeySaveFilterEvent saves the current filter in the config under the typed name.
+func (m HomeModel) handleKeySaveFilterEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := &m.filterBar
+	switch msg.String() {
+	case "esc", "enter":
+		name := strings.TrimSpace(b.input.Value())
+		b.saving = false
+		b.input.Prompt = "Filter: "
+		b.input.SetValue(m.filter.query)
+		b.input.CursorEnd()
+		if msg.String() == "esc" || name == "" {
+			return m, nil
+		}
+		b.active = false
+		config := clients.GetConfig()
+		filters := make(map[string]string, len(config.Filters)+1)
+		for k, v := range config.Filters {
+			filters[k] = v
+		}
+		filters[name] = m.filter.query
+		config.Filters = filters
+		if err := clients.SavePreferences(config); err != nil {
+			return m, components.Notify("Saving filter", err, nil)
+		}
+		return m, nil
+	}
+	b.input, _ = b.input.Update(msg)
+	return m, nil
+}
+
+// renderFilterLine shows the filter bar while it is open, or the filter applied to the board.
+func (m HomeModel) renderFilterLine() string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	if m.filterBar.active {
+		line := m.filterBar.input.View()
+		if m.filterBar.err != nil {
+			line += lipgloss.NewStyle().Foreground(ui.Error).Render("  ✗ " + m.filterBar.err.Error())
+		}
+		return lipgloss.NewStyle().MaxWidth(m.width).Render(line)
+	}
+	if m.filter.active() {
+		return lipgloss.NewStyle().MaxWidth(m.width).Render(dimStyle.Render("Filter: ") + lipgloss.NewStyle().Foreground(ui.Highlight).Render(m.filter.query))
+	}
+	return ""
+}
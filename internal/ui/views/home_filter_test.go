@@ -0,0 +1,135 @@
+package views
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mceck/clickup-tui/internal/clients"
+)
+
+func TestTaskFilterMatch(t *testing.T) {
+	now := time.Date(2025, 6, 2, 10, 0, 0, 0, time.Local)
+	millis := func(days int) string {
+		return strconv.FormatInt(now.AddDate(0, 0, days).UnixMilli(), 10)
+	}
+	me := clients.User{Id: 7, Username: "mattia", Initials: "MC"}
+	alice := clients.User{Id: 8, Username: "alice", Initials: "AL"}
+	login := clients.Task{
+		Name: "Fix login redirect", CustomId: "APP-12", Status: clients.Status{Status: "in progress"},
+		List: clients.List{Name: "Backend"}, Tags: []clients.Tag{{Name: "bug"}}, Assignees: []clients.User{me}, DueDate: millis(3),
+		CustomFields: []clients.CustomField{
+			{Name: "Sprint", Type: clients.FieldDropDown, TypeConfig: clients.CustomFieldConfig{Options: []clients.CustomFieldOption{{Id: "o1", Name: "Sprint 12"}}},
+				Typed: clients.CustomFieldValue{Set: true, Options: []string{"o1"}}},
+			{Name: "Story points", Type: clients.FieldNumber, Typed: clients.CustomFieldValue{Set: true, Number: 5}},
+			{Name: "Blocked", Type: clients.FieldCheckbox, Typed: clients.CustomFieldValue{Set: true, Checked: true}},
+		},
+	}
+	docs := clients.Task{
+		Name: "Write the docs", CustomId: "APP-13", Status: clients.Status{Status: "done"},
+		List: clients.List{Name: "Frontend"}, Assignees: []clients.User{alice}, DueDate: millis(-2),
+		CustomFields: []clients.CustomField{
+			{Name: "Story points", Type: clients.FieldNumber, Typed: clients.CustomFieldValue{Set: true, Number: 2}},
+			{Name: "Release", Type: clients.FieldDate, Typed: clients.CustomFieldValue{Set: true, Date: now.UnixMilli()}},
+		},
+	}
+	tests := []struct {
+		query string
+		want  []bool // login, docs
+	}{
+		{"", []bool{true, true}},
+		{"lgn", []bool{true, false}},
+		{"app-13", []bool{false, true}},
+		{"@me", []bool{true, false}},
+		{"@AL", []bool{false, true}},
+		{"-@me", []bool{false, true}},
+		{"tag:BUG", []bool{true, false}},
+		{"list:end", []bool{true, true}},
+		{"list:back -status:done", []bool{true, false}},
+		{`list:"Front" status:done`, []bool{false, true}},
+		{"due:<7d", []bool{true, true}},
+		{"due:>1d", []bool{true, false}},
+		{"due:overdue", []bool{false, true}},
+		{"due:none", []bool{false, false}},
+		{"@me tag:bug list:Backend -status:done due:<1w", []bool{true, false}},
+		{`field:sprint="sprint 12"`, []bool{true, false}},
+		{`field:"Story points">3`, []bool{true, false}},
+		{`field:"Story points"=2`, []bool{false, true}},
+		{"field:Sprint", []bool{true, false}},
+		{"-field:Blocked", []bool{false, true}},
+		{"field:Blocked=false", []bool{false, false}},
+		{"field:Release<2025-06-03 field:Release>2025-06-01", []bool{false, true}},
+	}
+	for _, tt := range tests {
+		f, err := parseTaskFilter(tt.query)
+		if err != nil {
+			t.Errorf("parseTaskFilter(%q): %v", tt.query, err)
+			continue
+		}
+		for i, task := range []clients.Task{login, docs} {
+			if got := f.match(task, "7", now); got != tt.want[i] {
+				t.Errorf("%q matching %q = %v, want %v", tt.query, task.Name, got, tt.want[i])
+			}
+		}
+	}
+
+	for _, query := range []string{"owner:me", "tag:", "due:soon", "due:<7m", "field:", "field:Points>", "field:Points>soon"} {
+		if _, err := parseTaskFilter(query); err == nil {
+			t.Errorf("parseTaskFilter(%q) accepted an invalid filter", query)
+		}
+	}
+}
+
+func TestHomeFiltersBoardAndSavesFilter(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.Tasks = append(fixtures.Tasks, clients.Task{Id: "t3", Name: "Third", Status: fixtures.Tasks[0].Status, Tags: []clients.Tag{{Name: "bug"}}})
+	fixtures.Views["v1"] = append(fixtures.Views["v1"], "t3")
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("tag:bug")})
+	home := m.(HomeModel)
+	if got := home.cellTasks(boardGroup{}, boardGroup{key: "to do"}); len(got) != 1 || got[0].Id != "t3" {
+		t.Errorf("to do column not filtered: %+v", got)
+	}
+	if got := home.cellTasks(boardGroup{}, boardGroup{key: "in progress"}); len(got) != 0 {
+		t.Errorf("in progress column not filtered: %+v", got)
+	}
+	if !home.EditingText() {
+		t.Error("filter bar should keep the global shortcuts")
+	}
+
+	// Save it, then bring it back from the saved filters after clearing it.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bugs")})
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if filters := clients.GetConfig().Filters; filters["bugs"] != "tag:bug" {
+		t.Errorf("filter not saved: %v", filters)
+	}
+	// Saving the filter keeps the cached board.
+	requests := len(srv.Requests())
+	start(NewHomeModel(srv.Client()))
+	if got := srv.Requests()[requests:]; len(got) > 0 {
+		t.Errorf("board fetched again after saving a filter: %v", got)
+	}
+	if home := m.(HomeModel); home.filterBar.active || home.filter.query != "tag:bug" {
+		t.Errorf("filter not kept after saving: %+v", home.filter)
+	}
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEscape})
+	if got := m.(HomeModel).cellTasks(boardGroup{}, boardGroup{key: "to do"}); len(got) != 2 {
+		t.Errorf("filter not cleared: %+v", got)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.(HomeModel).cellTasks(boardGroup{}, boardGroup{key: "to do"}); len(got) != 1 || got[0].Id != "t3" {
+		t.Errorf("saved filter not applied: %+v", got)
+	}
+}
@@ -0,0 +1,384 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+)
+
+const (
+	groupStatus   = "status"
+	groupAssignee = "assignee"
+	groupList     = "list"
+	groupPriority = "priority"
+	groupTag      = "tag"
+	groupDue      = "due"
+)
+
+// groupings are the fields the board can be grouped by, in the order g and G cycle through them.
+var groupings = []string{groupStatus, groupAssignee, groupList, groupPriority, groupTag, groupDue}
+
+// boardGroup is a column or a swimlane of the board.
+type boardGroup struct {
+	key   string
+	label string
+	color string
+	order int // groups are sorted by order, then by label when the grouping is alphabetical
+}
+
+// noGroup sorts the group of the tasks without a value last.
+const noGroup = math.MaxInt
+
+var dueBuckets = []boardGroup{
+	{key: "overdue", label: "overdue", color: "#FF5F87", order: 0},
+	{key: "today", label: "today", color: "#FFA500", order: 1},
+	{key: "week", label: "next 7 days", color: "#4194f6", order: 2},
+	{key: "later", label: "later", color: "#73F59F", order: 3},
+	{key: "none", label: "no due date", color: "#888888", order: noGroup},
+}
+
+// defaultLayout returns the layout saved for a view, status columns without lanes by default.
+func defaultLayout(viewId string) clients.BoardLayout {
+	layout := clients.GetConfig().Layouts[viewId]
+	if !slices.Contains(groupings, layout.Columns) {
+		layout.Columns = groupStatus
+	}
+	if !slices.Contains(groupings, layout.Lanes) || layout.Lanes == layout.Columns {
+		layout.Lanes = ""
+	}
+	return layout
+}
+
+// dueBucket returns the group of a due date, by day.
+func dueBucket(dueDate string, now time.Time) boardGroup {
+	if dueDate == "" {
+		return dueBuckets[4]
+	}
+	due := shared.ToDate(dueDate)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case due.Before(today):
+		return dueBuckets[0]
+	case due.Before(today.AddDate(0, 0, 1)):
+		return dueBuckets[1]
+	case due.Before(today.AddDate(0, 0, 8)):
+		return dueBuckets[2]
+	}
+	return dueBuckets[3]
+}
+
+// taskGroups returns the groups of a task. A task with several assignees or tags is in each of their groups.
+func taskGroups(by string, task clients.Task, now time.Time) []boardGroup {
+	switch by {
+	case groupAssignee:
+		if len(task.Assignees) == 0 {
+			return []boardGroup{{label: "unassigned", color: "#888888", order: noGroup}}
+		}
+		groups := make([]boardGroup, len(task.Assignees))
+		for i, user := range task.Assignees {
+			groups[i] = boardGroup{key: user.IdString(), label: user.Username, color: user.Color}
+		}
+		return groups
+	case groupList:
+		return []boardGroup{{key: task.List.Id, label: task.List.Name}}
+	case groupPriority:
+		if task.Priority == nil {
+			return []boardGroup{{label: "no priority", color: "#888888", order: noGroup}}
+		}
+		return []boardGroup{{key: task.Priority.Priority, label: task.Priority.Priority, color: task.Priority.Color, order: slices.Index(priorityNames, task.Priority.Priority)}}
+	case groupTag:
+		if len(task.Tags) == 0 {
+			return []boardGroup{{label: "no tags", color: "#888888", order: noGroup}}
+		}
+		groups := make([]boardGroup, len(task.Tags))
+		for i, tag := range task.Tags {
+			groups[i] = boardGroup{key: tag.Name, label: tag.Name, color: tag.TagBg}
+		}
+		return groups
+	case groupDue:
+		return []boardGroup{dueBucket(task.DueDate, now)}
+	}
+	return []boardGroup{{key: task.Status.Status, label: task.Status.Status, color: task.Status.Color}}
+}
+
+func inGroup(groups []boardGroup, key string) bool {
+	return slices.ContainsFunc(groups, func(g boardGroup) bool { return g.key == key })
+}
+
+// boardTasks returns all the tasks of the board, column after column.
+func (m HomeModel) boardTasks() []clients.Task {
+	var tasks []clients.Task
+	for _, state := range m.states {
+		tasks = append(tasks, m.columns[state].tasks...)
+	}
+	return tasks
+}
+
+// boardGroups returns the groups found on the board for a grouping. Status columns are
+// the ones of the view, even when empty; the other groups are the ones of the tasks.
+func (m HomeModel) boardGroups(by string) []boardGroup {
+	if by == groupStatus {
+		groups := make([]boardGroup, len(m.states))
+		for i, state := range m.states {
+			groups[i] = boardGroup{key: state, label: state, color: m.statuses[state].Color, order: i}
+		}
+		return groups
+	}
+	var groups []boardGroup
+	now := time.Now()
+	for _, task := range m.boardTasks() {
+		for _, g := range taskGroups(by, task, now) {
+			if !inGroup(groups, g.key) {
+				groups = append(groups, g)
+			}
+		}
+	}
+	alphabetical := by == groupAssignee || by == groupTag
+	slices.SortStableFunc(groups, func(a, b boardGroup) int {
+		if a.order != b.order {
+			if a.order < b.order {
+				return -1
+			}
+			return 1
+		}
+		if alphabetical {
+			return strings.Compare(strings.ToLower(a.label), strings.ToLower(b.label))
+		}
+		return 0
+	})
+	return groups
+}
+
+// boardColumns returns the columns of the board.
+func (m HomeModel) boardColumns() []boardGroup {
+	return m.boardGroups(m.layout.Columns)
+}
+
+// boardLanes returns the swimlanes of the board, a single lane without a key when there are none.
+func (m HomeModel) boardLanes() []boardGroup {
+	if m.layout.Lanes == "" {
+		return []boardGroup{{}}
+	}
+	return m.boardGroups(m.layout.Lanes)
+}
+
+// groupTasks returns the tasks in a column of a lane that match a filter, in board order.
+func (m HomeModel) groupTasks(lane boardGroup, column boardGroup, filter taskFilter) []clients.Task {
+	source := m.boardTasks()
+	if m.layout.Columns == groupStatus {
+		source = m.columns[column.key].tasks
+	}
+	userId, now := clients.GetConfig().UserId, time.Now()
+	var tasks []clients.Task
+	for _, task := range source {
+		if filter.active() && !filter.match(task, userId, now) {
+			continue
+		}
+		if m.layout.Columns != groupStatus && !inGroup(taskGroups(m.layout.Columns, task, now), column.key) {
+			continue
+		}
+		if m.layout.Lanes != "" && !inGroup(taskGroups(m.layout.Lanes, task, now), lane.key) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// cellTasks returns the tasks in a column of a lane that match the board filter.
+// Selection and scrolling refer to these tasks.
+func (m HomeModel) cellTasks(lane boardGroup, column boardGroup) []clients.Task {
+	return m.groupTasks(lane, column, m.filter)
+}
+
+func cellKey(lane boardGroup, column boardGroup) string {
+	return lane.key + "\x00" + column.key
+}
+
+// laneWindow returns how many lanes fit on the screen and how many cards each of their cells shows.
+func (m HomeModel) laneWindow() (lanes int, rows int) {
+	if m.layout.Lanes == "" {
+		return 1, m.wndY
+	}
+	rows = max(1, m.wndY/2)
+	return max(1, m.wndY/rows), rows
+}
+
+// selectedCell returns the lane and the column of the selection, false when the board is empty.
+func (m HomeModel) selectedCell() (boardGroup, boardGroup, bool) {
+	lanes, columns := m.boardLanes(), m.boardColumns()
+	if m.selectedLane >= len(lanes) || m.selectedColumn >= len(columns) {
+		return boardGroup{}, boardGroup{}, false
+	}
+	return lanes[m.selectedLane], columns[m.selectedColumn], true
+}
+
+// selectedCard returns the selected task, false when the selected cell is empty.
+func (m HomeModel) selectedCard() (clients.Task, bool) {
+	lane, column, ok := m.selectedCell()
+	if !ok {
+		return clients.Task{}, false
+	}
+	tasks := m.cellTasks(lane, column)
+	if m.selectedTask >= len(tasks) {
+		return clients.Task{}, false
+	}
+	return tasks[m.selectedTask], true
+}
+
+func (m *HomeModel) setOffset(key string, offset int) {
+	if m.offsets == nil {
+		m.offsets = make(map[string]int)
+	}
+	m.offsets[key] = offset
+}
+
+// selectTask moves the selection on a task, scrolling the board to it.
+func (m *HomeModel) selectTask(taskId string) bool {
+	for l, lane := range m.boardLanes() {
+		for c, column := range m.boardColumns() {
+			idx := slices.IndexFunc(m.cellTasks(lane, column), func(t clients.Task) bool { return t.Id == taskId })
+			if idx >= 0 {
+				m.selectedLane, m.selectedColumn, m.selectedTask = l, c, idx
+				m.scrollToSelection()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scrollToSelection scrolls the columns, the lanes and the selected cell to show the selected card.
+func (m *HomeModel) scrollToSelection() {
+	if m.selectedColumn < m.offsetX {
+		m.offsetX = m.selectedColumn
+	}
+	if m.selectedColumn >= m.offsetX+m.wndX {
+		m.offsetX = m.selectedColumn - m.wndX + 1
+	}
+	visibleLanes, rows := m.laneWindow()
+	if m.selectedLane < m.offsetLane {
+		m.offsetLane = m.selectedLane
+	}
+	if m.selectedLane >= m.offsetLane+visibleLanes {
+		m.offsetLane = m.selectedLane - visibleLanes + 1
+	}
+	// Show as many lanes as fit, when the window grew.
+	m.offsetLane = min(m.offsetLane, max(len(m.boardLanes())-visibleLanes, 0))
+	lane, column, ok := m.selectedCell()
+	if !ok {
+		return
+	}
+	key := cellKey(lane, column)
+	if offset := m.offsets[key]; m.selectedTask < offset {
+		m.setOffset(key, m.selectedTask)
+	} else if m.selectedTask >= offset+rows {
+		m.setOffset(key, m.selectedTask-rows+1)
+	}
+}
+
+// clampSelection keeps the selection on the board after its columns or lanes changed.
+func (m *HomeModel) clampSelection() {
+	m.selectedLane = min(m.selectedLane, max(len(m.boardLanes())-1, 0))
+	m.selectedColumn = min(m.selectedColumn, max(len(m.boardColumns())-1, 0))
+	m.offsetX = min(m.offsetX, m.selectedColumn)
+	m.offsetLane = min(m.offsetLane, m.selectedLane)
+}
+
+// setLayout regroups the board and saves the layout for the view.
+func (m HomeModel) setLayout(layout clients.BoardLayout) (tea.Model, tea.Cmd) {
+	selected, hasSelection := m.selectedCard()
+	m.layout = layout
+	m.offsets = make(map[string]int)
+	m.selectedLane, m.selectedColumn, m.selectedTask, m.offsetX, m.offsetLane = 0, 0, 0, 0, 0
+	if hasSelection {
+		m.selectTask(selected.Id)
+	}
+	return m, saveLayout(layout)
+}
+
+// saveLayout saves the layout of the board for the current view.
+func saveLayout(layout clients.BoardLayout) tea.Cmd {
+	config := clients.GetConfig()
+	layouts := make(map[string]clients.BoardLayout, len(config.Layouts)+1)
+	for k, v := range config.Layouts {
+		layouts[k] = v
+	}
+	layouts[config.ViewId] = layout
+	config.Layouts = layouts
+	if err := clients.SavePreferences(config); err != nil {
+		return components.Notify("Saving board layout", err, nil)
+	}
+	return nil
+}
+
+// cycleColumns groups the columns by the next field. The lanes cannot use the same field.
+func (m HomeModel) cycleColumns() (tea.Model, tea.Cmd) {
+	layout := m.layout
+	layout.Columns = groupings[(slices.Index(groupings, layout.Columns)+1)%len(groupings)]
+	if layout.Lanes == layout.Columns {
+		layout.Lanes = ""
+	}
+	return m.setLayout(layout)
+}
+
+// cycleLanes splits the board in swimlanes by the next field, after the last one it removes them.
+func (m HomeModel) cycleLanes() (tea.Model, tea.Cmd) {
+	options := []string{""}
+	for _, g := range groupings {
+		if g != m.layout.Columns {
+			options = append(options, g)
+		}
+	}
+	layout := m.layout
+	layout.Lanes = options[(slices.Index(options, layout.Lanes)+1)%len(options)]
+	return m.setLayout(layout)
+}
+
+var errMoveNotByStatus = errors.New("cards can only be moved between status columns, press g to group the board by status")
+
+// layoutTitle describes the grouping of the board when it is not the default one.
+func (m HomeModel) layoutTitle() string {
+	if m.layout.Columns == groupStatus && m.layout.Lanes == "" {
+		return ""
+	}
+	title := "by " + m.layout.Columns
+	if m.layout.Lanes != "" {
+		title += fmt.Sprintf(" × %s lanes", m.layout.Lanes)
+	}
+	return title
+}
+
+// renderLane renders the visible columns of a lane, under the lane header when the board has lanes.
+func (m HomeModel) renderLane(laneIdx int, lane boardGroup, columns []boardGroup) string {
+	var rendered []string
+	for c := m.offsetX; c < min(m.offsetX+m.wndX, len(columns)); c++ {
+		isSelected := m.selectedLane == laneIdx && m.selectedColumn == c
+		rendered = append(rendered, m.renderColumn(lane, columns[c], isSelected))
+	}
+	row := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	if m.layout.Lanes == "" {
+		return row
+	}
+	seen := map[string]bool{}
+	for _, column := range columns {
+		for _, task := range m.cellTasks(lane, column) {
+			seen[task.Id] = true
+		}
+	}
+	color := lane.color
+	if color == "" {
+		color = "#874BFD"
+	}
+	header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(color)).Render(fmt.Sprintf("▌ %s (%d)", strings.ToUpper(lane.label), len(seen)))
+	return lipgloss.JoinVertical(lipgloss.Left, header, row)
+}
@@ -0,0 +1,483 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type relatedTasksLoadedMsg struct {
+	taskId string
+	tasks  map[string]clients.Task
+}
+
+type modalTaskLoadedMsg struct {
+	task clients.Task
+	err  error
+}
+
+type checklistItemResolvedMsg struct {
+	taskId      string
+	checklistId string
+	itemId      string
+	resolved    bool
+	err         error
+}
+
+const (
+	entrySubtask = iota
+	entryChecklistItem
+	entryWaitingOn
+	entryBlocking
+	entryLinked
+)
+
+// taskEntry is a row of the subtasks pane: a subtask, a checklist item or a related task.
+type taskEntry struct {
+	kind        int
+	section     string
+	depth       int
+	taskId      string // subtasks and related tasks
+	checklistId string
+	item        clients.ChecklistItem
+}
+
+// subtasksPane replaces the description of the task modal with the subtasks, the
+// checklists and the dependencies of the task.
+type subtasksPane struct {
+	active  bool
+	cursor  int
+	related map[string]clients.Task // tasks the modal task waits on, blocks or is linked to, by id
+}
+
+// relatedTaskIds returns the ids of the tasks on the other side of the dependencies and links of a task.
+func relatedTaskIds(task clients.Task) []string {
+	var ids []string
+	add := func(id string) {
+		if id != "" && id != task.Id && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, d := range task.Dependencies {
+		add(d.TaskId)
+		add(d.DependsOn)
+	}
+	for _, l := range task.LinkedTasks {
+		add(l.TaskId)
+		add(l.LinkId)
+	}
+	return ids
+}
+
+// loadRelatedTasks fetches the tasks a task depends on, blocks or is linked to, for their status.
+// The ones that cannot be read are left out.
+func loadRelatedTasks(ctx context.Context, client clients.ClickupAPI, task clients.Task) tea.Cmd {
+	ids := relatedTaskIds(task)
+	if len(ids) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		tasks := make(map[string]clients.Task, len(ids))
+		for _, id := range ids {
+			if related, err := client.GetTask(ctx, id); err == nil {
+				tasks[id] = related
+			}
+		}
+		return relatedTasksLoadedMsg{taskId: task.Id, tasks: tasks}
+	}
+}
+
+func reloadModalTask(client clients.ClickupAPI, taskId string) tea.Cmd {
+	return func() tea.Msg {
+		task, err := client.RefreshTask(context.Background(), taskId)
+		return modalTaskLoadedMsg{task: task, err: err}
+	}
+}
+
+// resolveChecklistItem saves the state of a checklist item. Like every write, it is not
+// bound to the view context.
+func resolveChecklistItem(client clients.ClickupAPI, taskId string, checklistId string, itemId string, resolved bool) tea.Cmd {
+	return func() tea.Msg {
+		err := client.ResolveChecklistItem(context.Background(), taskId, checklistId, itemId, resolved)
+		return checklistItemResolvedMsg{taskId: taskId, checklistId: checklistId, itemId: itemId, resolved: resolved, err: err}
+	}
+}
+
+// taskEntries lists the rows of the subtasks pane: the subtasks as a tree, the items of
+// each checklist, then the tasks the task waits on, blocks and is linked to.
+func taskEntries(task clients.Task) []taskEntry {
+	var entries []taskEntry
+
+	children := map[string][]clients.Task{}
+	ids := map[string]bool{}
+	for _, sub := range task.SubTasks {
+		ids[sub.Id] = true
+	}
+	for _, sub := range task.SubTasks {
+		parent := sub.Parent
+		if !ids[parent] {
+			parent = task.Id
+		}
+		children[parent] = append(children[parent], sub)
+	}
+	section := fmt.Sprintf("Subtasks (%d)", len(task.SubTasks))
+	var addSubtasks func(parent string, depth int)
+	addSubtasks = func(parent string, depth int) {
+		for _, sub := range children[parent] {
+			entries = append(entries, taskEntry{kind: entrySubtask, section: section, depth: depth, taskId: sub.Id})
+			addSubtasks(sub.Id, depth+1)
+		}
+	}
+	addSubtasks(task.Id, 0)
+
+	for _, checklist := range task.Checklists {
+		resolved, total := checklistProgress(checklist.Items)
+		section := fmt.Sprintf("%s (%d/%d)", checklist.Name, resolved, total)
+		var addItems func(items []clients.ChecklistItem, depth int)
+		addItems = func(items []clients.ChecklistItem, depth int) {
+			for _, item := range items {
+				entries = append(entries, taskEntry{kind: entryChecklistItem, section: section, depth: depth, checklistId: checklist.Id, item: item})
+				addItems(item.Children, depth+1)
+			}
+		}
+		addItems(checklist.Items, 0)
+	}
+
+	for _, d := range task.Dependencies {
+		if d.TaskId == task.Id {
+			entries = append(entries, taskEntry{kind: entryWaitingOn, section: "Waiting on", taskId: d.DependsOn})
+		}
+	}
+	for _, d := range task.Dependencies {
+		if d.DependsOn == task.Id {
+			entries = append(entries, taskEntry{kind: entryBlocking, section: "Blocking", taskId: d.TaskId})
+		}
+	}
+	for _, l := range task.LinkedTasks {
+		id := l.LinkId
+		if id == task.Id {
+			id = l.TaskId
+		}
+		entries = append(entries, taskEntry{kind: entryLinked, section: "Linked", taskId: id})
+	}
+	return entries
+}
+
+func checklistProgress(items []clients.ChecklistItem) (resolved int, total int) {
+	for _, item := range items {
+		if item.Resolved {
+			resolved++
+		}
+		r, t := checklistProgress(item.Children)
+		resolved, total = resolved+r, total+t+1
+	}
+	return resolved, total
+}
+
+// setChecklistItem ticks or unticks an item of a checklist of the modal task.
+func (m *HomeModel) setChecklistItem(taskId string, checklistId string, itemId string, resolved bool) {
+	if m.modalTask == nil || m.modalTask.Id != taskId {
+		return
+	}
+	var set func(items []clients.ChecklistItem) bool
+	set = func(items []clients.ChecklistItem) bool {
+		for i := range items {
+			if items[i].Id == itemId {
+				items[i].Resolved = resolved
+				return true
+			}
+			if set(items[i].Children) {
+				return true
+			}
+		}
+		return false
+	}
+	// The checklists are copied, the previous task may still be in the back-stack or the cache.
+	checklists := slices.Clone(m.modalTask.Checklists)
+	for i := range checklists {
+		if checklists[i].Id == checklistId {
+			checklists[i].Items = cloneChecklistItems(checklists[i].Items)
+			set(checklists[i].Items)
+		}
+	}
+	task := *m.modalTask
+	task.Checklists = checklists
+	m.modalTask = &task
+}
+
+func cloneChecklistItems(items []clients.ChecklistItem) []clients.ChecklistItem {
+	items = slices.Clone(items)
+	for i := range items {
+		items[i].Children = cloneChecklistItems(items[i].Children)
+	}
+	return items
+}
+
+// relatedTask returns a task shown in the subtasks pane: a subtask or a related task.
+func (m HomeModel) relatedTask(id string) (clients.Task, bool) {
+	if i := slices.IndexFunc(m.modalTask.SubTasks, func(t clients.Task) bool { return t.Id == id }); i >= 0 {
+		return m.modalTask.SubTasks[i], true
+	}
+	task, ok := m.subtasks.related[id]
+	return task, ok
+}
+
+// openModal shows a task in the modal, with its comments and the status of the tasks it depends on.
+func (m *HomeModel) openModal(taskId string) (tea.Cmd, error) {
+	t, err := m.client.GetTask(m.ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := m.client.GetTaskComments(m.ctx, taskId)
+	if err != nil {
+		comments = []clients.Comment{}
+	}
+	t.Comments = comments
+	m.showTask(&t)
+	return tea.Batch(loadComments(m.client, t.Id), loadRelatedTasks(m.ctx, m.client, t)), nil
+}
+
+// showTask resets the modal on a task.
+func (m *HomeModel) showTask(task *clients.Task) {
+	m.modalTask = task
+	m.showModal = true
+	m.editor = taskEditor{}
+	m.external = externalEdit{}
+	m.comments = commentsPane{}
+	m.subtasks = subtasksPane{}
+	m.fields = fieldsPane{}
+	m.attachments = attachmentsPane{}
+
+	m.contentViewport = viewport.New(m.width-9, m.height-20)
+	m.commentsViewport = viewport.New(m.width-11, 6)
+	m.setModalDescription()
+	m.setModalComments()
+}
+
+// openNestedTask opens a subtask or a related task in the modal. The current task goes
+// on the back-stack, esc comes back to it.
+func (m HomeModel) openNestedTask(taskId string) (tea.Model, tea.Cmd) {
+	parent := modalEntry{task: m.modalTask, subtasks: m.subtasks}
+	cmd, err := m.openModal(taskId)
+	if err != nil {
+		return m, components.Notify("Opening task", err, nil)
+	}
+	m.modalStack = append(m.modalStack, parent)
+	return m, cmd
+}
+
+// closeModal goes back to the previous task of the back-stack, or closes the modal.
+// The previous task is fetched again, as its subtasks may have been changed meanwhile.
+func (m HomeModel) closeModal() (tea.Model, tea.Cmd) {
+	if len(m.modalStack) == 0 {
+		m.showModal = false
+		m.modalTask = nil
+		return m, nil
+	}
+	previous := m.modalStack[len(m.modalStack)-1]
+	m.modalStack = m.modalStack[:len(m.modalStack)-1]
+	m.showTask(previous.task)
+	m.subtasks = previous.subtasks
+	return m, tea.Batch(loadComments(m.client, previous.task.Id), reloadModalTask(m.client, previous.task.Id))
+}
+
+// modalEntry is a task of the back-stack of the modal, with the row that was selected in it.
+type modalEntry struct {
+	task     *clients.Task
+	subtasks subtasksPane
+}
+
+func (m HomeModel) handleRelatedTasksLoadedEvent(msg relatedTasksLoadedMsg) (tea.Model, tea.Cmd) {
+	if m.modalTask == nil || m.modalTask.Id != msg.taskId {
+		return m, nil
+	}
+	m.subtasks.related = msg.tasks
+	return m, nil
+}
+
+func (m HomeModel) handleModalTaskLoadedEvent(msg modalTaskLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil || m.modalTask == nil || m.modalTask.Id != msg.task.Id {
+		return m, nil
+	}
+	msg.task.Comments = m.modalTask.Comments
+	m.modalTask = &msg.task
+	m.subtasks.cursor = min(m.subtasks.cursor, max(len(taskEntries(msg.task))-1, 0))
+	return m, loadRelatedTasks(m.ctx, m.client, msg.task)
+}
+
+// handleChecklistItemResolvedEvent rolls back a checklist item that could not be saved.
+func (m HomeModel) handleChecklistItemResolvedEvent(msg checklistItemResolvedMsg) (tea.Model, tea.Cmd) {
+	if msg.err == nil {
+		// A successful retry after a rollback ticks the item again.
+		m.setChecklistItem(msg.taskId, msg.checklistId, msg.itemId, msg.resolved)
+		return m, nil
+	}
+	m.setChecklistItem(msg.taskId, msg.checklistId, msg.itemId, !msg.resolved)
+	retry := resolveChecklistItem(m.client, msg.taskId, msg.checklistId, msg.itemId, msg.resolved)
+	return m, components.Notify("Updating checklist item", msg.err, retry)
+}
+
+func (m HomeModel) handleKeySubtasksEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.subtasks
+	entries := taskEntries(*m.modalTask)
+	switch msg.String() {
+	case "esc", "s":
+		p.active = false
+	case "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down":
+		if p.cursor < len(entries)-1 {
+			p.cursor++
+		}
+	case "enter":
+		if p.cursor < len(entries) && entries[p.cursor].kind != entryChecklistItem {
+			return m.openNestedTask(entries[p.cursor].taskId)
+		}
+	case " ", "x":
+		if p.cursor >= len(entries) || entries[p.cursor].kind != entryChecklistItem {
+			return m, nil
+		}
+		entry := entries[p.cursor]
+		resolved := !entry.item.Resolved
+		m.setChecklistItem(m.modalTask.Id, entry.checklistId, entry.item.Id, resolved)
+		return m, resolveChecklistItem(m.client, m.modalTask.Id, entry.checklistId, entry.item.Id, resolved)
+	}
+	return m, nil
+}
+
+// renderStatus renders the status of a task in its color.
+func renderStatus(status clients.Status) string {
+	color := status.Color
+	if color == "" {
+		color = "#888888"
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("● " + strings.ToUpper(status.Status))
+}
+
+// isDone tells whether a status closes the task, so that it no longer blocks the tasks waiting on it.
+func isDone(status clients.Status) bool {
+	return status.Type == "done" || status.Type == "closed"
+}
+
+// renderSubtasks renders the rows of the subtasks pane in a box of the given size,
+// scrolled to keep the selected row in view.
+func (m HomeModel) renderSubtasks(width int, height int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	sectionStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#888888"))
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.Highlight).Bold(true)
+	entries := taskEntries(*m.modalTask)
+	if len(entries) == 0 {
+		return lipgloss.NewStyle().Width(width).Height(height).Render(dimStyle.Render("No subtasks, checklists or dependencies"))
+	}
+
+	var lines []string
+	selectedLine := 0
+	section := ""
+	for i, entry := range entries {
+		if entry.section != section {
+			if section != "" {
+				lines = append(lines, "")
+			}
+			section = entry.section
+			lines = append(lines, sectionStyle.Render(section))
+		}
+		prefix := "  "
+		if i == m.subtasks.cursor {
+			prefix = cursorStyle.Render("> ")
+			selectedLine = len(lines)
+		}
+		indent := strings.Repeat("  ", entry.depth)
+		var row string
+		switch entry.kind {
+		case entryChecklistItem:
+			box := "[ ] "
+			name := entry.item.Name
+			if entry.item.Resolved {
+				box = lipgloss.NewStyle().Foreground(ui.Special).Render("[x] ")
+				name = dimStyle.Strikethrough(true).Render(name)
+			}
+			row = indent + box + name
+			if entry.item.Assignee != nil {
+				row += dimStyle.Render("  → " + entry.item.Assignee.Username)
+			}
+		default:
+			task, ok := m.relatedTask(entry.taskId)
+			if !ok {
+				row = indent + dimStyle.Render(entry.taskId+"  status unknown")
+				break
+			}
+			marker := ""
+			if entry.depth > 0 {
+				marker = "└ "
+			}
+			if entry.kind == entryWaitingOn {
+				marker = lipgloss.NewStyle().Foreground(ui.Error).Render("⛔ ")
+				if isDone(task.Status) {
+					marker = lipgloss.NewStyle().Foreground(ui.Special).Render("✓ ")
+				}
+			}
+			row = indent + marker + renderStatus(task.Status) + "  " + task.Name
+			if task.CustomId != "" {
+				row += dimStyle.Render("  " + task.CustomId)
+			}
+		}
+		lines = append(lines, lipgloss.NewStyle().MaxWidth(width).Render(prefix+row))
+	}
+
+	start := 0
+	if len(lines) > height {
+		start = min(max(selectedLine-height/2, 0), len(lines)-height)
+	}
+	lines = lines[start:min(start+height, len(lines))]
+	return lipgloss.NewStyle().Width(width).Height(height).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// renderTaskStructure summarizes the subtasks, checklists and dependencies of a task in a line.
+func renderTaskStructure(task clients.Task) string {
+	var parts []string
+	if n := len(task.SubTasks); n > 0 {
+		parts = append(parts, fmt.Sprintf("⑂ %d subtasks", n))
+	}
+	resolved, total := 0, 0
+	for _, checklist := range task.Checklists {
+		r, t := checklistProgress(checklist.Items)
+		resolved, total = resolved+r, total+t
+	}
+	if total > 0 {
+		parts = append(parts, fmt.Sprintf("☑ %d/%d", resolved, total))
+	}
+	waiting, blocking := 0, 0
+	for _, d := range task.Dependencies {
+		if d.TaskId == task.Id {
+			waiting++
+		} else if d.DependsOn == task.Id {
+			blocking++
+		}
+	}
+	if waiting > 0 {
+		parts = append(parts, fmt.Sprintf("⛔ waiting on %d", waiting))
+	}
+	if blocking > 0 {
+		parts = append(parts, fmt.Sprintf("blocking %d", blocking))
+	}
+	if n := len(task.LinkedTasks); n > 0 {
+		parts = append(parts, fmt.Sprintf("🔗 %d linked", n))
+	}
+	if n := len(task.Attachments); n > 0 {
+		parts = append(parts, fmt.Sprintf("📎 %d attachments", n))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("   " + strings.Join(parts, "   "))
+}
@@ -0,0 +1,708 @@
+package views
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/fakeclickup"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+)
+
+func boardFixtures() fakeclickup.Fixtures {
+	todo := clients.Status{Status: "to do", Color: "#d3d3d3"}
+	progress := clients.Status{Status: "in progress", Color: "#4194f6"}
+	return fakeclickup.Fixtures{
+		Tasks: []clients.Task{
+			{Id: "t1", Name: "First", Status: todo, List: clients.List{Id: "l1", Name: "Sprint"}},
+			{Id: "t2", Name: "Second", Status: progress, List: clients.List{Id: "l1", Name: "Sprint"}},
+		},
+		Views: map[string][]string{"v1": {"t1", "t2"}},
+	}
+}
+
+func TestHomeLoadsBoard(t *testing.T) {
+	srv := newTestServer(t, boardFixtures(), clients.Config{ViewId: "v1"})
+
+	m, notifications := start(NewHomeModel(srv.Client()))
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	home := m.(HomeModel)
+	if home.loading {
+		t.Fatal("board still loading")
+	}
+	if len(home.states) != 2 || home.states[0] != "to do" || home.states[1] != "in progress" {
+		t.Errorf("unexpected columns %v", home.states)
+	}
+}
+
+func TestHomeMoveTaskUpdatesStatus(t *testing.T) {
+	srv := newTestServer(t, boardFixtures(), clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyShiftRight})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	home := m.(HomeModel)
+	if got := home.columns["in progress"].tasks; len(got) != 2 || got[0].Id != "t1" {
+		t.Errorf("task not moved on the board: %+v", got)
+	}
+	if home.selectedColumn != 1 || home.selectedTask != 0 {
+		t.Errorf("selection did not follow the task: column %d task %d", home.selectedColumn, home.selectedTask)
+	}
+	if task, _ := srv.Task("t1"); task.Status.Status != "in progress" {
+		t.Errorf("server status = %q, want in progress", task.Status.Status)
+	}
+}
+
+func TestHomeMoveTaskRollsBackOnError(t *testing.T) {
+	srv := newTestServer(t, boardFixtures(), clients.Config{ViewId: "v1"})
+	srv.FailNext("PUT /api/v2/task/t1", http.StatusBadRequest, 1)
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyShiftRight})
+	if len(notifications) != 1 || notifications[0].Retry == nil {
+		t.Fatalf("expected a retryable notification, got %v", notifications)
+	}
+	home := m.(HomeModel)
+	if got := home.columns["to do"].tasks; len(got) != 1 || got[0].Id != "t1" {
+		t.Errorf("task not rolled back: %+v", got)
+	}
+	if home.selectedColumn != 0 {
+		t.Errorf("selection not restored, column %d", home.selectedColumn)
+	}
+
+	m, _ = drive(m, notifications[0].Retry())
+	if got := m.(HomeModel).columns["in progress"].tasks; len(got) != 2 || got[0].Id != "t1" {
+		t.Errorf("retry did not move the task: %+v", got)
+	}
+}
+
+func TestHomePicksViewFromHierarchy(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.Teams = []clients.Team{{Id: fakeclickup.DefaultTeam, Name: "Own"}, {Id: "team-2", Name: "Client"}}
+	fixtures.Spaces = map[string][]clients.Space{fakeclickup.DefaultTeam: {{Id: "s1", Name: "Engineering"}}}
+	fixtures.Folders = map[string][]clients.Folder{"s1": {{Id: "f1", Name: "Product"}}}
+	fixtures.Lists = map[string][]clients.List{"f1": {{Id: "l1", Name: "Sprint"}}}
+	fixtures.ViewDefs = map[string][]clients.View{"l1": {{Id: "v1", Name: "Sprint board", Type: "board"}}}
+	srv := newTestServer(t, fixtures, clients.Config{})
+
+	m, notifications := start(NewHomeModel(srv.Client()))
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if !m.(HomeModel).picking {
+		t.Fatal("picker not shown without a view")
+	}
+
+	// Load everything below the first team, then filter down to the board.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyCtrlA})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("sprbrd")})
+	m, notifications = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+
+	if config := clients.GetConfig(); config.ViewId != "v1" || config.TeamId != fakeclickup.DefaultTeam {
+		t.Errorf("unexpected config %+v", config)
+	}
+	home := m.(HomeModel)
+	if home.picking || home.loading || len(home.states) != 2 {
+		t.Errorf("board not loaded after picking: picking %v loading %v states %v", home.picking, home.loading, home.states)
+	}
+}
+
+func TestHomeCreatesTaskInCurrentColumn(t *testing.T) {
+	srv := newTestServer(t, boardFixtures(), clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRight})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if !m.(HomeModel).form.open {
+		t.Fatal("form not opened")
+	}
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) != 1 || !m.(HomeModel).form.open {
+		t.Fatalf("a task without a name should be refused, got %v", notifications)
+	}
+
+	keys := []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("Third")},
+		{Type: tea.KeyTab}, {Type: tea.KeyTab}, {Type: tea.KeyTab}, {Type: tea.KeyTab},
+		{Type: tea.KeyRunes, Runes: []rune("ui, bug")},
+		{Type: tea.KeyTab},
+		{Type: tea.KeyRight}, {Type: tea.KeyRight},
+		{Type: tea.KeyTab},
+		{Type: tea.KeyRunes, Runes: []rune("2026-11-02")},
+	}
+	for _, key := range keys {
+		m, _ = drive(m, key)
+	}
+	m, notifications = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+
+	home := m.(HomeModel)
+	if home.form.open {
+		t.Error("form still open after creating the task")
+	}
+	got := home.columns["in progress"].tasks
+	if len(got) != 2 || got[0].Name != "Third" || home.selectedColumn != 1 || home.selectedTask != 0 {
+		t.Fatalf("new task not selected on top of its column: %+v", got)
+	}
+	task, ok := srv.Task(got[0].Id)
+	if !ok {
+		t.Fatal("task not created on the server")
+	}
+	if task.List.Id != "l1" || task.Status.Status != "in progress" || len(task.Tags) != 2 || task.Priority == nil || task.Priority.Priority != "high" || task.DueDate == "" {
+		t.Errorf("unexpected task %+v", task)
+	}
+}
+
+func TestHomeModalEditsFields(t *testing.T) {
+	srv := newTestServer(t, boardFixtures(), clients.Config{ViewId: "v1"})
+	srv.FailNext("PUT /api/v2/task/t1", http.StatusBadRequest, 1)
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if !m.(HomeModel).editor.active {
+		t.Fatal("editor not opened")
+	}
+
+	// Rename: the server fails, the field shows the error and the retry saves it.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Renamed")})
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) != 1 || m.(HomeModel).editor.fields[editName].err == nil {
+		t.Fatalf("expected a failed name field, got %v", notifications)
+	}
+	m, _ = drive(m, notifications[0].Retry())
+	home := m.(HomeModel)
+	if home.editor.fields[editName] != (fieldState{}) || home.modalTask.Name != "Renamed" {
+		t.Errorf("name not saved: %+v %q", home.editor.fields[editName], home.modalTask.Name)
+	}
+
+	// An invalid date is refused without calling ClickUp.
+	for i := 0; i < editDueDate; i++ {
+		m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("tomorrow")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.(HomeModel).editor.fields[editDueDate].err == nil || !m.(HomeModel).editor.editing {
+		t.Error("invalid due date accepted")
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	// A new status moves the card to its column.
+	for i := editDueDate; i > editStatus; i-- {
+		m, _ = drive(m, tea.KeyMsg{Type: tea.KeyUp})
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRight})
+	m, notifications = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	home = m.(HomeModel)
+	if got := home.columns["in progress"].tasks; len(got) != 2 || got[0].Name != "Renamed" {
+		t.Errorf("card not moved: %+v", got)
+	}
+	if task, _ := srv.Task("t1"); task.Name != "Renamed" || task.Status.Status != "in progress" {
+		t.Errorf("unexpected server task %+v", task)
+	}
+}
+
+func TestHomeModalSubtasksChecklistsAndDependencies(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.Tasks[1].Status.Type = "custom"
+	fixtures.Tasks[0].Checklists = []clients.Checklist{{Id: "c1", TaskId: "t1", Name: "Release", Items: []clients.ChecklistItem{{Id: "i1", Name: "Changelog"}}}}
+	fixtures.Tasks[0].Dependencies = []clients.Dependency{{TaskId: "t1", DependsOn: "t2"}}
+	fixtures.Tasks = append(fixtures.Tasks,
+		clients.Task{Id: "s1", Name: "Subtask", Parent: "t1", Status: fixtures.Tasks[0].Status},
+		clients.Task{Id: "s2", Name: "Nested subtask", Parent: "s1", Status: fixtures.Tasks[0].Status},
+	)
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+	m, _ = drive(m, tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	home := m.(HomeModel)
+	entries := taskEntries(*home.modalTask)
+	if len(entries) != 3 || entries[0].taskId != "s1" || entries[1].item.Id != "i1" || entries[2].kind != entryWaitingOn || entries[2].taskId != "t2" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if view := m.View(); !strings.Contains(view, "Subtasks (1)") || !strings.Contains(view, "Release (0/1)") || !strings.Contains(view, "⛔ ● IN PROGRESS  Second") {
+		t.Errorf("pane not rendered:\n%s", view)
+	}
+
+	// Space ticks the checklist item on ClickUp.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if task, _ := srv.Task("t1"); !task.Checklists[0].Items[0].Resolved {
+		t.Error("checklist item not resolved on the server")
+	}
+	if !m.(HomeModel).modalTask.Checklists[0].Items[0].Resolved {
+		t.Error("checklist item not resolved in the modal")
+	}
+
+	// Enter opens the subtask over the task, esc comes back to it.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	home = m.(HomeModel)
+	if home.modalTask.Id != "s1" || len(home.modalStack) != 1 {
+		t.Fatalf("subtask not opened: %q, stack %d", home.modalTask.Id, len(home.modalStack))
+	}
+	if entries := taskEntries(*home.modalTask); len(entries) != 1 || entries[0].taskId != "s2" {
+		t.Errorf("unexpected subtask entries %+v", entries)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+	home = m.(HomeModel)
+	if !home.showModal || home.modalTask.Id != "t1" || len(home.modalStack) != 0 || !home.subtasks.active {
+		t.Fatalf("not back to the task: %+v", home.modalTask)
+	}
+	if !home.modalTask.Checklists[0].Items[0].Resolved {
+		t.Error("the task was not fetched again")
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.(HomeModel).showModal {
+		t.Error("modal not closed")
+	}
+}
+
+func TestHomeModalEditsCustomFieldsAndPinsThemOnCards(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.Tasks[0].CustomFields = []clients.CustomField{
+		{Id: "f1", Name: "Priority", Type: clients.FieldDropDown, Value: json.RawMessage(`0`), TypeConfig: clients.CustomFieldConfig{
+			Options: []clients.CustomFieldOption{{Id: "o1", Name: "Low", Orderindex: 0}, {Id: "o2", Name: "High", Orderindex: 1}},
+		}},
+		{Id: "f2", Name: "Points", Type: clients.FieldNumber},
+	}
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+	m, _ = drive(m, tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if view := m.View(); !strings.Contains(view, "Priority") || !strings.Contains(view, "Low") {
+		t.Errorf("custom fields not rendered:\n%s", view)
+	}
+
+	// The drop-down moves to the next option.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRight})
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if task, _ := srv.Task("t1"); string(task.CustomFields[0].Value) != "1" {
+		t.Errorf("drop-down value on the server = %s, want 1", task.CustomFields[0].Value)
+	}
+	if got := customFieldDisplay(m.(HomeModel).modalTask.CustomFields[0]); got != "High" {
+		t.Errorf("drop-down in the modal = %q, want High", got)
+	}
+
+	// An invalid number is not sent.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("many")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	home := m.(HomeModel)
+	if !home.fields.editing || home.fields.fields["f2"].err == nil {
+		t.Fatal("invalid number accepted")
+	}
+	home.fields.input.SetValue("3.5")
+	m, notifications = drive(home, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if task, _ := srv.Task("t1"); string(task.CustomFields[1].Value) != "3.5" {
+		t.Errorf("number on the server = %s, want 3.5", task.CustomFields[1].Value)
+	}
+
+	// p shows the field on the cards of the view.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if got := clients.GetConfig().Layouts["v1"].Fields; len(got) != 1 || got[0] != "Points" {
+		t.Errorf("pinned fields = %v, want [Points]", got)
+	}
+	// Pinning a field keeps the cached board.
+	requests := len(srv.Requests())
+	start(NewHomeModel(srv.Client()))
+	if got := srv.Requests()[requests:]; len(got) > 0 {
+		t.Errorf("board fetched again after pinning a field: %v", got)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if view := m.View(); !strings.Contains(view, "Points: 3.5") {
+		t.Errorf("field not shown on the card:\n%s", view)
+	}
+}
+
+func TestHomeCardsShowTimeSpentAgainstTheEstimate(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.Tasks[0].TimeEstimate = 5 * time.Hour.Milliseconds()
+	fixtures.Tasks[0].TimeSpent = 3 * time.Hour.Milliseconds()
+	fixtures.Tasks[1].TimeEstimate = time.Hour.Milliseconds()
+	fixtures.Tasks[1].TimeSpent = 90 * time.Minute.Milliseconds()
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	view := m.View()
+	if !strings.Contains(view, "▰▰▰▱▱ 3h/5h") {
+		t.Errorf("half spent bar not shown:\n%s", view)
+	}
+	if !strings.Contains(view, "▰▰▰▰▰ 1.5h/1h") {
+		t.Errorf("exceeded bar not shown:\n%s", view)
+	}
+	if bar := renderEstimateBar(clients.Task{}); bar != "" {
+		t.Errorf("bar without an estimate: %q", bar)
+	}
+}
+
+func TestHomeModalPreviewsDownloadsAndUploadsAttachments(t *testing.T) {
+	var buf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	fixtures := boardFixtures()
+	fixtures.Tasks[0].Attachments = []clients.Attachment{
+		{Id: "a1", Title: "notes.txt", Mimetype: "text/plain", Size: 11, User: clients.User{Username: "ann"}},
+		{Id: "a2", Title: "logo.png", Mimetype: "image/png", Size: int64(buf.Len())},
+	}
+	fixtures.Files = map[string][]byte{"a1": []byte("first\nsecond"), "a2": buf.Bytes()}
+	downloads := t.TempDir()
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1", DownloadDir: downloads})
+	m, _ := start(NewHomeModel(srv.Client()))
+	m, _ = drive(m, tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	if view := m.View(); !strings.Contains(view, "notes.txt") || !strings.Contains(view, "11 B") || !strings.Contains(view, "ann") {
+		t.Errorf("attachments not rendered:\n%s", view)
+	}
+
+	// Text is shown as is, images with half blocks.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if view := m.View(); !strings.Contains(view, "second") {
+		t.Errorf("text not previewed:\n%s", view)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if preview := m.(HomeModel).attachments.preview; preview == nil || preview.img == nil {
+		t.Fatalf("image not decoded: %+v", preview)
+	}
+	if view := m.View(); !strings.Contains(view, "▀") {
+		t.Errorf("image not rendered:\n%s", view)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	// d saves the file in the download directory without overwriting.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyUp})
+	if err := os.WriteFile(filepath.Join(downloads, "notes.txt"), []byte("mine"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if content, err := os.ReadFile(filepath.Join(downloads, "notes (1).txt")); err != nil || string(content) != "first\nsecond" {
+		t.Errorf("downloaded %q, %v", content, err)
+	}
+
+	// u uploads a local file.
+	upload := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(upload, []byte("a,b"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(upload)})
+	m, notifications = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if task, _ := srv.Task("t1"); len(task.Attachments) != 3 || task.Attachments[2].Title != "report.csv" {
+		t.Errorf("file not uploaded: %+v", task.Attachments)
+	}
+	if home := m.(HomeModel); len(home.modalTask.Attachments) != 3 || home.attachments.cursor != 2 {
+		t.Errorf("uploaded file not selected in the modal: %+v", home.modalTask.Attachments)
+	}
+}
+
+func TestHomeExternalEditorMergesConcurrentChanges(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.Tasks[0].Description = "line one\nline two"
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// The editor itself is not run: its temp file is edited in its place.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	edit := m.(HomeModel).external
+	if err := os.WriteFile(edit.path, []byte("line one!\nline two\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	theirs := "line one\nline two\nline three"
+	if _, err := srv.Client().UpdateTask(context.Background(), "t1", clients.TaskUpdate{Description: &theirs}); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = drive(m, editorFinished(edit)(nil))
+	if !m.(HomeModel).external.conflict {
+		t.Fatal("conflict not detected")
+	}
+
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	want := "line one!\nline two\nline three"
+	if task, _ := srv.Task("t1"); task.Description != want {
+		t.Errorf("server description = %q, want %q", task.Description, want)
+	}
+	if home := m.(HomeModel); home.external.conflict || home.modalTask.Description != want {
+		t.Errorf("modal not updated: %q", home.modalTask.Description)
+	}
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	edit = m.(HomeModel).external
+	if err := os.WriteFile(edit.path, []byte("Looks good\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = drive(m, editorFinished(edit)(nil))
+	if comments := srv.Comments("t1"); len(comments) != 1 || comments[0].CommentText != "Looks good" {
+		t.Errorf("unexpected comments on the server %+v", comments)
+	}
+	if comments := m.(HomeModel).modalTask.Comments; len(comments) != 1 {
+		t.Errorf("modal comments not refreshed: %+v", comments)
+	}
+}
+
+func TestHomeCommentThreads(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.User = clients.User{Id: 7, Username: "me", Initials: "ME"}
+	fixtures.Tasks[0].Assignees = []clients.User{{Id: 8, Username: "alice", Initials: "AL"}}
+	fixtures.Comments = map[string][]clients.Comment{"t1": {
+		{Id: "c1", CommentText: "First", Comment: []clients.CommentText{{Text: "First"}}, ReplyCount: 1},
+		{Id: "c2", CommentText: "Second @bob", Comment: []clients.CommentText{{Text: "Second "}, {Type: "tag", User: &clients.User{Id: 9, Username: "bob"}}}},
+	}}
+	fixtures.Replies = map[string][]clients.Comment{"c1": {{Id: "r1", CommentText: "Reply", Comment: []clients.CommentText{{Text: "Reply"}}}}}
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1", UserId: "7"})
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	home := m.(HomeModel)
+	items := home.commentItems()
+	if len(items) != 3 || items[1].comment.Id != "r1" || !items[1].reply || items[2].comment.Id != "c2" {
+		t.Fatalf("replies not threaded under their comment: %+v", items)
+	}
+
+	// Reply to the thread from the reply itself, then react to it.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	edit := m.(HomeModel).external
+	if err := os.WriteFile(edit.path, []byte("Agreed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = drive(m, editorFinished(edit)(nil))
+	if replies := srv.Replies("c1"); len(replies) != 2 || replies[1].CommentText != "Agreed" {
+		t.Errorf("reply not posted in the thread: %+v", replies)
+	}
+	home = m.(HomeModel)
+	if len(home.commentItems()) != 4 {
+		t.Errorf("thread not reloaded: %+v", home.modalTask.Comments)
+	}
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("+")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if replies := srv.Replies("c1"); len(replies[0].Reactions) != 1 || replies[0].Reactions[0].Reaction != "thumbsup" {
+		t.Errorf("reaction not added: %+v", replies[0].Reactions)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("+")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if replies := srv.Replies("c1"); len(replies[0].Reactions) != 0 {
+		t.Errorf("reaction not removed: %+v", replies[0].Reactions)
+	}
+
+	// Assign the last comment, then resolve it.
+	for i := 0; i < 3; i++ {
+		m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("alice")})
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	comment := srv.Comments("t1")[1]
+	if comment.Assignee == nil || comment.Assignee.Username != "alice" || !comment.Resolved {
+		t.Errorf("comment not assigned and resolved: %+v", comment)
+	}
+	// The text is not sent back: its plain version would replace the mention.
+	if len(comment.Comment) != 2 || comment.Comment[1].User == nil {
+		t.Errorf("comment text rewritten: %+v", comment.Comment)
+	}
+	if home = m.(HomeModel); !home.modalTask.Comments[1].Resolved {
+		t.Errorf("modal not refreshed: %+v", home.modalTask.Comments[1])
+	}
+}
+
+func TestHomeGroupsBoardInColumnsAndLanes(t *testing.T) {
+	fixtures := boardFixtures()
+	alice := clients.User{Id: 8, Username: "alice", Initials: "AL"}
+	bob := clients.User{Id: 9, Username: "bob", Initials: "BO"}
+	fixtures.Tasks[0].Assignees = []clients.User{bob, alice}
+	fixtures.Tasks[1].Assignees = []clients.User{alice}
+	fixtures.Tasks = append(fixtures.Tasks, clients.Task{Id: "t3", Name: "Third", Status: fixtures.Tasks[1].Status})
+	fixtures.Views["v1"] = append(fixtures.Views["v1"], "t3")
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+
+	labels := func(groups []boardGroup) []string {
+		var names []string
+		for _, g := range groups {
+			names = append(names, g.label)
+		}
+		return names
+	}
+	ids := func(tasks []clients.Task) []string {
+		var names []string
+		for _, task := range tasks {
+			names = append(names, task.Id)
+		}
+		return names
+	}
+
+	// g groups the columns by assignee: a task is in the column of each assignee.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	home := m.(HomeModel)
+	columns := home.boardColumns()
+	if got := labels(columns); len(got) != 3 || got[0] != "alice" || got[1] != "bob" || got[2] != "unassigned" {
+		t.Fatalf("unexpected assignee columns %v", got)
+	}
+	if got := ids(home.cellTasks(boardGroup{}, columns[0])); len(got) != 2 || got[0] != "t1" || got[1] != "t2" {
+		t.Errorf("unexpected tasks for alice: %v", got)
+	}
+
+	// G adds swimlanes by status; down walks the lanes of a column.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	home = m.(HomeModel)
+	if got := labels(home.boardLanes()); len(got) != 2 || got[0] != "to do" || got[1] != "in progress" {
+		t.Fatalf("unexpected status lanes %v", got)
+	}
+	if card, _ := home.selectedCard(); card.Id != "t1" {
+		t.Errorf("selected %q, want t1", card.Id)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+	if home := m.(HomeModel); home.selectedLane != 1 {
+		t.Errorf("down did not move to the next lane: lane %d", home.selectedLane)
+	} else if card, _ := home.selectedCard(); card.Id != "t2" {
+		t.Errorf("selected %q, want t2", card.Id)
+	}
+
+	m, _ = drive(m, tea.WindowSizeMsg{Width: 160, Height: 60})
+	if view := m.View(); !strings.Contains(view, "▌ TO DO (1)") || !strings.Contains(view, "▌ IN PROGRESS (2)") || !strings.Contains(view, "by assignee × status lanes") {
+		t.Errorf("lanes not rendered:\n%s", view)
+	}
+
+	// Cards only move between status columns.
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyShiftRight})
+	if len(notifications) != 1 || notifications[0].Err != errMoveNotByStatus {
+		t.Errorf("expected a notification about the grouping, got %v", notifications)
+	}
+	if task, _ := srv.Task("t2"); task.Status.Status != "in progress" {
+		t.Errorf("task moved to %q", task.Status.Status)
+	}
+
+	// The layout is remembered for the view.
+	if layout := clients.GetConfig().Layouts["v1"]; layout.Columns != groupAssignee || layout.Lanes != groupStatus {
+		t.Errorf("layout not saved: %+v", layout)
+	}
+	m, _ = start(NewHomeModel(srv.Client()))
+	if home := m.(HomeModel); home.layout.Columns != groupAssignee || home.layout.Lanes != groupStatus || len(home.boardColumns()) != 3 {
+		t.Errorf("layout not restored: %+v", home.layout)
+	}
+}
+
+func TestHomeRefreshKeepsSelectionAndHighlightsChanges(t *testing.T) {
+	fixtures := boardFixtures()
+	fixtures.Tasks = append(fixtures.Tasks, clients.Task{Id: "t3", Name: "Third", Status: fixtures.Tasks[1].Status})
+	fixtures.Views["v1"] = append(fixtures.Views["v1"], "t3")
+	srv := newTestServer(t, fixtures, clients.Config{ViewId: "v1"})
+	m, _ := start(NewHomeModel(srv.Client()))
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRight})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+
+	renamed := fixtures.Tasks[0]
+	renamed.Name = "First, renamed"
+	srv.PutTask(renamed)
+	before := len(srv.Requests())
+	m, notifications := drive(m, components.RefreshMsg{})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	// Only the tasks updated since the last sync are fetched.
+	for _, request := range srv.Requests()[before:] {
+		if !strings.Contains(request, "date_updated_gt=") {
+			t.Errorf("full fetch on refresh: %s", request)
+		}
+	}
+	home := m.(HomeModel)
+	if card, _ := home.selectedCard(); card.Id != "t3" || home.loading {
+		t.Errorf("selected %q after the refresh, want t3", card.Id)
+	}
+	if got := home.columns["to do"].tasks; len(got) != 1 || got[0].Name != "First, renamed" {
+		t.Errorf("board not refreshed: %+v", got)
+	}
+	if len(home.changes) != 1 || home.changes["t1"].before.Name != "First" {
+		t.Fatalf("unexpected changes %+v", home.changes)
+	}
+
+	// w shows what changed on the selected card; closing the popover marks it as seen.
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyLeft})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if view := m.View(); !strings.Contains(view, "What changed") || !strings.Contains(view, "First → First, renamed") {
+		t.Errorf("changes not shown:\n%s", view)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if home := m.(HomeModel); len(home.changes) != 0 || home.showChanges != "" {
+		t.Errorf("change not marked as seen: %+v", home.changes)
+	}
+}
+
+func TestDiffTasks(t *testing.T) {
+	before := clients.Task{Name: "Task", Status: clients.Status{Status: "to do"}, Description: "old"}
+	after := before
+	after.Status = clients.Status{Status: "done"}
+	after.Description = "new"
+	changes := diffTasks(before, after)
+	if len(changes) != 2 || changes[0].label != "Status" || changes[0].from != "to do" || changes[0].to != "done" || changes[1].label != "Description" {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+	if changes := diffTasks(before, before); len(changes) != 0 {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+}
@@ -1,29 +1,37 @@
 package views
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/ui/components"
 	ui "github.com/mceck/clickup-tui/internal/ui/styles"
 )
 
 type SettingsModel struct {
+	client          clients.ClickupAPI
 	token           textinput.Model
 	teamId          textinput.Model
 	viewId          textinput.Model
+	refreshInterval textinput.Model
 	timesheetFilter textinput.Model // New field for timesheet filters
 	initialView     string          // New field for the initial view ('kanban' or 'timesheet')
 
 	focusIndex int
 	inputs     []textinput.Model
+	picking    bool
+	picker     components.Picker
 	width      int
 	height     int
 }
 
-func NewSettingsModel() SettingsModel {
+func NewSettingsModel(client clients.ClickupAPI) SettingsModel {
 	config := clients.GetConfig()
 
 	token := textinput.New()
@@ -50,7 +58,15 @@ func NewSettingsModel() SettingsModel {
 	timesheetFilter.Width = 60
 	timesheetFilter.SetValue(config.TimesheetFilter)
 
-	inputs := []textinput.Model{token, teamId, viewId, timesheetFilter}
+	refreshInterval := textinput.New()
+	refreshInterval.Placeholder = "0 disables the background refresh"
+	refreshInterval.CharLimit = 6
+	refreshInterval.Width = 60
+	if config.RefreshInterval > 0 {
+		refreshInterval.SetValue(strconv.Itoa(config.RefreshInterval))
+	}
+
+	inputs := []textinput.Model{token, teamId, viewId, timesheetFilter, refreshInterval}
 
 	initialView := config.InitialView
 	if initialView != "kanban" && initialView != "timesheet" {
@@ -58,10 +74,12 @@ func NewSettingsModel() SettingsModel {
 	}
 
 	m := SettingsModel{
+		client:          client,
 		token:           token,
 		teamId:          teamId,
 		viewId:          viewId,
 		timesheetFilter: timesheetFilter,
+		refreshInterval: refreshInterval,
 		initialView:     initialView,
 		inputs:          inputs,
 		focusIndex:      0,
@@ -83,6 +101,10 @@ func (m SettingsModel) Init() tea.Cmd {
 func (m SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	if m.picking {
+		return m.handlePickerEvent(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -94,51 +116,18 @@ func (m SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(func() tea.Msg {
 				return tea.KeyMsg{Type: tea.KeyTab}
 			})
-		case "enter":
-			token := m.token.Value()
-			if token == "" {
-				return m, nil
+		case "ctrl+o":
+			if m.token.Value() != "" {
+				return m, m.openPicker()
 			}
-			config := clients.GetConfig()
-			client := clients.NewClickupClient(config.ClickupToken, config.TeamId)
-
-			teamId := m.teamId.Value()
-			if teamId == "" {
-				teams, err := client.GetTeams(token)
-				if err != nil || len(teams) == 0 {
-					return m, nil
-				}
-				teamId = teams[0].Id
-				m.inputs[1].SetValue(teamId)
-				if len(teams) > 1 {
-					return m, nil
-				}
-			}
-			user, err := client.GetCurrentUser(token)
-			if err != nil {
-				return m, nil
-			}
-
-			userId := ""
-			switch v := user.Id.(type) {
-			case float64:
-				userId = fmt.Sprintf("%.0f", v)
-			case string:
-				userId = v
-			default:
+		case "enter":
+			if m.token.Value() == "" {
 				return m, nil
 			}
-
-			c := clients.Config{
-				ClickupToken:    token,
-				TeamId:          teamId,
-				UserId:          userId,
-				ViewId:          m.viewId.Value(),
-				TimesheetFilter: m.timesheetFilter.Value(),
-				InitialView:     m.initialView,
+			if m.teamId.Value() == "" || m.viewId.Value() == "" {
+				return m, m.openPicker()
 			}
-			clients.SaveConfig(c)
-			return m, tea.Quit
+			return m.save()
 
 		case "up", "down":
 			if msg.String() == "up" {
@@ -172,6 +161,85 @@ func (m SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+func (m *SettingsModel) openPicker() tea.Cmd {
+	m.picking = true
+	m.picker = components.NewPicker(m.client, m.token.Value())
+	m.picker.SetSize(m.width, m.height)
+	return m.picker.Init()
+}
+
+// handlePickerEvent routes the messages to the team and view picker while it is open.
+// The chosen team and view fill the form, which is then saved.
+func (m SettingsModel) handlePickerEvent(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.picker.SetSize(m.width, m.height)
+		return m, nil
+	case components.PickedMsg:
+		if msg.PickerId != m.picker.Id() {
+			return m, nil
+		}
+		m.picking = false
+		m.inputs[1].SetValue(msg.Choice.TeamId)
+		m.inputs[2].SetValue(msg.Choice.ViewId)
+		m.syncFields()
+		return m.save()
+	case components.PickerCanceledMsg:
+		if msg.PickerId == m.picker.Id() {
+			m.picking = false
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	return m, cmd
+}
+
+func (m SettingsModel) save() (tea.Model, tea.Cmd) {
+	token := m.token.Value()
+	user, err := m.client.GetCurrentUser(context.Background(), token)
+	if err != nil {
+		return m, components.Notify("Loading current user", err, nil)
+	}
+
+	userId := ""
+	switch v := user.Id.(type) {
+	case float64:
+		userId = fmt.Sprintf("%.0f", v)
+	case string:
+		userId = v
+	default:
+		return m, nil
+	}
+
+	refreshInterval := 0
+	if value := strings.TrimSpace(m.refreshInterval.Value()); value != "" {
+		refreshInterval, err = strconv.Atoi(value)
+		if err != nil || refreshInterval < 0 {
+			return m, components.Notify("Saving config", fmt.Errorf("the refresh interval must be a number of seconds, got %q", value), nil)
+		}
+	}
+
+	// Start from the saved config, so that the settings without a field here are kept.
+	c := clients.GetConfig()
+	c.ClickupToken = token
+	c.TeamId = m.teamId.Value()
+	c.UserId = userId
+	c.ViewId = m.viewId.Value()
+	c.TimesheetFilter = m.timesheetFilter.Value()
+	c.RefreshInterval = refreshInterval
+	c.InitialView = m.initialView
+	if err := clients.SaveConfig(c); err != nil {
+		return m, components.Notify("Saving config", err, nil)
+	}
+	return m, tea.Quit
+}
+
 func (m *SettingsModel) updateFocus() []tea.Cmd {
 	cmds := make([]tea.Cmd, len(m.inputs))
 	for i := range m.inputs {
@@ -201,14 +269,18 @@ func (m *SettingsModel) syncFields() {
 	m.teamId = m.inputs[1]
 	m.viewId = m.inputs[2]
 	m.timesheetFilter = m.inputs[3]
+	m.refreshInterval = m.inputs[4]
 }
 
 func (m SettingsModel) View() string {
 	if m.width == 0 {
 		return "Loading..."
 	}
+	if m.picking {
+		return m.picker.View()
+	}
 
-	title := ui.TitleStyle.Render("Impostazioni ClickUp")
+	title := ui.TitleStyle.Render("Impostazioni ClickUp · " + clients.GetConfig().Profile)
 	labelWidth := 20 // Increased width for better alignment
 
 	var inputRows []string
@@ -244,7 +316,7 @@ func (m SettingsModel) View() string {
 		radioRow,
 	)
 	formBox := ui.PanelStyle.Width(m.width - 4).Render(formContent)
-	footer := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("[↑ ← → ↓] Move      [enter] Save and quit     [esc/tab] Go back")
+	footer := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render("[↑ ← → ↓] Move      [ctrl+o] Browse teams and views     [enter] Save and quit     [esc/tab] Go back")
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -257,7 +329,7 @@ func (m SettingsModel) View() string {
 }
 
 func (m SettingsModel) getLabel(index int) string {
-	labels := []string{"Token", "Team ID", "View ID", "Timesheet Filter", "Initial View"}
+	labels := []string{"Token", "Team ID", "View ID", "Timesheet Filter", "Refresh (seconds)", "Initial View"}
 	if index >= 0 && index < len(labels) {
 		return labels[index]
 	}
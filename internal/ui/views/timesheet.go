@@ -1,8 +1,11 @@
 package views
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,6 +16,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mceck/clickup-tui/internal/clients"
 	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
 	ui "github.com/mceck/clickup-tui/internal/ui/styles"
 	"golang.org/x/term"
 )
@@ -21,6 +25,8 @@ type TimeEntryR struct {
 	TaskId   string
 	TaskName string
 	Hours    map[string]float64
+	Entries  map[string][]clients.TimeEntry
+	Estimate float64 // hours, 0 when the task has no estimate
 }
 
 type loadedTimesheetMsg struct {
@@ -28,6 +34,20 @@ type loadedTimesheetMsg struct {
 	err       error
 }
 
+type historyLoadedMsg struct {
+	hours      map[string]float64 // by task id
+	recentFrom time.Time
+	err        error
+}
+
+type trackingUpdatedMsg struct {
+	taskId  string
+	day     time.Time
+	hours   float64
+	entries []clients.TimeEntry
+	err     error
+}
+
 type position struct {
 	x, y, width, height int
 }
@@ -46,56 +66,71 @@ type tsStyles struct {
 	totalStyle        lipgloss.Style
 	totalOkStyle      lipgloss.Style
 	totalOverStyle    lipgloss.Style
+	trackedStyle      lipgloss.Style
+	trackedOverStyle  lipgloss.Style
 	helpStyle         lipgloss.Style
 	loadingStyle      lipgloss.Style
 }
 
 type TimesheetModel struct {
-	width        int
-	height       int
-	wndwSize     int
-	wndwOffset   int
-	timesheet    []TimeEntryR
-	filtered     []TimeEntryR
-	weekFrom     time.Time
-	weekDays     [5]string
-	cursorRow    int
-	cursorCol    int
-	editing      bool
-	editBuffer   string
-	firstEdit    bool
-	cursorPos    int
-	searchMode   bool
-	searchQuery  string
-	loading      bool
-	spinner      spinner.Model
-	styles       tsStyles
-	taskColWidth int
-	dayColWidth  int
+	client          clients.ClickupAPI
+	width           int
+	height          int
+	wndwSize        int
+	wndwOffset      int
+	timesheet       []TimeEntryR
+	filtered        []TimeEntryR
+	weekFrom        time.Time // midnight of the first day of the week
+	week            clients.WorkWeek
+	dayOffsets      []int              // days from weekFrom of the day columns
+	holidays        map[string]string  // names of the public holidays, by date
+	history         map[string]float64 // hours tracked before recentFrom by task id, nil hides the Tracked column
+	recentFrom      time.Time
+	cursorRow       int
+	cursorCol       int
+	editing         bool
+	editBuffer      string
+	firstEdit       bool
+	cursorPos       int
+	searchMode      bool
+	searchQuery     string
+	loading         bool
+	interrupted     bool
+	refreshing      bool
+	ctx             context.Context
+	cancel          context.CancelFunc
+	entries         entriesPanel
+	spinner         spinner.Model
+	styles          tsStyles
+	queued          map[string]clients.WriteState // state of the offline changes, by task id and day
+	taskColWidth    int
+	dayColWidth     int
+	trackedColWidth int
 }
 
 const (
 	colTask = iota
-	colMon
-	colTue
-	colWed
-	colThu
-	colFri
+	colFirstDay
 )
 
-func fetchTimesheetEntries() tea.Msg {
+func fetchTimesheetEntries(ctx context.Context, client clients.ClickupAPI) tea.Cmd {
+	return func() tea.Msg {
+		return loadTimesheetEntries(ctx, client)
+	}
+}
+
+func loadTimesheetEntries(ctx context.Context, client clients.ClickupAPI) tea.Msg {
 	config := clients.GetConfig()
-	client := clients.NewClickupClient(config.ClickupToken, config.TeamId)
 	userId := config.UserId
 	filter := config.TimesheetFilter
 	if filter == "" {
-		filter = "tags[]=timesheet"
+		filter = clients.DefaultTimesheetFilter
 	}
-	tasks, err := client.GetTimesheetTasks(filter)
+	tasks, err := client.GetTimesheetTasks(ctx, filter)
 	if err != nil {
 		return loadedTimesheetMsg{err: err}
 	}
-	trackings, err := client.GetTimesheetsEntries(userId)
+	trackings, err := client.GetTimesheetsEntries(ctx, userId)
 	if err != nil {
 		return loadedTimesheetMsg{err: err}
 	}
@@ -107,6 +142,8 @@ func fetchTimesheetEntries() tea.Msg {
 			TaskId:   task.Id,
 			TaskName: task.Name,
 			Hours:    make(map[string]float64),
+			Entries:  make(map[string][]clients.TimeEntry),
+			Estimate: float64(task.TimeEstimate) / float64(time.Hour/time.Millisecond),
 		}
 	}
 
@@ -119,22 +156,64 @@ func fetchTimesheetEntries() tea.Msg {
 					TaskId:   taskId,
 					TaskName: t["name"].(string),
 					Hours:    make(map[string]float64),
+					Entries:  make(map[string][]clients.TimeEntry),
 				}
 			}
 			day := shared.ToDateString(tracking.Start)
 			entry.Hours[day] += shared.ToHours(tracking.Duration)
+			entry.Entries[day] = append(entry.Entries[day], tracking)
 			timesheetMap[taskId] = entry
 		}
 	}
 
 	datats := make([]TimeEntryR, 0, len(timesheetMap))
 	for _, entry := range timesheetMap {
+		for _, entries := range entry.Entries {
+			sort.Slice(entries, func(i, j int) bool {
+				return shared.ToInt(entries[i].Start) < shared.ToInt(entries[j].Start)
+			})
+		}
 		datats = append(datats, entry)
 	}
 
 	return loadedTimesheetMsg{timesheet: datats}
 }
 
+// fetchTrackedHistory sums the hours the user tracked on each task before the recent
+// entries, for the Tracked column. The grid does not wait for it.
+func fetchTrackedHistory(ctx context.Context, client clients.ClickupAPI) tea.Cmd {
+	return func() tea.Msg {
+		recentFrom := clients.RecentEntriesStart(time.Now())
+		entries, err := client.GetOlderTimeEntries(ctx, clients.GetConfig().UserId)
+		if err != nil {
+			return historyLoadedMsg{err: err}
+		}
+		hours := make(map[string]float64)
+		for _, entry := range entries {
+			hours[entry.TaskId()] += shared.ToHours(entry.Duration)
+		}
+		return historyLoadedMsg{hours: hours, recentFrom: recentFrom}
+	}
+}
+
+// updateTracking persists the tracked hours of a cell and reloads the entries behind it.
+// Like every write, it is not cancelled when the user leaves the view.
+func updateTracking(client clients.ClickupAPI, taskId string, day time.Time, hours float64) tea.Cmd {
+	return func() tea.Msg {
+		config := clients.GetConfig()
+		ctx := context.Background()
+		if err := client.UpdateTracking(ctx, config.UserId, taskId, day, hours); err != nil {
+			return trackingUpdatedMsg{taskId: taskId, day: day, hours: hours, err: err}
+		}
+		allEntries, err := client.GetTimesheetsEntries(ctx, config.UserId)
+		if err != nil {
+			return trackingUpdatedMsg{taskId: taskId, day: day, hours: hours, err: err}
+		}
+		entries := clients.FilterTimeEntries(allEntries, taskId, day)
+		return trackingUpdatedMsg{taskId: taskId, day: day, hours: hours, entries: entries}
+	}
+}
+
 func sortTimesheetEntries(entries []TimeEntryR, weekStart time.Time) []TimeEntryR {
 	sorted := make([]TimeEntryR, len(entries))
 	copy(sorted, entries)
@@ -178,22 +257,26 @@ func filterTimesheet(entries []TimeEntryR, query string) []TimeEntryR {
 	return filtered
 }
 
-func NewTimesheetModel() TimesheetModel {
+func NewTimesheetModel(client clients.ClickupAPI) TimesheetModel {
 	s := spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("205"))))
 	now := time.Now()
-	weekFrom := now.AddDate(0, 0, -int(now.Weekday())+1)
+	week := clients.GetConfig().WorkWeek
 
+	ctx, cancel := context.WithCancel(context.Background())
 	m := TimesheetModel{
+		client:    client,
+		ctx:       ctx,
+		cancel:    cancel,
 		timesheet: []TimeEntryR{},
 		filtered:  []TimeEntryR{},
-		weekFrom:  weekFrom,
+		weekFrom:  now,
 		loading:   true,
 		spinner:   s,
-		cursorCol: int(now.Weekday()),
 	}
-
-	if m.cursorCol == 0 || m.cursorCol > 5 {
-		m.cursorCol = 1
+	m.setWorkWeek(week)
+	m.cursorCol = colFirstDay
+	if i := slices.Index(week.Weekdays(), now.Weekday()); i >= 0 {
+		m.cursorCol = colFirstDay + i
 	}
 
 	width, height, err := term.GetSize(int(os.Stdout.Fd()))
@@ -215,11 +298,18 @@ func (m *TimesheetModel) setSize(width, height int) {
 		m.wndwSize = 1
 	}
 
-	numColumns := 6
+	// A day takes 2 units of the width, the tracked hours 3 and the task name at least 8.
+	numDays := len(m.dayOffsets)
+	numColumns, trackedUnits := numDays+1, 0
+	if m.history != nil {
+		numColumns, trackedUnits = numColumns+1, 3
+	}
 	padding := 2
 	availableWidth := m.width - (numColumns * padding)
-	m.taskColWidth = availableWidth * 4 / 9
-	m.dayColWidth = availableWidth / 9
+	units := 2*numDays + trackedUnits + 8
+	m.dayColWidth = availableWidth * 2 / units
+	m.trackedColWidth = availableWidth * trackedUnits / units
+	m.taskColWidth = availableWidth - numDays*m.dayColWidth - m.trackedColWidth
 
 	m.styles.headerStyle = ui.SubtitleStyle.Width(m.dayColWidth).Align(lipgloss.Center).BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("240"))
 	m.styles.taskHeaderStyle = m.styles.headerStyle.Width(m.taskColWidth).Foreground(lipgloss.Color("212"))
@@ -234,6 +324,8 @@ func (m *TimesheetModel) setSize(width, height int) {
 	m.styles.totalStyle = m.styles.cellStyle.Foreground(lipgloss.Color("208"))
 	m.styles.totalOkStyle = m.styles.totalStyle.Foreground(lipgloss.Color("72"))
 	m.styles.totalOverStyle = m.styles.totalStyle.Foreground(lipgloss.Color("134"))
+	m.styles.trackedStyle = m.styles.cellStyle.Width(m.trackedColWidth)
+	m.styles.trackedOverStyle = m.styles.trackedStyle.Foreground(ui.Error)
 	m.styles.helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Padding(0, 1)
 	m.styles.loadingStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#874BFD")).MarginLeft(2)
 }
@@ -297,7 +389,7 @@ func (m *TimesheetModel) startEditing() {
 	}
 	m.editing = true
 	m.firstEdit = true
-	dayKey := m.weekFrom.AddDate(0, 0, m.cursorCol-1).Format("2006-01-02")
+	dayKey := m.dayAt(m.cursorCol).Format("2006-01-02")
 	hours := m.activeTimesheet()[m.cursorRow].Hours[dayKey]
 	m.editBuffer = fmt.Sprintf("%.2f", hours)
 	m.cursorPos = len(m.editBuffer)
@@ -305,17 +397,75 @@ func (m *TimesheetModel) startEditing() {
 
 func (m TimesheetModel) Init() tea.Cmd {
 	if m.loading {
-		return tea.Batch(fetchTimesheetEntries, m.spinner.Tick)
+		return tea.Batch(fetchTimesheetEntries(m.ctx, m.client), fetchTrackedHistory(m.ctx, m.client), loadHolidays(m.week), m.spinner.Tick)
 	}
 	return nil
 }
 
+// reload cancels the requests still running for the view and fetches the timesheet again.
+func (m *TimesheetModel) reload() tea.Cmd {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.loading = true
+	m.interrupted = false
+	m.setWorkWeek(clients.GetConfig().WorkWeek)
+	return tea.Batch(fetchTimesheetEntries(m.ctx, m.client), fetchTrackedHistory(m.ctx, m.client), loadHolidays(m.week), m.spinner.Tick)
+}
+
+// refresh fetches the timesheet again in the background, without the spinner.
+// It waits while the user is changing the hours.
+func (m *TimesheetModel) refresh() tea.Cmd {
+	if m.loading || m.refreshing || m.EditingText() || m.entries.open {
+		return nil
+	}
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.refreshing = true
+	clients.ClearTimesheetTasksCache()
+	clients.ClearTimeentriesCache()
+	return tea.Batch(fetchTimesheetEntries(m.ctx, m.client), fetchTrackedHistory(m.ctx, m.client))
+}
+
+// setTimesheet replaces the rows, keeping the cursor on the same task.
+func (m *TimesheetModel) setTimesheet(timesheet []TimeEntryR) {
+	var taskId string
+	if active := m.activeTimesheet(); m.cursorRow < len(active) {
+		taskId = active[m.cursorRow].TaskId
+	}
+	m.timesheet = timesheet
+	m.reapplyFiltersAndSort()
+	if i := slices.IndexFunc(m.activeTimesheet(), func(e TimeEntryR) bool { return e.TaskId == taskId }); i >= 0 {
+		m.cursorRow = i
+	}
+}
+
 func (m TimesheetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case LoadMsg:
-		m.loading = true
-		cmd = tea.Batch(fetchTimesheetEntries, m.spinner.Tick)
+		cmd = m.reload()
+	case LeaveMsg:
+		if m.cancel != nil {
+			m.cancel()
+		}
+		if m.loading {
+			m.loading = false
+			m.interrupted = true
+		}
+	case EnterMsg:
+		if m.interrupted {
+			cmd = m.reload()
+		}
+	case components.TimerStoppedMsg, components.QueueChangedMsg:
+		if !m.loading {
+			cmd = m.reload()
+		}
+	case components.RefreshMsg:
+		cmd = m.refresh()
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -327,11 +477,46 @@ func (m TimesheetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.setSize(msg.Width, msg.Height)
 	case loadedTimesheetMsg:
+		refreshing := m.refreshing
+		m.refreshing = false
+		if clients.IsCanceled(msg.err) {
+			break
+		}
 		m.loading = false
+		if refreshing && clients.IsOffline(msg.err) {
+			// The header already tells that ClickUp cannot be reached.
+			break
+		}
+		if msg.err != nil {
+			cmd = components.Notify("Loading timesheet", msg.err, fetchTimesheetEntries(m.ctx, m.client))
+		} else {
+			m.setTimesheet(msg.timesheet)
+		}
+	case historyLoadedMsg:
+		// Without the history the grid is shown without the Tracked column, or with the
+		// one loaded before.
 		if msg.err == nil {
-			m.timesheet = msg.timesheet
-			m.reapplyFiltersAndSort()
+			m.history, m.recentFrom = msg.hours, msg.recentFrom
+			if m.width > 0 {
+				m.setSize(m.width, m.height)
+			}
+		}
+	case holidaysLoadedMsg:
+		if msg.err != nil {
+			cmd = components.Notify("Loading the work week", msg.err, nil)
+		} else {
+			m.holidays = msg.holidays
+		}
+	case trackingUpdatedMsg:
+		if msg.err != nil {
+			cmd = components.Notify("Updating tracked time", msg.err, updateTracking(m.client, msg.taskId, msg.day, msg.hours))
+		} else {
+			m.setEntries(msg.taskId, msg.day, msg.entries)
 		}
+	case timeEntryUpdatedMsg:
+		cmd = m.handleTimeEntryUpdated(msg)
+	case timeEntryDeletedMsg:
+		cmd = m.handleTimeEntryDeleted(msg)
 	}
 
 	m.clampCursor()
@@ -340,8 +525,10 @@ func (m TimesheetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m *TimesheetModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
-	if m.editing {
-		m.handleEditingInput(msg)
+	if m.entries.open {
+		cmd = m.handleEntriesInput(msg)
+	} else if m.editing {
+		cmd = m.handleEditingInput(msg)
 	} else if m.searchMode {
 		m.handleSearchInput(msg)
 	} else {
@@ -351,77 +538,17 @@ func (m *TimesheetModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func parseHoursInput(input string) (float64, error) {
-	var hours, minutes float64
-	input = strings.ToLower(strings.TrimSpace(input))
-
-	if strings.Contains(input, "h") || strings.Contains(input, "m") {
-		parts := strings.Fields(input)
-		if len(parts) == 0 {
-			parts = []string{input}
-		}
-
-		for _, part := range parts {
-			if strings.Contains(part, "h") && strings.Contains(part, "m") {
-				hPart := strings.Split(part, "h")[0]
-				mPart := strings.Split(strings.Split(part, "h")[1], "m")[0]
-
-				h, err := strconv.ParseFloat(hPart, 64)
-				if err != nil {
-					return 0, err
-				}
-				hours = h
-
-				m, err := strconv.ParseFloat(mPart, 64)
-				if err != nil {
-					return 0, err
-				}
-				minutes = m
-				continue
-			}
-
-			if strings.HasSuffix(part, "h") {
-				h, err := strconv.ParseFloat(strings.TrimSuffix(part, "h"), 64)
-				if err != nil {
-					return 0, err
-				}
-				hours = h
-			}
-			if strings.HasSuffix(part, "m") {
-				m, err := strconv.ParseFloat(strings.TrimSuffix(part, "m"), 64)
-				if err != nil {
-					return 0, err
-				}
-				minutes = m
-			}
-		}
-		return hours + minutes/60.0, nil
-	}
-
-	return strconv.ParseFloat(input, 64)
-}
-
-func (m *TimesheetModel) handleEditingInput(msg tea.KeyMsg) {
+func (m *TimesheetModel) handleEditingInput(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
 	switch msg.Type {
 	case tea.KeyEnter:
-		newHours, err := parseHoursInput(m.editBuffer)
-		if err == nil && newHours >= 0 {
+		newHours, err := shared.ParseHoursInput(m.editBuffer)
+		if err != nil || newHours < 0 {
+			cmd = components.Notify("Invalid hours", fmt.Errorf("cannot parse %q", m.editBuffer), nil)
+		} else {
 			entry := m.activeTimesheet()[m.cursorRow]
-			day := m.weekFrom.AddDate(0, 0, m.cursorCol-1)
-			config := clients.GetConfig()
-			client := clients.NewClickupClient(config.ClickupToken, config.TeamId)
-			if updateErr := client.UpdateTracking(config.UserId, entry.TaskId, day, newHours); updateErr != nil {
-				fmt.Fprintf(os.Stderr, "Error updating tracking for task %s: %v\n", entry.TaskId, updateErr)
-			} else {
-				dayKey := day.Format("2006-01-02")
-				for i := range m.timesheet {
-					if m.timesheet[i].TaskId == entry.TaskId {
-						m.timesheet[i].Hours[dayKey] = newHours
-						break
-					}
-				}
-				m.reapplyFiltersAndSort()
-			}
+			day := m.dayAt(m.cursorCol)
+			cmd = updateTracking(m.client, entry.TaskId, day, newHours)
 		}
 		m.stopEditing()
 	case tea.KeyEscape:
@@ -448,6 +575,7 @@ func (m *TimesheetModel) handleEditingInput(msg tea.KeyMsg) {
 		m.editBuffer = m.editBuffer[:m.cursorPos] + string(msg.Runes) + m.editBuffer[m.cursorPos:]
 		m.cursorPos += len(msg.Runes)
 	}
+	return cmd
 }
 
 func (m *TimesheetModel) handleSearchInput(msg tea.KeyMsg) {
@@ -477,12 +605,18 @@ func (m *TimesheetModel) handleNavigationInput(msg tea.KeyMsg) tea.Cmd {
 	case "r":
 		clients.ClearTimesheetTasksCache()
 		clients.ClearTimeentriesCache()
-		m.loading = true
-		return tea.Batch(fetchTimesheetEntries, m.spinner.Tick)
+		return m.reload()
 	case "enter":
 		if m.cursorCol > colTask && len(m.activeTimesheet()) > 0 {
 			m.startEditing()
 		}
+	case "i":
+		m.openEntries()
+	case "t":
+		if len(m.activeTimesheet()) > 0 {
+			entry := m.activeTimesheet()[m.cursorRow]
+			return components.ToggleTimer(entry.TaskId, entry.TaskName)
+		}
 	case "up":
 		if m.cursorRow > 0 {
 			m.cursorRow--
@@ -492,17 +626,17 @@ func (m *TimesheetModel) handleNavigationInput(msg tea.KeyMsg) tea.Cmd {
 			m.cursorRow++
 		}
 	case "left":
-		if m.cursorCol > colMon {
+		if m.cursorCol > colFirstDay {
 			m.cursorCol--
 		} else {
-			m.weekFrom, m.cursorCol = m.weekFrom.AddDate(0, 0, -7), colFri
+			m.weekFrom, m.cursorCol = m.weekFrom.AddDate(0, 0, -7), m.lastDayCol()
 			m.reapplyFiltersAndSort()
 		}
 	case "right":
-		if m.cursorCol < colFri {
+		if m.cursorCol < m.lastDayCol() {
 			m.cursorCol++
 		} else {
-			m.weekFrom, m.cursorCol = m.weekFrom.AddDate(0, 0, 7), colMon
+			m.weekFrom, m.cursorCol = m.weekFrom.AddDate(0, 0, 7), colFirstDay
 			m.reapplyFiltersAndSort()
 		}
 	case "ctrl+left":
@@ -521,7 +655,7 @@ func (m *TimesheetModel) handleNavigationInput(msg tea.KeyMsg) tea.Cmd {
 func (m *TimesheetModel) calculateCellPositions() [][]position {
 	positions := make([][]position, m.wndwSize)
 	for i := range positions {
-		positions[i] = make([]position, 6)
+		positions[i] = make([]position, m.lastDayCol()+1)
 	}
 	startY := 9
 	rowHeight := 3
@@ -530,7 +664,7 @@ func (m *TimesheetModel) calculateCellPositions() [][]position {
 		currentX := 0
 		positions[r][colTask] = position{x: currentX, y: startY + r*rowHeight, width: m.taskColWidth + padding, height: rowHeight}
 		currentX += m.taskColWidth + padding
-		for c := colMon; c <= colFri; c++ {
+		for c := colFirstDay; c <= m.lastDayCol(); c++ {
 			positions[r][c] = position{x: currentX, y: startY + r*rowHeight, width: m.dayColWidth + padding, height: rowHeight}
 			currentX += m.dayColWidth + padding
 		}
@@ -549,7 +683,7 @@ func (m *TimesheetModel) handleMouseInput(msg tea.MouseMsg) {
 			m.cursorRow++
 		}
 	case tea.MouseButtonLeft:
-		if msg.Action != tea.MouseActionRelease {
+		if msg.Action != tea.MouseActionRelease || m.entries.open {
 			return
 		}
 		if m.editing {
@@ -581,6 +715,10 @@ func (m *TimesheetModel) handleMouseInput(msg tea.MouseMsg) {
 	}
 }
 
+func (m TimesheetModel) EditingText() bool {
+	return m.editing || m.searchMode || m.entries.editing
+}
+
 func (m TimesheetModel) View() string {
 	if m.width == 0 {
 		return "Initializing..."
@@ -589,13 +727,21 @@ func (m TimesheetModel) View() string {
 		return m.styles.loadingStyle.Render("Loading timesheet... ") + m.spinner.View()
 	}
 
-	for i := 0; i < 5; i++ {
-		m.weekDays[i] = m.weekFrom.AddDate(0, 0, i).Format("Mon 2")
+	m.queued = make(map[string]clients.WriteState)
+	for _, w := range m.client.QueuedWrites() {
+		// A change to review outweighs one still waiting for the connection.
+		if key := w.TaskId + " " + w.Date(); w.State != clients.WritePending || m.queued[key] == "" {
+			m.queued[key] = w.State
+		}
 	}
 
-	title := lipgloss.PlaceHorizontal(m.width, lipgloss.Center, ui.TitleStyle.Render("Weekly Timesheet"))
+	title := lipgloss.PlaceHorizontal(m.width, lipgloss.Center, ui.TitleStyle.Render("Weekly Timesheet")+m.renderHolidays())
 	table := m.renderTable()
 	help := m.renderHelp()
+	if m.entries.open {
+		table = m.renderEntries()
+		help = m.renderEntriesHelp()
+	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, title, "\n", table)
 	if paddingHeight := m.height - lipgloss.Height(content) - lipgloss.Height(help); paddingHeight > 0 {
@@ -611,8 +757,15 @@ func (m *TimesheetModel) renderTable() string {
 
 func (m *TimesheetModel) renderHeader() string {
 	headers := []string{m.styles.taskHeaderStyle.Render(m.weekFrom.Format("January 2006"))}
-	for _, day := range m.weekDays {
-		headers = append(headers, m.styles.headerStyle.Render(day))
+	for c := colFirstDay; c <= m.lastDayCol(); c++ {
+		day, style := m.dayAt(c), m.styles.headerStyle
+		if _, ok := m.holidays[day.Format("2006-01-02")]; ok {
+			style = style.Foreground(ui.Warning)
+		}
+		headers = append(headers, style.Render(day.Format("Mon 2")))
+	}
+	if m.history != nil {
+		headers = append(headers, m.styles.headerStyle.Width(m.trackedColWidth).Render("Tracked"))
 	}
 	return lipgloss.JoinHorizontal(lipgloss.Left, headers...)
 }
@@ -632,24 +785,42 @@ func formatHoursToHM(hours float64) string {
 	return fmt.Sprintf("%dh %dm", h, m)
 }
 
-func (m *TimesheetModel) renderTotalsRow() string {
-	totals := make([]float64, 5)
-	for _, entry := range m.timesheet {
-		for i := 0; i < 5; i++ {
-			day := m.weekFrom.AddDate(0, 0, i).Format("2006-01-02")
-			totals[i] += entry.Hours[day]
+// formatHoursShort formats hours with at most one decimal, as "12h" or "2.5h".
+func formatHoursShort(hours float64) string {
+	return strconv.FormatFloat(math.Round(hours*10)/10, 'f', -1, 64) + "h"
+}
+
+// formatTracked formats the tracked hours against the estimate, as "12h / 20h", and
+// tells whether the estimate is exceeded.
+func formatTracked(tracked float64, estimate float64) (string, bool) {
+	if estimate <= 0 {
+		if tracked == 0 {
+			return "-", false
 		}
+		return formatHoursShort(tracked), false
 	}
+	return formatHoursShort(tracked) + " / " + formatHoursShort(estimate), tracked > estimate
+}
+
+func (m *TimesheetModel) renderTotalsRow() string {
 	totalCells := []string{m.styles.taskCellStyle.Foreground(lipgloss.Color("72")).Render("Total")}
-	for _, total := range totals {
-		style := m.styles.totalStyle
-		if total == 8 {
+	for c := colFirstDay; c <= m.lastDayCol(); c++ {
+		day := m.dayAt(c)
+		total := 0.0
+		for _, entry := range m.timesheet {
+			total += entry.Hours[day.Format("2006-01-02")]
+		}
+		style, target := m.styles.totalStyle, m.dayTarget(day)
+		if total == target {
 			style = m.styles.totalOkStyle
-		} else if total > 8 {
+		} else if total > target {
 			style = m.styles.totalOverStyle
 		}
 		totalCells = append(totalCells, style.Render(formatHoursToHM(total)))
 	}
+	if m.history != nil {
+		totalCells = append(totalCells, m.styles.trackedStyle.Render(""))
+	}
 	return lipgloss.JoinHorizontal(lipgloss.Left, totalCells...)
 }
 
@@ -664,12 +835,39 @@ func (m *TimesheetModel) renderBody() string {
 func (m *TimesheetModel) renderRow(entry TimeEntryR, rowIdx int) string {
 	isCursorRow := (rowIdx == m.cursorRow)
 	taskCell := m.renderTaskCell(entry.TaskName, isCursorRow)
-	dayCells := make([]string, 5)
-	for i := 0; i < 5; i++ {
-		day := m.weekFrom.AddDate(0, 0, i).Format("2006-01-02")
-		dayCells[i] = m.renderDayCell(entry.Hours[day], isCursorRow, i+1)
+	dayCells := make([]string, 0, len(m.dayOffsets))
+	for c := colFirstDay; c <= m.lastDayCol(); c++ {
+		day := m.dayAt(c).Format("2006-01-02")
+		dayCells = append(dayCells, m.renderDayCell(entry.Hours[day], m.queued[entry.TaskId+" "+day], isCursorRow, c))
+	}
+	cells := append([]string{taskCell}, dayCells...)
+	if m.history != nil {
+		cells = append(cells, m.renderTrackedCell(entry))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, cells...)
+}
+
+// tracked returns the hours tracked on the task in every week: the history, then the
+// recent entries of the grid.
+func (m *TimesheetModel) tracked(entry TimeEntryR) float64 {
+	hours := m.history[entry.TaskId]
+	recentFrom := m.recentFrom.Format("2006-01-02")
+	for day, h := range entry.Hours {
+		if day >= recentFrom {
+			hours += h
+		}
 	}
-	return lipgloss.JoinHorizontal(lipgloss.Left, append([]string{taskCell}, dayCells...)...)
+	return hours
+}
+
+// renderTrackedCell renders the hours tracked on the task in every week, red when they
+// exceed the estimate.
+func (m *TimesheetModel) renderTrackedCell(entry TimeEntryR) string {
+	content, over := formatTracked(m.tracked(entry), entry.Estimate)
+	if over {
+		return m.styles.trackedOverStyle.Render(content)
+	}
+	return m.styles.trackedStyle.Render(content)
 }
 
 func (m *TimesheetModel) renderTaskCell(taskNameInput string, isCursorRow bool) string {
@@ -727,11 +925,19 @@ func (m *TimesheetModel) renderTaskCell(taskNameInput string, isCursorRow bool)
 	return currentStyle.Render(finalTextContent)
 }
 
-func (m *TimesheetModel) renderDayCell(hours float64, isCursorRow bool, colIdx int) string {
+// renderDayCell renders the hours of a cell, marked with ⇅ while a change is waiting for
+// the connection and with ! when it could not be applied.
+func (m *TimesheetModel) renderDayCell(hours float64, queued clients.WriteState, isCursorRow bool, colIdx int) string {
 	style, content := m.styles.cellStyle, "-"
 	if hours > 0 {
 		content = formatHoursToHM(hours)
 	}
+	switch queued {
+	case clients.WritePending:
+		content += " ⇅"
+	case clients.WriteConflict, clients.WriteFailed:
+		content += " !"
+	}
 	if isCursorRow && colIdx == m.cursorCol {
 		if m.editing {
 			style, content = m.styles.editingStyle, m.editBuffer
@@ -757,5 +963,5 @@ func (m *TimesheetModel) renderHelp() string {
 			"[esc] Exit Search    [↑↓] Navigate",
 		)
 	}
-	return "[← ↑ → ↓] Navigate   [enter] Select/Edit   [/] Search   [tab] View   [r] Refresh    [?] Settings   [q] Quit"
+	return "[← ↑ → ↓] Navigate   [enter] Select/Edit   [i] Entries   [/] Search   [t] Timer   [tab] View   [r] Refresh    [?] Settings   [q] Quit"
 }
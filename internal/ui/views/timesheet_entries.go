@@ -0,0 +1,323 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/shared"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type timeEntryUpdatedMsg struct {
+	taskId string
+	day    time.Time
+	entry  clients.TimeEntry
+	err    error
+}
+
+type timeEntryDeletedMsg struct {
+	taskId  string
+	day     time.Time
+	entryId string
+	err     error
+}
+
+const (
+	fieldDuration = iota
+	fieldDescription
+)
+
+// entriesPanel lists the time entries behind a timesheet cell and lets the user edit or delete them.
+type entriesPanel struct {
+	open          bool
+	taskId        string
+	taskName      string
+	day           time.Time
+	entries       []clients.TimeEntry
+	cursor        int
+	editing       bool
+	focus         int
+	durationInput textinput.Model
+	descInput     textinput.Model
+	confirmDelete bool
+}
+
+func updateTimeEntry(client clients.ClickupAPI, taskId string, day time.Time, entry clients.TimeEntry) tea.Cmd {
+	return func() tea.Msg {
+		err := client.UpdateTimeEntry(context.Background(), entry)
+		return timeEntryUpdatedMsg{taskId: taskId, day: day, entry: entry, err: err}
+	}
+}
+
+func deleteTimeEntry(client clients.ClickupAPI, taskId string, day time.Time, entryId string) tea.Cmd {
+	return func() tea.Msg {
+		err := client.DeleteTimeEntry(context.Background(), taskId, entryId)
+		if err == nil {
+			clients.ClearTimeentriesCache()
+		}
+		return timeEntryDeletedMsg{taskId: taskId, day: day, entryId: entryId, err: err}
+	}
+}
+
+func newEntriesPanel(row TimeEntryR, day time.Time) entriesPanel {
+	durationInput := textinput.New()
+	durationInput.Prompt = "Duration: "
+	durationInput.CharLimit = 16
+	descInput := textinput.New()
+	descInput.Prompt = "Description: "
+	descInput.CharLimit = 500
+	return entriesPanel{
+		open:          true,
+		taskId:        row.TaskId,
+		taskName:      row.TaskName,
+		day:           day,
+		entries:       row.Entries[day.Format("2006-01-02")],
+		durationInput: durationInput,
+		descInput:     descInput,
+	}
+}
+
+func (p *entriesPanel) selected() (clients.TimeEntry, bool) {
+	if p.cursor < 0 || p.cursor >= len(p.entries) {
+		return clients.TimeEntry{}, false
+	}
+	return p.entries[p.cursor], true
+}
+
+func (p *entriesPanel) startEditing() {
+	entry, ok := p.selected()
+	if !ok {
+		return
+	}
+	p.editing = true
+	p.focus = fieldDuration
+	p.durationInput.SetValue(fmt.Sprintf("%.2f", shared.ToHours(entry.Duration)))
+	p.durationInput.CursorEnd()
+	p.durationInput.Focus()
+	p.descInput.SetValue(entry.Description)
+	p.descInput.CursorEnd()
+	p.descInput.Blur()
+}
+
+func (p *entriesPanel) stopEditing() {
+	p.editing = false
+	p.durationInput.Blur()
+	p.descInput.Blur()
+}
+
+func (p *entriesPanel) switchFocus() {
+	if p.focus == fieldDuration {
+		p.focus = fieldDescription
+		p.durationInput.Blur()
+		p.descInput.Focus()
+	} else {
+		p.focus = fieldDuration
+		p.descInput.Blur()
+		p.durationInput.Focus()
+	}
+}
+
+// setEntries replaces the entries of a task on a day and recomputes the hours shown in the grid.
+func (m *TimesheetModel) setEntries(taskId string, day time.Time, entries []clients.TimeEntry) {
+	dayKey := day.Format("2006-01-02")
+	for i := range m.timesheet {
+		if m.timesheet[i].TaskId != taskId {
+			continue
+		}
+		hours := 0.0
+		for _, entry := range entries {
+			hours += shared.ToHours(entry.Duration)
+		}
+		m.timesheet[i].Entries[dayKey] = entries
+		m.timesheet[i].Hours[dayKey] = hours
+		break
+	}
+	if m.entries.open && m.entries.taskId == taskId && m.entries.day.Equal(day) {
+		m.entries.entries = entries
+		m.entries.cursor = min(m.entries.cursor, max(len(entries)-1, 0))
+	}
+	m.reapplyFiltersAndSort()
+}
+
+func (m *TimesheetModel) dayEntries(taskId string, day time.Time) []clients.TimeEntry {
+	for _, row := range m.timesheet {
+		if row.TaskId == taskId {
+			return row.Entries[day.Format("2006-01-02")]
+		}
+	}
+	return nil
+}
+
+func (m *TimesheetModel) openEntries() {
+	if m.cursorCol == colTask || len(m.activeTimesheet()) == 0 {
+		return
+	}
+	row := m.activeTimesheet()[m.cursorRow]
+	m.entries = newEntriesPanel(row, m.dayAt(m.cursorCol))
+}
+
+func (m *TimesheetModel) handleTimeEntryUpdated(msg timeEntryUpdatedMsg) tea.Cmd {
+	if msg.err != nil {
+		return components.Notify("Updating time entry", msg.err, updateTimeEntry(m.client, msg.taskId, msg.day, msg.entry))
+	}
+	entries := append([]clients.TimeEntry(nil), m.dayEntries(msg.taskId, msg.day)...)
+	for i := range entries {
+		if entries[i].Id == msg.entry.Id {
+			entries[i] = msg.entry
+		}
+	}
+	m.setEntries(msg.taskId, msg.day, entries)
+	return nil
+}
+
+func (m *TimesheetModel) handleTimeEntryDeleted(msg timeEntryDeletedMsg) tea.Cmd {
+	if msg.err != nil {
+		return components.Notify("Deleting time entry", msg.err, deleteTimeEntry(m.client, msg.taskId, msg.day, msg.entryId))
+	}
+	var entries []clients.TimeEntry
+	for _, entry := range m.dayEntries(msg.taskId, msg.day) {
+		if entry.Id != msg.entryId {
+			entries = append(entries, entry)
+		}
+	}
+	m.setEntries(msg.taskId, msg.day, entries)
+	return nil
+}
+
+func (m *TimesheetModel) handleEntriesInput(msg tea.KeyMsg) tea.Cmd {
+	p := &m.entries
+	if p.editing {
+		return m.handleEntryEditingInput(msg)
+	}
+	key := msg.String()
+	if key != "d" {
+		p.confirmDelete = false
+	}
+	switch key {
+	case "esc", "i":
+		m.entries = entriesPanel{}
+	case "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down":
+		if p.cursor < len(p.entries)-1 {
+			p.cursor++
+		}
+	case "enter":
+		p.startEditing()
+	case "b":
+		if entry, ok := p.selected(); ok {
+			entry.Billable = !entry.Billable
+			return updateTimeEntry(m.client, p.taskId, p.day, entry)
+		}
+	case "d":
+		entry, ok := p.selected()
+		if !ok {
+			break
+		}
+		if !p.confirmDelete {
+			p.confirmDelete = true
+			break
+		}
+		p.confirmDelete = false
+		return deleteTimeEntry(m.client, p.taskId, p.day, entry.Id)
+	}
+	return nil
+}
+
+func (m *TimesheetModel) handleEntryEditingInput(msg tea.KeyMsg) tea.Cmd {
+	p := &m.entries
+	switch msg.Type {
+	case tea.KeyEscape:
+		p.stopEditing()
+		return nil
+	case tea.KeyTab, tea.KeyShiftTab:
+		p.switchFocus()
+		return nil
+	case tea.KeyEnter:
+		entry, ok := p.selected()
+		if !ok {
+			p.stopEditing()
+			return nil
+		}
+		hours, err := shared.ParseHoursInput(p.durationInput.Value())
+		if err != nil || hours <= 0 {
+			return components.Notify("Invalid duration", fmt.Errorf("cannot parse %q", p.durationInput.Value()), nil)
+		}
+		entry.Duration = strconv.Itoa(int(math.Round(hours * 60 * 60 * 1000)))
+		entry.End = strconv.Itoa(shared.ToInt(entry.Start) + shared.ToInt(entry.Duration))
+		entry.Description = strings.TrimSpace(p.descInput.Value())
+		p.stopEditing()
+		return updateTimeEntry(m.client, p.taskId, p.day, entry)
+	}
+	var cmd tea.Cmd
+	if p.focus == fieldDuration {
+		p.durationInput, cmd = p.durationInput.Update(msg)
+	} else {
+		p.descInput, cmd = p.descInput.Update(msg)
+	}
+	return cmd
+}
+
+func (m *TimesheetModel) renderEntries() string {
+	p := m.entries
+	title := ui.TitleStyle.Render(p.taskName)
+	subtitle := ui.SubtitleStyle.Render(p.day.Format("Monday 2 January 2006"))
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Bold(true)
+	billableStyle := lipgloss.NewStyle().Foreground(ui.Special)
+
+	var rows []string
+	if len(p.entries) == 0 {
+		rows = append(rows, dimStyle.Render("No time entries"))
+	}
+	total := 0.0
+	for i, entry := range p.entries {
+		hours := shared.ToHours(entry.Duration)
+		total += hours
+		start := shared.ToDate(entry.Start).Format("15:04")
+		end := shared.ToDate(entry.Start).Add(time.Duration(hours * float64(time.Hour))).Format("15:04")
+		billable := "  "
+		if entry.Billable {
+			billable = billableStyle.Render("$ ")
+		}
+		description := entry.Description
+		if description == "" {
+			description = dimStyle.Render("no description")
+		}
+		line := fmt.Sprintf("%s - %s  %-8s %s%s", start, end, formatHoursToHM(hours), billable, description)
+		if i == p.cursor {
+			line = cursorStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(m.width-8).Render(line))
+	}
+
+	content := []string{title, subtitle, "", lipgloss.JoinVertical(lipgloss.Left, rows...), "", "Total: " + formatHoursToHM(total)}
+	if p.editing {
+		content = append(content, "", p.durationInput.View(), p.descInput.View())
+	}
+	if p.confirmDelete {
+		content = append(content, "", lipgloss.NewStyle().Foreground(ui.Error).Bold(true).Render("Press [d] again to delete the entry"))
+	}
+	return ui.PanelStyle.Width(m.width - 4).Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+}
+
+func (m *TimesheetModel) renderEntriesHelp() string {
+	if m.entries.editing {
+		return "[tab] Switch field   [enter] Save   [esc] Cancel"
+	}
+	return "[↑ ↓] Select   [enter] Edit   [b] Billable   [d d] Delete   [esc] Close"
+}
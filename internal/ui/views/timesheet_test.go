@@ -0,0 +1,365 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mceck/clickup-tui/internal/clients"
+	"github.com/mceck/clickup-tui/internal/fakeclickup"
+	"github.com/mceck/clickup-tui/internal/ui/components"
+)
+
+func asTimesheet(m tea.Model) TimesheetModel {
+	if p, ok := m.(*TimesheetModel); ok {
+		return *p
+	}
+	return m.(TimesheetModel)
+}
+
+func TestTimesheetEditCellCreatesTimeEntry(t *testing.T) {
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Timesheet task", Tags: []clients.Tag{{Name: "timesheet"}}}},
+	}, clients.Config{UserId: "1"})
+
+	model := NewTimesheetModel(srv.Client())
+	model.cursorCol = colFirstDay
+	m, notifications := start(model)
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if rows := asTimesheet(m).timesheet; len(rows) != 1 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2h30m")})
+	m, notifications = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+
+	ts := asTimesheet(m)
+	day := ts.weekFrom.Format("2006-01-02")
+	if got := ts.timesheet[0].Hours[day]; got != 2.5 {
+		t.Errorf("grid hours = %v, want 2.5", got)
+	}
+	entries := srv.TimeEntries()
+	if len(entries) != 1 || entries[0].Duration != "9000000" {
+		t.Errorf("unexpected time entries %+v", entries)
+	}
+}
+
+func TestTimesheetRejectsInvalidHours(t *testing.T) {
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Timesheet task", Tags: []clients.Tag{{Name: "timesheet"}}}},
+	}, clients.Config{UserId: "1"})
+	m, _ := start(NewTimesheetModel(srv.Client()))
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("abc")})
+	_, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) != 1 {
+		t.Fatalf("expected a notification, got %v", notifications)
+	}
+	if len(srv.TimeEntries()) != 0 {
+		t.Error("invalid input created a time entry")
+	}
+}
+
+func TestTimesheetEntriesEditAndDelete(t *testing.T) {
+	now := time.Now()
+	weekFrom := clients.WorkWeek{}.WeekStart(now)
+	morning := time.Date(weekFrom.Year(), weekFrom.Month(), weekFrom.Day(), 9, 0, 0, 0, time.Local)
+	entry := func(id string, start time.Time, duration time.Duration) clients.TimeEntry {
+		return clients.TimeEntry{
+			Id:       id,
+			Task:     map[string]interface{}{"id": "t1", "name": "Timesheet task"},
+			Start:    strconv.FormatInt(start.UnixMilli(), 10),
+			Duration: strconv.FormatInt(duration.Milliseconds(), 10),
+		}
+	}
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Timesheet task", Tags: []clients.Tag{{Name: "timesheet"}}}},
+		TimeEntries: []clients.TimeEntry{
+			entry("e1", morning, time.Hour),
+			entry("e2", morning.Add(2*time.Hour), time.Hour),
+		},
+	}, clients.Config{UserId: "1"})
+
+	model := NewTimesheetModel(srv.Client())
+	model.cursorCol = colFirstDay
+	m, _ := start(model)
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if ts := asTimesheet(m); !ts.entries.open || len(ts.entries.entries) != 2 {
+		t.Fatalf("unexpected entries panel %+v", ts.entries)
+	}
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1h30m")})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Planning")})
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m, notifications = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+
+	entries := srv.TimeEntries()
+	if len(entries) != 1 || entries[0].Id != "e1" || entries[0].Duration != "5400000" || entries[0].Description != "Planning" {
+		t.Fatalf("unexpected time entries %+v", entries)
+	}
+	ts := asTimesheet(m)
+	if got := ts.timesheet[0].Hours[weekFrom.Format("2006-01-02")]; got != 1.5 {
+		t.Errorf("grid hours = %v, want 1.5", got)
+	}
+}
+
+func TestTimesheetQueuesEditsOffline(t *testing.T) {
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Timesheet task", Tags: []clients.Tag{{Name: "timesheet"}}}},
+	}, clients.Config{UserId: "1"})
+	client := srv.Client()
+	client.QueueWrites = true
+
+	model := NewTimesheetModel(client)
+	model.cursorCol = colFirstDay
+	m, _ := start(model)
+	srv.SetOffline(true)
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2h")})
+	m, notifications := drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	ts := asTimesheet(m)
+	day := ts.weekFrom.Format("2006-01-02")
+	if got := ts.timesheet[0].Hours[day]; got != 2 {
+		t.Errorf("grid hours = %v, want 2", got)
+	}
+	if view := m.View(); !strings.Contains(view, "2h ⇅") {
+		t.Errorf("queued cell not marked:\n%s", view)
+	}
+	if len(srv.TimeEntries()) != 0 {
+		t.Fatalf("offline edit reached the server: %+v", srv.TimeEntries())
+	}
+
+	srv.SetOffline(false)
+	if sent, err := client.ReplayWrites(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("replay sent %d writes: %v", sent, err)
+	}
+	m, _ = drive(m, components.QueueChangedMsg{})
+	if got := asTimesheet(m).timesheet[0].Hours[day]; got != 2 {
+		t.Errorf("grid hours after replay = %v, want 2", got)
+	}
+	if view := m.View(); strings.Contains(view, "⇅") {
+		t.Errorf("sent cell still marked:\n%s", view)
+	}
+	if entries := srv.TimeEntries(); len(entries) != 1 || entries[0].Duration != "7200000" {
+		t.Errorf("unexpected time entries %+v", entries)
+	}
+}
+
+func TestTimesheetRefreshKeepsCursorOnTask(t *testing.T) {
+	tag := []clients.Tag{{Name: "timesheet"}}
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "B task", Tags: tag}, {Id: "t2", Name: "C task", Tags: tag}},
+	}, clients.Config{UserId: "1"})
+	m, _ := start(NewTimesheetModel(srv.Client()))
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyDown})
+
+	srv.PutTask(clients.Task{Id: "t3", Name: "A task", Tags: tag})
+	m, notifications := drive(m, components.RefreshMsg{})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	ts := asTimesheet(m)
+	if len(ts.timesheet) != 3 || ts.timesheet[0].TaskId != "t3" {
+		t.Fatalf("timesheet not refreshed: %+v", ts.timesheet)
+	}
+	if ts.cursorRow != 2 || ts.refreshing {
+		t.Errorf("cursor on row %d, want the row of t2", ts.cursorRow)
+	}
+}
+
+func TestTimesheetTracksEveryWeekAgainstTheEstimate(t *testing.T) {
+	now := time.Now()
+	weekFrom := clients.WorkWeek{}.WeekStart(now)
+	monday := time.Date(weekFrom.Year(), weekFrom.Month(), weekFrom.Day(), 9, 0, 0, 0, time.Local)
+	entry := func(id string, start time.Time, duration time.Duration) clients.TimeEntry {
+		return clients.TimeEntry{
+			Id:       id,
+			Task:     map[string]interface{}{"id": "t1", "name": "Timesheet task"},
+			Start:    strconv.FormatInt(start.UnixMilli(), 10),
+			Duration: strconv.FormatInt(duration.Milliseconds(), 10),
+		}
+	}
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Timesheet task", Tags: []clients.Tag{{Name: "timesheet"}}, TimeEstimate: 5 * time.Hour.Milliseconds()}},
+		TimeEntries: []clients.TimeEntry{
+			entry("e1", monday.AddDate(0, -3, 0), 3*time.Hour),
+			entry("e2", monday, time.Hour),
+		},
+	}, clients.Config{UserId: "1"})
+
+	model := NewTimesheetModel(srv.Client())
+	model.cursorCol = colFirstDay
+	m, notifications := start(model)
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if ts := asTimesheet(m); ts.tracked(ts.timesheet[0]) != 4 || ts.timesheet[0].Estimate != 5 {
+		t.Fatalf("tracked %v of %v, want 4 of 5", ts.tracked(ts.timesheet[0]), ts.timesheet[0].Estimate)
+	}
+	if view := m.View(); !strings.Contains(view, "4h / 5h") {
+		t.Errorf("tracked column not shown:\n%s", view)
+	}
+
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3h")})
+	m, notifications = drive(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	if ts := asTimesheet(m); ts.tracked(ts.timesheet[0]) != 6 {
+		t.Errorf("tracked after the edit = %v, want 6", ts.tracked(ts.timesheet[0]))
+	}
+	if view := m.View(); !strings.Contains(view, "6h / 5h") {
+		t.Errorf("tracked column not updated:\n%s", view)
+	}
+
+	// The history is not fetched again by a refresh.
+	requests := len(srv.Requests())
+	m, _ = drive(m, components.RefreshMsg{})
+	for _, r := range srv.Requests()[requests:] {
+		if strings.Contains(r, "start_date=") {
+			t.Errorf("history fetched again on refresh: %s", r)
+		}
+	}
+	if view := m.View(); !strings.Contains(view, "6h / 5h") {
+		t.Errorf("tracked column lost on refresh:\n%s", view)
+	}
+}
+
+// failingHistory is a client that cannot read the older time entries.
+type failingHistory struct {
+	clients.ClickupAPI
+}
+
+func (failingHistory) GetOlderTimeEntries(ctx context.Context, userId string) ([]clients.TimeEntry, error) {
+	return nil, errors.New("history unavailable")
+}
+
+func TestTimesheetLoadsWithoutTheHistory(t *testing.T) {
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Timesheet task", Tags: []clients.Tag{{Name: "timesheet"}}, TimeEstimate: time.Hour.Milliseconds()}},
+	}, clients.Config{UserId: "1"})
+	m, notifications := start(NewTimesheetModel(failingHistory{srv.Client()}))
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	ts := asTimesheet(m)
+	if len(ts.timesheet) != 1 || ts.history != nil {
+		t.Fatalf("unexpected timesheet %+v with history %v", ts.timesheet, ts.history)
+	}
+	if view := m.View(); strings.Contains(view, "Tracked") || !strings.Contains(view, "Timesheet task") {
+		t.Errorf("grid not shown without the Tracked column:\n%s", view)
+	}
+}
+
+func TestFormatTracked(t *testing.T) {
+	tests := []struct {
+		tracked, estimate float64
+		want              string
+		over              bool
+	}{
+		{0, 0, "-", false},
+		{2.5, 0, "2.5h", false},
+		{12, 20, "12h / 20h", false},
+		{20.25, 20, "20.3h / 20h", true},
+	}
+	for _, tt := range tests {
+		got, over := formatTracked(tt.tracked, tt.estimate)
+		if got != tt.want || over != tt.over {
+			t.Errorf("formatTracked(%v, %v) = %q, %v, want %q, %v", tt.tracked, tt.estimate, got, over, tt.want, tt.over)
+		}
+	}
+}
+
+func TestTimesheetShowsTheConfiguredWorkWeek(t *testing.T) {
+	week := clients.WorkWeek{
+		FirstDay: "sunday",
+		Days:     []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"},
+		Targets:  map[string]float64{"friday": 0, "saturday": 0},
+		Holidays: "~/holidays.json",
+	}
+	srv := newTestServer(t, fakeclickup.Fixtures{
+		Tasks: []clients.Task{{Id: "t1", Name: "Timesheet task", Tags: []clients.Tag{{Name: "timesheet"}}}},
+	}, clients.Config{UserId: "1", WorkWeek: week})
+	sunday := week.WeekStart(time.Now())
+	tuesday := sunday.AddDate(0, 0, 2)
+	holidays := `{"` + tuesday.Format("2006-01-02") + `": "Founders Day"}`
+	if err := os.WriteFile(filepath.Join(os.Getenv("HOME"), "holidays.json"), []byte(holidays), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewTimesheetModel(srv.Client())
+	model.cursorCol = colFirstDay
+	m, notifications := start(model)
+	if len(notifications) > 0 {
+		t.Fatalf("unexpected errors: %v", notifications[0].Err)
+	}
+	ts := asTimesheet(m)
+	if !ts.weekFrom.Equal(sunday) || ts.lastDayCol() != 7 || !ts.dayAt(ts.lastDayCol()).Equal(sunday.AddDate(0, 0, 6)) {
+		t.Fatalf("week from %s with %d days, want 7 days from %s", ts.weekFrom, len(ts.dayOffsets), sunday)
+	}
+	for day, want := range map[time.Time]float64{sunday: 8, tuesday: 0, sunday.AddDate(0, 0, 5): 0, sunday.AddDate(0, 0, 6): 0} {
+		if got := ts.dayTarget(day); got != want {
+			t.Errorf("target of %s = %v, want %v", day.Format("Mon"), got, want)
+		}
+	}
+	view := m.View()
+	if !strings.Contains(view, "Founders Day ("+tuesday.Format("Mon 2")+")") || !strings.Contains(view, sunday.AddDate(0, 0, 6).Format("Sat 2")) {
+		t.Errorf("work week not shown:\n%s", view)
+	}
+
+	// The cells of every day column can be clicked, and they fit in the width.
+	positions := ts.calculateCellPositions()
+	last := positions[0][ts.lastDayCol()]
+	if len(positions[0]) != 8 || last.x+last.width > ts.width {
+		t.Fatalf("unexpected cell positions %+v", positions[0])
+	}
+	m, _ = drive(m, tea.MouseMsg{X: last.x + 1, Y: last.y + 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+	if ts := asTimesheet(m); ts.cursorCol != 7 {
+		t.Fatalf("cursor on column %d after the click, want 7", ts.cursorCol)
+	}
+	m, _ = drive(m, tea.KeyMsg{Type: tea.KeyRight})
+	if ts := asTimesheet(m); ts.cursorCol != colFirstDay || !ts.weekFrom.Equal(sunday.AddDate(0, 0, 7)) {
+		t.Errorf("right from Saturday moved to column %d of the week of %s", ts.cursorCol, ts.weekFrom)
+	}
+}
+
+func TestTimesheetReportsAnInvalidWorkWeek(t *testing.T) {
+	srv := newTestServer(t, fakeclickup.Fixtures{}, clients.Config{UserId: "1", WorkWeek: clients.WorkWeek{Days: []string{"mon", "funday"}}})
+	m, notifications := start(NewTimesheetModel(srv.Client()))
+	if len(notifications) != 1 || !strings.Contains(notifications[0].Err.Error(), "funday") {
+		t.Fatalf("expected a notification about the unknown day, got %v", notifications)
+	}
+	if ts := asTimesheet(m); ts.lastDayCol() != colFirstDay {
+		t.Errorf("got %d day columns, want only Monday", len(ts.dayOffsets))
+	}
+}
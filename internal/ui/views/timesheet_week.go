@@ -0,0 +1,83 @@
+package views
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mceck/clickup-tui/internal/clients"
+	ui "github.com/mceck/clickup-tui/internal/ui/styles"
+)
+
+type holidaysLoadedMsg struct {
+	holidays map[string]string
+	err      error
+}
+
+// loadHolidays checks the work week of the config and reads its file of public holidays.
+func loadHolidays(week clients.WorkWeek) tea.Cmd {
+	return func() tea.Msg {
+		if err := week.Validate(); err != nil {
+			return holidaysLoadedMsg{err: err}
+		}
+		holidays, err := clients.LoadHolidays(week.Holidays)
+		return holidaysLoadedMsg{holidays: holidays, err: err}
+	}
+}
+
+// setWorkWeek changes the days of the columns, keeping the cursor on the same day of the
+// week when it is still shown.
+func (m *TimesheetModel) setWorkWeek(week clients.WorkWeek) {
+	current := time.Weekday(-1)
+	if m.cursorCol >= colFirstDay && m.cursorCol <= m.lastDayCol() {
+		current = m.dayAt(m.cursorCol).Weekday()
+	}
+	m.week = week
+	m.weekFrom = week.WeekStart(m.weekFrom)
+	days := week.Weekdays()
+	m.dayOffsets = make([]int, len(days))
+	for i, day := range days {
+		m.dayOffsets[i] = (int(day-week.Start()) + 7) % 7
+	}
+	m.cursorCol = min(max(m.cursorCol, colFirstDay), m.lastDayCol())
+	if i := slices.Index(days, current); i >= 0 {
+		m.cursorCol = colFirstDay + i
+	}
+	if m.width > 0 {
+		m.setSize(m.width, m.height)
+	}
+}
+
+// dayAt returns the date of a day column.
+func (m *TimesheetModel) dayAt(col int) time.Time {
+	return m.weekFrom.AddDate(0, 0, m.dayOffsets[col-colFirstDay])
+}
+
+func (m *TimesheetModel) lastDayCol() int {
+	return colFirstDay + len(m.dayOffsets) - 1
+}
+
+// dayTarget returns the hours expected on a day, none on the public holidays.
+func (m *TimesheetModel) dayTarget(day time.Time) float64 {
+	if _, ok := m.holidays[day.Format("2006-01-02")]; ok {
+		return 0
+	}
+	return m.week.Target(day.Weekday())
+}
+
+// renderHolidays names the public holidays of the days shown, to follow the title.
+func (m *TimesheetModel) renderHolidays() string {
+	var names []string
+	for c := colFirstDay; c <= m.lastDayCol(); c++ {
+		day := m.dayAt(c)
+		if name, ok := m.holidays[day.Format("2006-01-02")]; ok {
+			names = append(names, name+" ("+day.Format("Mon 2")+")")
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(ui.Warning).Render(" · " + strings.Join(names, " · "))
+}
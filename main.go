@@ -5,9 +5,18 @@ import (
 	"os"
 
 	"github.com/mceck/clickup-tui/internal/app"
+	"github.com/mceck/clickup-tui/internal/cli"
 )
 
 func main() {
+	args, err := cli.ApplyGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(args) > 0 {
+		os.Exit(cli.Run(args, os.Stdin, os.Stdout, os.Stderr))
+	}
 	p := app.NewProgram()
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Errore durante l'avvio dell'applicazione: %v\n", err)